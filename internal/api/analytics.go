@@ -0,0 +1,55 @@
+package api
+
+// AnalyticsCallback holds the learning dashboard data that posted by BBB server (2.4+) to
+// `meta_analytics-callback-url` of a meeting after it ended. The request is authenticated
+// using JWT in Authorization header that signed with BBB secret.
+type AnalyticsCallback struct {
+	Version           string        `json:"version"`
+	MeetingId         string        `json:"meeting_id"`          // The meeting ID that was given when creating the meeting.
+	InternalMeetingId string        `json:"internal_meeting_id"` // The meeting ID that was generated by BBB server.
+	Data              AnalyticsData `json:"data"`
+}
+
+// AnalyticsData holds learning dashboard data of a meeting.
+type AnalyticsData struct {
+	Metadata  map[string]interface{} `json:"metadata"`  // Metadata that was given when creating the meeting.
+	Duration  int64                  `json:"duration"`  // How long the meeting took place in seconds.
+	Start     string                 `json:"start"`     // When the meeting started.
+	Finish    string                 `json:"finish"`    // When the meeting ended.
+	Attendees []AnalyticsAttendee    `json:"attendees"` // Every user that joined the meeting.
+	Files     []string               `json:"files"`     // Presentation files that were uploaded.
+	Polls     []AnalyticsPoll        `json:"polls"`     // Polls that were held.
+}
+
+// AnalyticsAttendee holds participation of a user in a meeting.
+type AnalyticsAttendee struct {
+	ExtUserId         string              `json:"ext_user_id"` // The user ID that was given when joining the meeting.
+	Name              string              `json:"name"`
+	Moderator         bool                `json:"moderator"`
+	Joins             []string            `json:"joins"`               // When the user joined.
+	Leaves            []string            `json:"leaves"`              // When the user left.
+	Duration          int64               `json:"duration"`            // How long the user was in the meeting in seconds.
+	RecentTalkingTime string              `json:"recent_talking_time"` // When the user talked for the last time.
+	Engagement        AnalyticsEngagement `json:"engagement"`
+}
+
+// AnalyticsEngagement holds how much a user engaged in a meeting.
+type AnalyticsEngagement struct {
+	Chats     int   `json:"chats"`      // Number of chat messages sent.
+	Talks     int   `json:"talks"`      // Number of times the user talked.
+	RaiseHand int   `json:"raisehand"`  // Number of times the user raised hand.
+	Emojis    int   `json:"emojis"`     // Number of emoji reactions sent.
+	PollVotes int   `json:"poll_votes"` // Number of polls answered.
+	TalkTime  int64 `json:"talk_time"`  // How long the user talked in seconds.
+}
+
+// AnalyticsPoll holds a poll and its answers.
+type AnalyticsPoll struct {
+	Id        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Question  string                 `json:"question"`
+	Published bool                   `json:"published"`
+	Options   []string               `json:"options"`
+	Start     string                 `json:"start"` // When the poll was started.
+	Votes     map[string]interface{} `json:"votes"` // Answer of every user keyed by the user ID that was given when joining.
+}
@@ -9,25 +9,74 @@ import (
 
 // CreateMeeting format that needed to create meeting. This should be sent as URL.
 type CreateMeeting struct {
-	Name             string `json:"name"` // A name for the meeting. Required.
-	MeetingId        string // A meeting ID that can be used to identify this meeting by the 3rd-party application. Required.
-	AttendeePass     string `json:"attendee_pass"`      // Password that would be used by attendee to enter the meeting. Optional.
-	ModeratorPass    string `json:"moderator_pass"`     // Password that would be used by moderator to enter the meeting. Optional.
-	MaxParticipants  uint8  `json:"max_participant"`    // Set the maximum number of users allowed to join the conference at the same time.
-	RedirectAtLogout string `json:"redirect_at_logout"` // The URL that the BigBlueButton client will go to after users click the OK button on the ‘You have been logged out message’.
-	WelcomeMsg       string `json:"welcome_msg"`        // A welcome message that gets displayed on the chat window when the participant joins.
-	IsRecording      bool   `json:"is_recording"`       // Instructs the BigBlueButton server to record the media and events in the session for later playback.
+	Name             string       `json:"name"` // A name for the meeting. Required.
+	MeetingId        string       // A meeting ID that can be used to identify this meeting by the 3rd-party application. Required.
+	AttendeePass     string       `json:"attendee_pass"`      // Password that would be used by attendee to enter the meeting. Optional.
+	ModeratorPass    string       `json:"moderator_pass"`     // Password that would be used by moderator to enter the meeting. Optional.
+	MaxParticipants  uint8        `json:"max_participant"`    // Set the maximum number of users allowed to join the conference at the same time.
+	RedirectAtLogout string       `json:"redirect_at_logout"` // The URL that the BigBlueButton client will go to after users click the OK button on the ‘You have been logged out message’.
+	WelcomeMsg       string       `json:"welcome_msg"`        // A welcome message that gets displayed on the chat window when the participant joins.
+	IsRecording      bool         `json:"is_recording"`       // Instructs the BigBlueButton server to record the media and events in the session for later playback.
+	MuteOnStart      bool         `json:"mute_on_start"`      // Every user starts with muted microphone.
+	LockSettings     LockSettings `json:"lock_settings"`      // Restrictions that apply to the attendees.
+	GuestPolicy      string       `json:"guest_policy"`       // How guests are let in. One of the GuestPolicy constants, default to ALWAYS_ACCEPT.
+	IsBreakout       bool         `json:"is_breakout"`        // The meeting is a breakout room of another meeting.
+	ParentMeetingId  string       `json:"parent_meeting_id"`  // Internal meeting ID of the meeting that the breakout room belongs to. Required for breakout room.
+	Sequence         uint8        `json:"sequence"`           // Number of the breakout room, starting from 1. Required for breakout room.
+	FreeJoin         bool         `json:"free_join"`          // Users could choose which breakout room to join.
+	Template         string       `json:"template,omitempty"` // Name of the template that the meeting was created from. Not sent to BBB API.
+}
+
+// Guest policies of a meeting.
+const (
+	GuestAlwaysAccept = "ALWAYS_ACCEPT" // Guests join right away.
+	GuestAlwaysDeny   = "ALWAYS_DENY"   // Guests could not join.
+	GuestAskModerator = "ASK_MODERATOR" // Guests wait until a moderator approves them.
+)
+
+// LockSettings restrictions that apply to the attendees of a meeting.
+type LockSettings struct {
+	DisableCam         bool `json:"disable_cam"`          // Attendees can't share their webcam.
+	DisableMic         bool `json:"disable_mic"`          // Attendees can't use their microphone.
+	DisablePrivateChat bool `json:"disable_private_chat"` // Attendees can't chat privately.
+	DisablePublicChat  bool `json:"disable_public_chat"`  // Attendees can't chat publicly.
+	DisableNotes       bool `json:"disable_notes"`        // Attendees can't edit the shared notes.
+	HideUserList       bool `json:"hide_user_list"`       // Attendees only see the moderators in the user list.
+	LockedLayout       bool `json:"locked_layout"`        // Attendees can't change the layout.
+}
+
+// query return the lock settings as BBB API query that only include the enabled ones.
+func (ls *LockSettings) query() (str string) {
+	for _, l := range []struct {
+		param   string
+		enabled bool
+	}{
+		{"lockSettingsDisableCam", ls.DisableCam},
+		{"lockSettingsDisableMic", ls.DisableMic},
+		{"lockSettingsDisablePrivateChat", ls.DisablePrivateChat},
+		{"lockSettingsDisablePublicChat", ls.DisablePublicChat},
+		{"lockSettingsDisableNotes", ls.DisableNotes},
+		{"lockSettingsHideUserList", ls.HideUserList},
+		{"lockSettingsLockedLayout", ls.LockedLayout},
+	} {
+		if l.enabled {
+			str += fmt.Sprintf("&%s=true", l.param)
+		}
+	}
+
+	return
 }
 
 // CreateMeetingResponse holds data from BBB API response after create meeting.
 type CreateMeetingResponse struct {
 	StdResponse
-	MeetingId     string `xml:"meetingID" json:"meeting_id"`
-	AttendeePass  string `xml:"attendeePW" json:"attendee_pass"`
-	ModeratorPass string `xml:"moderatorPW" json:"moderator_pass"`
-	CreateTime    string `xml:"createTime" json:"create_time"`
-	CreatedAt     string `xml:"createDate" json:"created_at"`
-	Duration      string `xml:"duration" json:"duration"`
+	MeetingId         string `xml:"meetingID" json:"meeting_id"`
+	InternalMeetingId string `xml:"internalMeetingID" json:"internal_meeting_id"`
+	AttendeePass      string `xml:"attendeePW" json:"attendee_pass,omitempty"`
+	ModeratorPass     string `xml:"moderatorPW" json:"moderator_pass,omitempty"`
+	CreateTime        string `xml:"createTime" json:"create_time"`
+	CreatedAt         string `xml:"createDate" json:"created_at"`
+	Duration          string `xml:"duration" json:"duration"`
 }
 
 // ParseCreateMeeting parse given request body binding from json and convert them
@@ -37,6 +86,16 @@ func (cm *CreateMeeting) ParseCreateMeeting(ran service.RandStringInterface) (st
 		return "", fmt.Errorf("`name` field is required")
 	}
 
+	switch cm.GuestPolicy {
+	case "", GuestAlwaysAccept, GuestAlwaysDeny, GuestAskModerator:
+	default:
+		return "", fmt.Errorf("`guest_policy` must be one of %s, %s or %s", GuestAlwaysAccept, GuestAlwaysDeny, GuestAskModerator)
+	}
+
+	if cm.IsBreakout && (cm.ParentMeetingId == "" || cm.Sequence == 0) {
+		return "", fmt.Errorf("`parent_meeting_id` and `sequence` are required for breakout room")
+	}
+
 	if cm.MeetingId == "" {
 		cm.MeetingId = ran.RandString()
 	}
@@ -53,9 +112,9 @@ func (cm *CreateMeeting) ParseCreateMeeting(ran service.RandStringInterface) (st
 		"/%s?name=%s&meetingID=%s&moderatorPW=%s&attendeePW=%s",
 		Create,
 		url.QueryEscape(cm.Name),
-		cm.MeetingId,
-		cm.ModeratorPass,
-		cm.AttendeePass,
+		url.QueryEscape(cm.MeetingId),
+		url.QueryEscape(cm.ModeratorPass),
+		url.QueryEscape(cm.AttendeePass),
 	)
 
 	if cm.RedirectAtLogout != "" {
@@ -76,5 +135,22 @@ func (cm *CreateMeeting) ParseCreateMeeting(ran service.RandStringInterface) (st
 		str += "&record=true"
 	}
 
+	if cm.MuteOnStart {
+		str += "&muteOnStart=true"
+	}
+
+	if cm.GuestPolicy != "" {
+		str += fmt.Sprintf("&guestPolicy=%s", url.QueryEscape(cm.GuestPolicy))
+	}
+
+	if cm.IsBreakout {
+		str += fmt.Sprintf("&isBreakout=true&parentMeetingID=%s&sequence=%d", url.QueryEscape(cm.ParentMeetingId), cm.Sequence)
+		if cm.FreeJoin {
+			str += "&freeJoin=true"
+		}
+	}
+
+	str += cm.LockSettings.query()
+
 	return str, nil
 }
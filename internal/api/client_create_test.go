@@ -63,6 +63,14 @@ func TestParseCreateMeeting(t *testing.T) {
 		assert.Equal(t, "/create?name=hello+there&meetingID=aaaaaaaa&moderatorPW=mp&attendeePW=ap&logoutURL=https%3A%2F%2Fredirect.domain%2Fdashboard+user+six", out)
 	})
 
+	t.Run("Reserved characters in the ID and passwords should not add params", func(t *testing.T) {
+		sample := CreateMeeting{Name: "math", MeetingId: "math 101&record=true", ModeratorPass: "m+p=1", AttendeePass: "a#p%"}
+		out, err := sample.ParseCreateMeeting(fake)
+		require.NoError(t, err)
+		assert.Equal(t, "/create?name=math&meetingID=math+101%26record%3Dtrue&moderatorPW=m%2Bp%3D1&attendeePW=a%23p%25", out)
+		assert.Equal(t, "math 101&record=true", sample.MeetingId, "the ID should be kept as it is")
+	})
+
 	t.Run("Should include `record` boolean field if its true", func(t *testing.T) {
 		sample := CreateMeeting{Name: "meet one", ModeratorPass: "mp", AttendeePass: "ap", IsRecording: true}
 		out, err := sample.ParseCreateMeeting(fake)
@@ -83,4 +91,37 @@ func TestParseCreateMeeting(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "/create?name=meet+one&meetingID=aaaaaaaa&moderatorPW=mp&attendeePW=ap&record=true", out)
 	})
+
+	t.Run("Should include mute on start and only the enabled lock settings", func(t *testing.T) {
+		sample := CreateMeeting{Name: "exam", ModeratorPass: "mp", AttendeePass: "ap", MuteOnStart: true, LockSettings: LockSettings{DisableCam: true, DisablePrivateChat: true}}
+		out, err := sample.ParseCreateMeeting(fake)
+		require.NoError(t, err)
+		assert.Equal(t, "/create?name=exam&meetingID=aaaaaaaa&moderatorPW=mp&attendeePW=ap&muteOnStart=true&lockSettingsDisableCam=true&lockSettingsDisablePrivateChat=true", out)
+	})
+
+	t.Run("Should include guest policy", func(t *testing.T) {
+		sample := CreateMeeting{Name: "webinar", ModeratorPass: "mp", AttendeePass: "ap", GuestPolicy: GuestAskModerator}
+		out, err := sample.ParseCreateMeeting(fake)
+		require.NoError(t, err)
+		assert.Equal(t, "/create?name=webinar&meetingID=aaaaaaaa&moderatorPW=mp&attendeePW=ap&guestPolicy=ASK_MODERATOR", out)
+	})
+
+	t.Run("Error if guest policy is unknown", func(t *testing.T) {
+		sample := CreateMeeting{Name: "webinar", GuestPolicy: "ASK_ADMIN"}
+		_, err := sample.ParseCreateMeeting(fake)
+		require.Error(t, err)
+	})
+
+	t.Run("Should include breakout room params", func(t *testing.T) {
+		sample := CreateMeeting{Name: "Group 1", MeetingId: "meet01-breakout-1", ModeratorPass: "mp", AttendeePass: "ap", IsBreakout: true, ParentMeetingId: "abc-121212", Sequence: 1, FreeJoin: true}
+		out, err := sample.ParseCreateMeeting(fake)
+		require.NoError(t, err)
+		assert.Equal(t, "/create?name=Group+1&meetingID=meet01-breakout-1&moderatorPW=mp&attendeePW=ap&isBreakout=true&parentMeetingID=abc-121212&sequence=1&freeJoin=true", out)
+	})
+
+	t.Run("Error if breakout room has no parent", func(t *testing.T) {
+		sample := CreateMeeting{Name: "Group 1", IsBreakout: true, Sequence: 1}
+		_, err := sample.ParseCreateMeeting(fake)
+		require.Error(t, err)
+	})
 }
@@ -1,6 +1,9 @@
 package api
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 // EndMeeting format that needed to end a meeting.
 type EndMeeting struct {
@@ -22,8 +25,8 @@ func (e *EndMeeting) ParseEndMeeting() (string, error) {
 	str := fmt.Sprintf(
 		"/%s?meetingID=%s&password=%s",
 		End,
-		e.MeetingId,
-		e.Password,
+		url.QueryEscape(e.MeetingId),
+		url.QueryEscape(e.Password),
 	)
 
 	return str, nil
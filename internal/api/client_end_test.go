@@ -35,6 +35,12 @@ func TestParseEndMeeting(t *testing.T) {
 			expect:  "/end?meetingID=meet02&password=pass",
 			wantErr: false,
 		},
+		{
+			name:    "Should escape reserved characters in the ID and password",
+			sample:  EndMeeting{MeetingId: "meet 02&x=1", Password: "p+s#"},
+			expect:  "/end?meetingID=meet+02%26x%3D1&password=p%2Bs%23",
+			wantErr: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1,6 +1,9 @@
 package api
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 // IsRunning format that needed to check whether a meeting is running.
 type IsRunning struct {
@@ -17,7 +20,7 @@ func (i *IsRunning) ParseIsRunning() (string, error) {
 	str := fmt.Sprintf(
 		"/%s?meetingID=%s",
 		IsMeetingRun,
-		i.MeetingId,
+		url.QueryEscape(i.MeetingId),
 	)
 
 	return str, nil
@@ -25,6 +25,12 @@ func TestParseIsRunning(t *testing.T) {
 			expect:  "/isMeetingRunning?meetingID=meet01",
 			wantErr: false,
 		},
+		{
+			name:    "Should escape reserved characters in `meeting_id`",
+			sample:  IsRunning{MeetingId: "meet 01&x=1"},
+			expect:  "/isMeetingRunning?meetingID=meet+01%26x%3D1",
+			wantErr: false,
+		},
 	}
 
 	for _, tc := range testCases {
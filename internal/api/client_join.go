@@ -1,8 +1,10 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 )
 
 // JoinMeeting format that needed to join a meeting. This should be sent to BBB API as URL.
@@ -14,8 +16,174 @@ type JoinMeeting struct {
 	UserId     string `json:"user_id"`     // An identifier for this user that will help your application to identify which person this is. This user ID will be returned for this user in the getMeetingInfo API call so that you can check. Important.
 	Avatar     string `json:"avatar"`      // The link for the user’s avatar to be displayed.
 	IsGuest    bool   `json:"is_guest"`    // To indicate that the user is a guest.
+	Role       string `json:"role"`        // Either moderator or viewer. Could be used instead of password since BBB 2.4.
+
+	Redirect             *bool          `json:"redirect,omitempty"`               // Whether BBB redirect the browser to the client (default), or return the session token as XML.
+	ErrorRedirectUrl     string         `json:"error_redirect_url,omitempty"`     // The URL that the browser goes to when the join is rejected, instead of the XML error.
+	ExcludeFromDashboard bool           `json:"exclude_from_dashboard,omitempty"` // The user is not shown in the learning dashboard.
+	DefaultLayout        string         `json:"default_layout,omitempty"`         // Layout that the user start with. One of the Layout constants.
+	WebcamBackgroundUrl  string         `json:"webcam_background_url,omitempty"`  // The image that is used as the user's virtual webcam background.
+	ClientSettings       ClientSettings `json:"client_settings"`                  // Overrides of the BBB client settings for this user, sent as userdata-bbb_*.
+}
+
+// Layouts of BBB client that the user could start with.
+const (
+	LayoutCustom       = "CUSTOM_LAYOUT"
+	LayoutSmart        = "SMART_LAYOUT"
+	LayoutPresentation = "PRESENTATION_FOCUS"
+	LayoutVideo        = "VIDEO_FOCUS"
+)
+
+// ClientSettings overrides of the BBB client settings for a joining user. Only the given ones are
+// sent, so unset settings keep the defaults of BBB server.
+type ClientSettings struct {
+	AskForFeedbackOnLogout    *bool    `json:"ask_for_feedback_on_logout,omitempty"`               // Ask the user for feedback when they leave.
+	AutoJoinAudio             *bool    `json:"auto_join_audio,omitempty"`                          // Join the audio without asking for microphone or listen only.
+	ClientTitle               string   `json:"client_title,omitempty"`                             // Title of the browser tab.
+	ForceListenOnly           *bool    `json:"force_listen_only,omitempty"`                        // The user could only join the audio as listen only.
+	ListenOnlyMode            *bool    `json:"listen_only_mode,omitempty"`                         // Whether listen only is offered when joining the audio.
+	SkipCheckAudio            *bool    `json:"skip_check_audio,omitempty"`                         // Skip the echo test when joining the audio.
+	SkipCheckAudioOnFirstJoin *bool    `json:"skip_check_audio_on_first_join,omitempty"`           // Skip the echo test only the first time the user join the audio.
+	OverrideDefaultLocale     string   `json:"override_default_locale,omitempty"`                  // Language of the client, such as id or en.
+	DisplayBrandingArea       *bool    `json:"display_branding_area,omitempty"`                    // Show the logo in the user list area.
+	Shortcuts                 string   `json:"shortcuts,omitempty"`                                // Shortcuts that are enabled, such as ["openOptions", "toggleUserList"].
+	AutoSwapLayout            *bool    `json:"auto_swap_layout,omitempty"`                         // Minimize the presentation when joining.
+	HidePresentation          *bool    `json:"hide_presentation,omitempty"`                        // Hide the presentation area.
+	HideActionsBar            *bool    `json:"hide_actions_bar,omitempty"`                         // Hide the actions bar at the bottom.
+	HideNavBar                *bool    `json:"hide_nav_bar,omitempty"`                             // Hide the navigation bar at the top.
+	ShowParticipantsOnLogin   *bool    `json:"show_participants_on_login,omitempty"`               // Open the user list when joining.
+	ShowPublicChatOnLogin     *bool    `json:"show_public_chat_on_login,omitempty"`                // Open the public chat when joining.
+	CustomStyle               string   `json:"custom_style,omitempty"`                             // CSS that is applied to the client.
+	CustomStyleUrl            string   `json:"custom_style_url,omitempty"`                         // URL of a CSS file that is applied to the client.
+	AutoShareWebcam           *bool    `json:"auto_share_webcam,omitempty"`                        // Share the webcam when joining.
+	PreferredCameraProfile    string   `json:"preferred_camera_profile,omitempty"`                 // Quality of the webcam. One of low, medium, high or hd.
+	EnableVideo               *bool    `json:"enable_video,omitempty"`                             // Whether the user could see and share webcams.
+	RecordVideo               *bool    `json:"record_video,omitempty"`                             // Whether the webcam of the user is recorded.
+	SkipVideoPreview          *bool    `json:"skip_video_preview,omitempty"`                       // Skip the webcam preview before sharing it.
+	MirrorOwnWebcam           *bool    `json:"mirror_own_webcam,omitempty"`                        // Show the own webcam mirrored.
+	ForceRestorePresentation  *bool    `json:"force_restore_presentation_on_new_events,omitempty"` // Restore the minimized presentation when it changes.
+	MultiUserPenOnly          *bool    `json:"multi_user_pen_only,omitempty"`                      // Only the pencil is available in multi user whiteboard.
+	PresenterTools            []string `json:"presenter_tools,omitempty"`                          // Whiteboard tools available to the presenter. Any of WhiteboardTools.
+	MultiUserTools            []string `json:"multi_user_tools,omitempty"`                         // Whiteboard tools available to the others in multi user whiteboard. Any of WhiteboardTools.
+}
+
+// WhiteboardTools the tools of BBB whiteboard that could be given to presenter_tools and multi_user_tools.
+var WhiteboardTools = []string{"text", "line", "ellipse", "rectangle", "triangle", "pencil", "hand"}
+
+// cameraProfiles the webcam qualities of BBB client.
+var cameraProfiles = []string{"low", "medium", "high", "hd"}
+
+// validate check the settings that only accept some values.
+func (cs *ClientSettings) validate() error {
+	if cs.PreferredCameraProfile != "" && !contains(cameraProfiles, cs.PreferredCameraProfile) {
+		return fmt.Errorf("`preferred_camera_profile` must be one of %s", strings.Join(cameraProfiles, ", "))
+	}
+
+	if cs.CustomStyleUrl != "" && !isWebUrl(cs.CustomStyleUrl) {
+		return fmt.Errorf("`custom_style_url` must be an http(s) url")
+	}
+
+	for _, tools := range []struct {
+		field string
+		names []string
+	}{
+		{"presenter_tools", cs.PresenterTools},
+		{"multi_user_tools", cs.MultiUserTools},
+	} {
+		for _, name := range tools.names {
+			if !contains(WhiteboardTools, name) {
+				return fmt.Errorf("`%s` must be any of %s", tools.field, strings.Join(WhiteboardTools, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// query return the given settings as BBB API query of userdata-bbb_* params with escaped values.
+func (cs *ClientSettings) query() (string, error) {
+	var str string
+	flag := func(param string, v *bool) {
+		if v != nil {
+			str += fmt.Sprintf("&userdata-bbb_%s=%t", param, *v)
+		}
+	}
+	text := func(param, v string) {
+		if v != "" {
+			str += fmt.Sprintf("&userdata-bbb_%s=%s", param, url.QueryEscape(v))
+		}
+	}
+
+	flag("ask_for_feedback_on_logout", cs.AskForFeedbackOnLogout)
+	flag("auto_join_audio", cs.AutoJoinAudio)
+	text("client_title", cs.ClientTitle)
+	flag("force_listen_only", cs.ForceListenOnly)
+	flag("listen_only_mode", cs.ListenOnlyMode)
+	flag("skip_check_audio", cs.SkipCheckAudio)
+	flag("skip_check_audio_on_first_join", cs.SkipCheckAudioOnFirstJoin)
+	text("override_default_locale", cs.OverrideDefaultLocale)
+	flag("display_branding_area", cs.DisplayBrandingArea)
+	text("shortcuts", cs.Shortcuts)
+	flag("auto_swap_layout", cs.AutoSwapLayout)
+	flag("hide_presentation", cs.HidePresentation)
+	flag("hide_actions_bar", cs.HideActionsBar)
+	flag("hide_nav_bar", cs.HideNavBar)
+	flag("show_participants_on_login", cs.ShowParticipantsOnLogin)
+	flag("show_public_chat_on_login", cs.ShowPublicChatOnLogin)
+	text("custom_style", cs.CustomStyle)
+	text("custom_style_url", cs.CustomStyleUrl)
+	flag("auto_share_webcam", cs.AutoShareWebcam)
+	text("preferred_camera_profile", cs.PreferredCameraProfile)
+	flag("enable_video", cs.EnableVideo)
+	flag("record_video", cs.RecordVideo)
+	flag("skip_video_preview", cs.SkipVideoPreview)
+	flag("mirror_own_webcam", cs.MirrorOwnWebcam)
+	flag("force_restore_presentation_on_new_events", cs.ForceRestorePresentation)
+	flag("multi_user_pen_only", cs.MultiUserPenOnly)
+
+	// BBB client reads the tools as json array.
+	for _, tools := range []struct {
+		param string
+		names []string
+	}{
+		{"presenter_tools", cs.PresenterTools},
+		{"multi_user_tools", cs.MultiUserTools},
+	} {
+		if len(tools.names) == 0 {
+			continue
+		}
+		b, err := json.Marshal(tools.names)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal `%s`: %s", tools.param, err)
+		}
+		text(tools.param, string(b))
+	}
+
+	return str, nil
+}
+
+// contains whether s is one of the given values.
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isWebUrl whether s is an absolute http or https url.
+func isWebUrl(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
+// Roles of user that join a meeting.
+const (
+	RoleModerator = "moderator"
+	RoleViewer    = "viewer"
+)
+
 // ParseJoinMeeting parse given request body binding from json and convert them
 // to url string that meet BBB API requirements.
 func (j *JoinMeeting) ParseJoinMeeting() (string, error) {
@@ -27,22 +195,44 @@ func (j *JoinMeeting) ParseJoinMeeting() (string, error) {
 		return "", fmt.Errorf("`meeting_id` is required")
 	}
 
-	if j.Password == "" {
-		return "", fmt.Errorf("`password` is required")
+	if j.Role != "" && j.Role != RoleModerator && j.Role != RoleViewer {
+		return "", fmt.Errorf("`role` must be either moderator or viewer")
+	}
+
+	if j.Password == "" && j.Role == "" {
+		return "", fmt.Errorf("either `password` or `role` is required")
 	}
 
 	if j.CreateTime == "" {
 		return "", fmt.Errorf("`created_time` is required")
 	}
 
-	str := fmt.Sprintf(
-		"/%s?meetingID=%s&password=%s&fullName=%s&createTime=%s",
-		Join,
-		j.MeetingId,
-		j.Password,
-		url.QueryEscape(j.Name),
-		j.CreateTime,
-	)
+	layouts := []string{LayoutCustom, LayoutSmart, LayoutPresentation, LayoutVideo}
+	if j.DefaultLayout != "" && !contains(layouts, j.DefaultLayout) {
+		return "", fmt.Errorf("`default_layout` must be one of %s", strings.Join(layouts, ", "))
+	}
+
+	if j.ErrorRedirectUrl != "" && !isWebUrl(j.ErrorRedirectUrl) {
+		return "", fmt.Errorf("`error_redirect_url` must be an http(s) url")
+	}
+
+	if j.WebcamBackgroundUrl != "" && !isWebUrl(j.WebcamBackgroundUrl) {
+		return "", fmt.Errorf("`webcam_background_url` must be an http(s) url")
+	}
+
+	if err := j.ClientSettings.validate(); err != nil {
+		return "", err
+	}
+
+	str := fmt.Sprintf("/%s?meetingID=%s", Join, url.QueryEscape(j.MeetingId))
+	if j.Password != "" {
+		str += fmt.Sprintf("&password=%s", url.QueryEscape(j.Password))
+	}
+	str += fmt.Sprintf("&fullName=%s&createTime=%s", url.QueryEscape(j.Name), url.QueryEscape(j.CreateTime))
+
+	if j.Role != "" {
+		str += fmt.Sprintf("&role=%s", url.QueryEscape(strings.ToUpper(j.Role)))
+	}
 
 	if j.UserId != "" {
 		j.UserId = url.QueryEscape(j.UserId)
@@ -58,5 +248,31 @@ func (j *JoinMeeting) ParseJoinMeeting() (string, error) {
 		str += "&guest=true"
 	}
 
+	if j.Redirect != nil {
+		str += fmt.Sprintf("&redirect=%t", *j.Redirect)
+	}
+
+	if j.ErrorRedirectUrl != "" {
+		str += fmt.Sprintf("&errorRedirectUrl=%s", url.QueryEscape(j.ErrorRedirectUrl))
+	}
+
+	if j.ExcludeFromDashboard {
+		str += "&excludeFromDashboard=true"
+	}
+
+	if j.DefaultLayout != "" {
+		str += fmt.Sprintf("&defaultLayout=%s", url.QueryEscape(j.DefaultLayout))
+	}
+
+	if j.WebcamBackgroundUrl != "" {
+		str += fmt.Sprintf("&webcamBackgroundURL=%s", url.QueryEscape(j.WebcamBackgroundUrl))
+	}
+
+	userdata, err := j.ClientSettings.query()
+	if err != nil {
+		return "", err
+	}
+	str += userdata
+
 	return str, nil
 }
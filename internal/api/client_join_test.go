@@ -34,6 +34,16 @@ func TestParseJoinMeeting(t *testing.T) {
 			sample:  JoinMeeting{Name: "name", MeetingId: "meet01", Password: "pass"},
 			wantErr: true,
 		},
+		{
+			name:    "Error if `role` is neither moderator nor viewer",
+			sample:  JoinMeeting{Name: "name", MeetingId: "meet01", Role: "admin", CreateTime: "273648"},
+			wantErr: true,
+		},
+		{
+			name:   "Pass using role instead of password",
+			sample: JoinMeeting{MeetingId: "meet01", Role: "moderator", Name: "Fake", CreateTime: "273648"},
+			expect: "/join?meetingID=meet01&fullName=Fake&createTime=273648&role=MODERATOR",
+		},
 		{
 			name:   "Pass if all required fields provided",
 			sample: JoinMeeting{MeetingId: "meet01", Password: "ap", Name: "Fake", CreateTime: "273648"},
@@ -44,6 +54,11 @@ func TestParseJoinMeeting(t *testing.T) {
 			sample: JoinMeeting{MeetingId: "meet01", Password: "ap", Name: "Fake Using Whitespace", CreateTime: "273648"},
 			expect: "/join?meetingID=meet01&password=ap&fullName=Fake+Using+Whitespace&createTime=273648",
 		},
+		{
+			name:   "Reserved characters in the ID and password should not add params",
+			sample: JoinMeeting{MeetingId: "math&role=MODERATOR", Password: "a+b=c#d", Name: "Fake", CreateTime: "273648&x=1"},
+			expect: "/join?meetingID=math%26role%3DMODERATOR&password=a%2Bb%3Dc%23d&fullName=Fake&createTime=273648%26x%3D1",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -106,3 +121,84 @@ func TestParseJoinMeeting_OptionalParams(t *testing.T) {
 		})
 	}
 }
+
+func TestParseJoinMeeting_JoinParams(t *testing.T) {
+	yes, no := true, false
+	base := "/join?meetingID=meet01&password=ap&fullName=Fake&createTime=273648"
+
+	testCases := []struct {
+		name    string
+		sample  JoinMeeting
+		expect  string
+		wantErr bool
+	}{
+		{
+			name:   "Redirect should only be sent if given",
+			sample: JoinMeeting{Redirect: &no},
+			expect: base + "&redirect=false",
+		},
+		{
+			name:   "Urls should be encoded",
+			sample: JoinMeeting{ErrorRedirectUrl: "https://lms.example/error?meet=01", WebcamBackgroundUrl: "https://lms.example/bg 01.png"},
+			expect: base + "&errorRedirectUrl=https%3A%2F%2Flms.example%2Ferror%3Fmeet%3D01&webcamBackgroundURL=https%3A%2F%2Flms.example%2Fbg+01.png",
+		},
+		{
+			name:   "Dashboard and layout",
+			sample: JoinMeeting{ExcludeFromDashboard: true, DefaultLayout: LayoutVideo},
+			expect: base + "&excludeFromDashboard=true&defaultLayout=VIDEO_FOCUS",
+		},
+		{
+			name: "Client settings should be sent as userdata including the disabled ones",
+			sample: JoinMeeting{ClientSettings: ClientSettings{
+				AutoJoinAudio:    &yes,
+				SkipCheckAudio:   &yes,
+				ForceListenOnly:  &no,
+				HidePresentation: &yes,
+				CustomStyle:      ":root{--loader-bg:#000;}",
+				PresenterTools:   []string{"pencil", "hand"},
+			}},
+			expect: base + "&userdata-bbb_auto_join_audio=true&userdata-bbb_force_listen_only=false&userdata-bbb_skip_check_audio=true" +
+				"&userdata-bbb_hide_presentation=true&userdata-bbb_custom_style=%3Aroot%7B--loader-bg%3A%23000%3B%7D" +
+				"&userdata-bbb_presenter_tools=%5B%22pencil%22%2C%22hand%22%5D",
+		},
+		{
+			name:    "Error if layout is unknown",
+			sample:  JoinMeeting{DefaultLayout: "GRID"},
+			wantErr: true,
+		},
+		{
+			name:    "Error if error redirect url is not a web url",
+			sample:  JoinMeeting{ErrorRedirectUrl: "javascript:alert(1)"},
+			wantErr: true,
+		},
+		{
+			name:    "Error if webcam background url is relative",
+			sample:  JoinMeeting{WebcamBackgroundUrl: "/bg.png"},
+			wantErr: true,
+		},
+		{
+			name:    "Error if camera profile is unknown",
+			sample:  JoinMeeting{ClientSettings: ClientSettings{PreferredCameraProfile: "4k"}},
+			wantErr: true,
+		},
+		{
+			name:    "Error if whiteboard tool is unknown",
+			sample:  JoinMeeting{ClientSettings: ClientSettings{MultiUserTools: []string{"pencil", "laser"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.sample.Name, tc.sample.MeetingId, tc.sample.Password, tc.sample.CreateTime = "Fake", "meet01", "ap", "273648"
+			out, err := tc.sample.ParseJoinMeeting()
+			switch tc.wantErr {
+			case true:
+				require.Error(t, err)
+			case false:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expect, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// MeetingInfo format that needed to get the details of a meeting.
+type MeetingInfo struct {
+	MeetingId string `json:"meeting_id"` // The meeting ID that identifies the meeting you are attempting to check on. Required.
+}
+
+// MeetingInfoResponse holds data from BBB API response after get the details of a meeting.
+type MeetingInfoResponse struct {
+	StdResponse
+	MeetingName           string     `xml:"meetingName" json:"meeting_name"`
+	MeetingId             string     `xml:"meetingID" json:"meeting_id"`
+	InternalMeetingId     string     `xml:"internalMeetingID" json:"internal_meeting_id"`
+	CreateTime            string     `xml:"createTime" json:"create_time"`
+	CreatedAt             string     `xml:"createDate" json:"created_at"`
+	Running               bool       `xml:"running" json:"running"`
+	Recording             bool       `xml:"recording" json:"recording"`
+	HasUserJoined         bool       `xml:"hasUserJoined" json:"has_user_joined"`
+	StartTime             int64      `xml:"startTime" json:"start_time"`
+	EndTime               int64      `xml:"endTime" json:"end_time"`
+	ParticipantCount      int        `xml:"participantCount" json:"participant_count"`
+	ListenerCount         int        `xml:"listenerCount" json:"listener_count"`
+	VoiceParticipantCount int        `xml:"voiceParticipantCount" json:"voice_participant_count"`
+	VideoCount            int        `xml:"videoCount" json:"video_count"`
+	MaxUsers              int        `xml:"maxUsers" json:"max_users"`
+	ModeratorCount        int        `xml:"moderatorCount" json:"moderator_count"`
+	IsBreakout            bool       `xml:"isBreakout" json:"is_breakout"`
+	ParentMeetingId       string     `xml:"parentMeetingID" json:"parent_meeting_id,omitempty"` // Internal meeting ID of the parent if this is a breakout room.
+	Sequence              int        `xml:"sequence" json:"sequence,omitempty"`                 // Number of the breakout room.
+	FreeJoin              bool       `xml:"freeJoin" json:"free_join,omitempty"`                // Users could choose which breakout room to join.
+	BreakoutRooms         []string   `xml:"breakoutRooms>breakout" json:"breakout_rooms"`       // Internal meeting IDs of the breakout rooms.
+	Attendees             []Attendee `xml:"attendees>attendee" json:"attendees"`
+}
+
+// Attendee holds data of a user that is currently in a meeting.
+type Attendee struct {
+	UserId          string `xml:"userID" json:"user_id"` // The user ID that was given when joining the meeting.
+	FullName        string `xml:"fullName" json:"name"`
+	Role            string `xml:"role" json:"role"`
+	IsPresenter     bool   `xml:"isPresenter" json:"is_presenter"`
+	IsListeningOnly bool   `xml:"isListeningOnly" json:"is_listening_only"`
+	HasJoinedVoice  bool   `xml:"hasJoinedVoice" json:"has_joined_voice"`
+	HasVideo        bool   `xml:"hasVideo" json:"has_video"`
+	ClientType      string `xml:"clientType" json:"client_type"`
+}
+
+// ParseMeetingInfo parse the given object, sanitize it, then transform it to format
+// that match BBB API requirement to get the details of a meeting.
+func (m *MeetingInfo) ParseMeetingInfo() (string, error) {
+	if m.MeetingId == "" {
+		return "", fmt.Errorf("`meeting_id` field is required")
+	}
+
+	str := fmt.Sprintf(
+		"/%s?meetingID=%s",
+		GetMeetingDetail,
+		url.QueryEscape(m.MeetingId),
+	)
+
+	return str, nil
+}
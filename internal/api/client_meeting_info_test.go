@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMeetingInfo(t *testing.T) {
+	t.Run("Should error if `meeting_id` field is not provided", func(t *testing.T) {
+		sample := MeetingInfo{}
+		_, err := sample.ParseMeetingInfo()
+		require.Error(t, err)
+	})
+
+	t.Run("Should pass if `meeting_id` field is provided", func(t *testing.T) {
+		sample := MeetingInfo{MeetingId: "meet 01"}
+		out, err := sample.ParseMeetingInfo()
+		require.NoError(t, err)
+		assert.Equal(t, "/getMeetingInfo?meetingID=meet+01", out)
+	})
+}
+
+func TestMeetingInfoResponse(t *testing.T) {
+	sample := `<response>
+<returncode>SUCCESS</returncode>
+<meetingName>meet one</meetingName>
+<meetingID>meet01</meetingID>
+<running>true</running>
+<participantCount>2</participantCount>
+<moderatorCount>1</moderatorCount>
+<attendees>
+<attendee><userID>usr01</userID><fullName>NzK</fullName><role>MODERATOR</role><isPresenter>true</isPresenter></attendee>
+<attendee><userID>usr02</userID><fullName>Mhs</fullName><role>VIEWER</role><isPresenter>false</isPresenter><hasJoinedVoice>true</hasJoinedVoice></attendee>
+</attendees>
+</response>`
+
+	var res MeetingInfoResponse
+	require.NoError(t, xml.Unmarshal([]byte(sample), &res))
+
+	assert.Equal(t, "SUCCESS", res.CodeString)
+	assert.True(t, res.Running)
+	assert.Equal(t, 2, res.ParticipantCount)
+	require.Len(t, res.Attendees, 2)
+	assert.Equal(t, "usr01", res.Attendees[0].UserId)
+	assert.True(t, res.Attendees[0].IsPresenter)
+	assert.True(t, res.Attendees[1].HasJoinedVoice)
+}
@@ -0,0 +1,15 @@
+package api
+
+import "fmt"
+
+// MeetingsResponse holds data from BBB API response after get the list of every meeting in
+// BBB server. Every meeting has the same details as getMeetingInfo.
+type MeetingsResponse struct {
+	StdResponse
+	Meetings []MeetingInfoResponse `xml:"meetings>meeting" json:"meetings"`
+}
+
+// ParseMeetings return format that match BBB API requirement to get the list of meetings.
+func ParseMeetings() string {
+	return fmt.Sprintf("/%s?", GetAllMeetings)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMeetings(t *testing.T) {
+	assert.Equal(t, "/getMeetings?", ParseMeetings())
+}
+
+func TestMeetingsResponse_Unmarshal(t *testing.T) {
+	sample := `<response>
+	<returncode>SUCCESS</returncode>
+	<meetings>
+		<meeting>
+			<meetingID>meet01</meetingID>
+			<running>true</running>
+			<recording>true</recording>
+			<participantCount>12</participantCount>
+			<listenerCount>3</listenerCount>
+			<voiceParticipantCount>9</voiceParticipantCount>
+			<videoCount>4</videoCount>
+		</meeting>
+		<meeting>
+			<meetingID>meet02</meetingID>
+			<running>false</running>
+		</meeting>
+	</meetings>
+</response>`
+
+	var res MeetingsResponse
+	require.NoError(t, xml.Unmarshal([]byte(sample), &res))
+	require.Len(t, res.Meetings, 2)
+	assert.Equal(t, "meet01", res.Meetings[0].MeetingId)
+	assert.True(t, res.Meetings[0].Recording)
+	assert.Equal(t, 12, res.Meetings[0].ParticipantCount)
+	assert.Equal(t, 4, res.Meetings[0].VideoCount)
+	assert.False(t, res.Meetings[1].Running)
+}
@@ -7,8 +7,9 @@ import (
 
 // Config holds BBB api-related data.
 type Config struct {
-	Secret string `yaml:"secret"`
-	Host   string `yaml:"host"`
+	Secret   string `yaml:"secret"`
+	Host     string `yaml:"host"`
+	RoleJoin bool   `yaml:"role_join"` // BBB server is 2.4 or later, so users join using role instead of password.
 }
 
 // Sanitization check and sanitize api config instance.
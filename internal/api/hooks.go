@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// HookCreate format that needed to register a hook in bbb-webhooks.
+type HookCreate struct {
+	CallbackUrl string // The URL that will receive a POST call with the events. Required.
+	MeetingId   string // A meeting ID to bind to this hook. Optional, all meetings if not provided.
+	GetRaw      bool   // Whether the events should be sent as they were received by bbb-webhooks.
+}
+
+// HookCreateResponse holds data from BBB API response after register a hook.
+type HookCreateResponse struct {
+	StdResponse
+	HookId        string `xml:"hookID"`
+	PermanentHook bool   `xml:"permanentHook"`
+	RawData       bool   `xml:"rawData"`
+}
+
+// HookDestroy format that needed to remove a registered hook in bbb-webhooks.
+type HookDestroy struct {
+	HookId string // The ID of the hook that should be removed. Required.
+}
+
+// HookDestroyResponse holds data from BBB API response after remove a hook.
+type HookDestroyResponse struct {
+	StdResponse
+	Removed bool `xml:"removed"`
+}
+
+// Hook holds data of a registered hook in bbb-webhooks.
+type Hook struct {
+	HookId        string `xml:"hookID"`
+	CallbackUrl   string `xml:"callbackURL"`
+	MeetingId     string `xml:"meetingID"`
+	PermanentHook bool   `xml:"permanentHook"`
+	RawData       bool   `xml:"rawData"`
+}
+
+// HookListResponse holds data from BBB API response after listing hooks.
+type HookListResponse struct {
+	StdResponse
+	Hooks []Hook `xml:"hooks>hook"`
+}
+
+// ParseHookCreate parse the given object, sanitize it, then transform it to
+// format that match BBB API requirement to register a hook.
+func (h *HookCreate) ParseHookCreate() (string, error) {
+	if h.CallbackUrl == "" {
+		return "", fmt.Errorf("`callback_url` field is required")
+	}
+
+	str := fmt.Sprintf(
+		"/%s?callbackURL=%s",
+		HooksCreate,
+		url.QueryEscape(h.CallbackUrl),
+	)
+
+	if h.MeetingId != "" {
+		str += fmt.Sprintf("&meetingID=%s", url.QueryEscape(h.MeetingId))
+	}
+
+	if h.GetRaw {
+		str += "&getRaw=true"
+	}
+
+	return str, nil
+}
+
+// ParseHookDestroy parse the given object, sanitize it, then transform it to
+// format that match BBB API requirement to remove a hook.
+func (h *HookDestroy) ParseHookDestroy() (string, error) {
+	if h.HookId == "" {
+		return "", fmt.Errorf("`hook_id` field is required")
+	}
+
+	return fmt.Sprintf("/%s?hookID=%s", HooksDestroy, url.QueryEscape(h.HookId)), nil
+}
+
+// ParseHookList return format that match BBB API requirement to list hooks.
+func ParseHookList() string {
+	return fmt.Sprintf("/%s?", HooksList)
+}
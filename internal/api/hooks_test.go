@@ -0,0 +1,62 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHookCreate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sample  HookCreate
+		expect  string
+		wantErr bool
+	}{
+		{
+			name:    "Should error if callback url is not provided",
+			sample:  HookCreate{MeetingId: "meet01"},
+			wantErr: true,
+		},
+		{
+			name:   "Callback url should be encoded",
+			sample: HookCreate{CallbackUrl: "https://this.app/webhooks/bbb"},
+			expect: "/hooks/create?callbackURL=https%3A%2F%2Fthis.app%2Fwebhooks%2Fbbb",
+		},
+		{
+			name:   "Should include meeting id and raw flag if provided",
+			sample: HookCreate{CallbackUrl: "http://app", MeetingId: "meet 01", GetRaw: true},
+			expect: "/hooks/create?callbackURL=http%3A%2F%2Fapp&meetingID=meet+01&getRaw=true",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := tc.sample.ParseHookCreate()
+
+			switch tc.wantErr {
+			case false:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expect, out)
+			case true:
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestParseHookDestroy(t *testing.T) {
+	t.Run("Should error if hook id is not provided", func(t *testing.T) {
+		sample := HookDestroy{}
+		_, err := sample.ParseHookDestroy()
+		require.Error(t, err)
+	})
+
+	t.Run("Should pass if hook id is provided", func(t *testing.T) {
+		sample := HookDestroy{HookId: "1"}
+		out, err := sample.ParseHookDestroy()
+		require.NoError(t, err)
+		assert.Equal(t, "/hooks/destroy?hookID=1", out)
+	})
+}
@@ -12,6 +12,9 @@ const (
 	GetAllRecordings    = "getRecordings"       // Get a list of recordings.
 	DeleteRecording     = "deleteRecordings"    // Deletes an existing recording.
 	UpdateRecordingMeta = "updateRecordings"    // Updates metadata in a recording.
+	HooksCreate         = "hooks/create"        // Creates a new hook to receive events from bbb-webhooks.
+	HooksList           = "hooks/list"          // Get the list of registered hooks.
+	HooksDestroy        = "hooks/destroy"       // Removes a registered hook.
 	EndPoint            = "bigbluebutton/api"   // BBB API endpoint.
 )
 
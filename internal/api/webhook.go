@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/kurvaid/bbb-interface/internal/service"
+)
+
+// WebhookPayload holds form data that sent by bbb-webhooks to the registered callback url.
+type WebhookPayload struct {
+	Event     string `form:"event"`     // Json array of the events.
+	Timestamp string `form:"timestamp"` // When bbb-webhooks sent this payload in milliseconds.
+	Domain    string `form:"domain"`    // The domain of BBB server that sent this payload.
+}
+
+// WebhookEvent holds one event from bbb-webhooks.
+type WebhookEvent struct {
+	Data WebhookEventData `json:"data"`
+}
+
+// WebhookEventData holds the type and attributes of an event from bbb-webhooks.
+type WebhookEventData struct {
+	Type       string            `json:"type"`       // Always `event`.
+	Id         string            `json:"id"`         // The id of this event such as `user-joined`.
+	Attributes WebhookAttributes `json:"attributes"` // Detail of this event.
+	Event      struct {
+		Ts int64 `json:"ts"` // When this event happened in milliseconds.
+	} `json:"event"`
+}
+
+// WebhookAttributes holds detail of an event. Only the fields relevant to the event
+// would be populated.
+type WebhookAttributes struct {
+	Meeting     WebhookMeeting      `json:"meeting"`
+	User        *WebhookUser        `json:"user"`
+	ChatMessage *WebhookChatMessage `json:"chat-message"`
+	RecordId    string              `json:"record-id"`
+}
+
+// WebhookMeeting holds meeting data of an event.
+type WebhookMeeting struct {
+	InternalMeetingId string `json:"internal-meeting-id"`
+	ExternalMeetingId string `json:"external-meeting-id"`
+	Name              string `json:"name"`
+}
+
+// WebhookUser holds user data of an event.
+type WebhookUser struct {
+	InternalUserId string `json:"internal-user-id"`
+	ExternalUserId string `json:"external-user-id"`
+	Name           string `json:"name"`
+	Role           string `json:"role"`
+	Presenter      bool   `json:"presenter"`
+	Guest          bool   `json:"guest"`
+	Muted          bool   `json:"muted"`
+}
+
+// WebhookChatMessage holds chat message data of an event.
+type WebhookChatMessage struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+	Sender  struct {
+		InternalUserId string `json:"internal-user-id"`
+		Name           string `json:"name"`
+	} `json:"sender"`
+}
+
+// Checksum calculate the checksum of this payload the same way bbb-webhooks does,
+// which is the sha1 of the callback url, this payload as json and the secret.
+func (w *WebhookPayload) Checksum(callbackUrl, secret string) (string, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	// encode given string as json without the trailing newline from encoder.
+	writeStr := func(s string) error {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+
+	// keep the order of the keys as bbb-webhooks sent them.
+	buf.WriteString(`{"event":`)
+	if err := writeStr(w.Event); err != nil {
+		return "", fmt.Errorf("failed to encode event to json: %s", err)
+	}
+
+	// bbb-webhooks sent the timestamp as number.
+	buf.WriteString(`,"timestamp":`)
+	if _, err := strconv.ParseInt(w.Timestamp, 10, 64); err != nil {
+		if err := writeStr(w.Timestamp); err != nil {
+			return "", fmt.Errorf("failed to encode timestamp to json: %s", err)
+		}
+	} else {
+		buf.WriteString(w.Timestamp)
+	}
+
+	if w.Domain != "" {
+		buf.WriteString(`,"domain":`)
+		if err := writeStr(w.Domain); err != nil {
+			return "", fmt.Errorf("failed to encode domain to json: %s", err)
+		}
+	}
+	buf.WriteString("}")
+
+	return service.SHA1Hash(callbackUrl, buf.String(), secret), nil
+}
+
+// ParseEvents parse the json array of events in this payload.
+func (w *WebhookPayload) ParseEvents() ([]WebhookEvent, error) {
+	if w.Event == "" {
+		return nil, fmt.Errorf("`event` field is required")
+	}
+
+	var events []WebhookEvent
+	if err := json.Unmarshal([]byte(w.Event), &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal events: %s", err)
+	}
+
+	return events, nil
+}
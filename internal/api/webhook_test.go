@@ -0,0 +1,78 @@
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookPayloadChecksum(t *testing.T) {
+	hash := func(in string) string {
+		out := sha1.Sum([]byte(in))
+		return hex.EncodeToString(out[:])
+	}
+
+	testCases := []struct {
+		name   string
+		sample WebhookPayload
+		expect string
+	}{
+		{
+			name:   "Numeric timestamp should be sent as number",
+			sample: WebhookPayload{Event: `[{"data":{}}]`, Timestamp: "1502810164922", Domain: "bbb.test"},
+			expect: hash(`http://app/hook{"event":"[{\"data\":{}}]","timestamp":1502810164922,"domain":"bbb.test"}secret`),
+		},
+		{
+			name:   "Empty domain should not be included",
+			sample: WebhookPayload{Event: `[]`, Timestamp: "12"},
+			expect: hash(`http://app/hook{"event":"[]","timestamp":12}secret`),
+		},
+		{
+			name:   "Html characters should not be escaped",
+			sample: WebhookPayload{Event: `<&>`, Timestamp: "12"},
+			expect: hash(`http://app/hook{"event":"<&>","timestamp":12}secret`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := tc.sample.Checksum("http://app/hook", "secret")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, out)
+		})
+	}
+}
+
+func TestWebhookPayloadParseEvents(t *testing.T) {
+	t.Run("Should error if event is empty", func(t *testing.T) {
+		sample := WebhookPayload{}
+		_, err := sample.ParseEvents()
+		require.Error(t, err)
+	})
+
+	t.Run("Should error if event is not json array", func(t *testing.T) {
+		sample := WebhookPayload{Event: `{"data":`}
+		_, err := sample.ParseEvents()
+		require.Error(t, err)
+	})
+
+	t.Run("Should bind every event attributes", func(t *testing.T) {
+		sample := WebhookPayload{Event: `[
+{"data":{"type":"event","id":"chat-group-message-sent","attributes":{"meeting":{"internal-meeting-id":"int01","external-meeting-id":"meet01"},"chat-message":{"message":"hello","sender":{"internal-user-id":"w_1","name":"NzK"}}},"event":{"ts":1}}},
+{"data":{"type":"event","id":"rap-publish-ended","attributes":{"meeting":{"internal-meeting-id":"int01","external-meeting-id":"meet01"},"record-id":"rec01"},"event":{"ts":2}}}
+]`}
+		out, err := sample.ParseEvents()
+		require.NoError(t, err)
+		require.Len(t, out, 2)
+
+		assert.Equal(t, "chat-group-message-sent", out[0].Data.Id)
+		assert.Equal(t, "meet01", out[0].Data.Attributes.Meeting.ExternalMeetingId)
+		require.NotNil(t, out[0].Data.Attributes.ChatMessage)
+		assert.Equal(t, "hello", out[0].Data.Attributes.ChatMessage.Message)
+		assert.Equal(t, "rec01", out[1].Data.Attributes.RecordId)
+		assert.Equal(t, int64(2), out[1].Data.Event.Ts)
+	})
+}
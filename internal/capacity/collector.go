@@ -0,0 +1,80 @@
+package capacity
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/client"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+)
+
+// Collector periodically get every meeting of each BBB server, then record how much of the
+// server is used to the capacity gauges of the metrics.
+type Collector struct {
+	hCl      *http.Client
+	hosts    []api.Config
+	interval time.Duration
+}
+
+// NewCollector return new Collector that collect from the given BBB servers every interval.
+func NewCollector(hCl *http.Client, hosts []api.Config, interval time.Duration) *Collector {
+	return &Collector{hCl: hCl, hosts: hosts, interval: interval}
+}
+
+// Run collect from every BBB server right away, then each interval until the given channel is
+// closed. Failed collections would be reported to onErr if not nil.
+func (c *Collector) Run(stop <-chan struct{}, onErr func(error)) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, bbb := range c.hosts {
+			if err := c.Collect(bbb); err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect get every meeting of the given BBB server and record its usage. The server is
+// recorded as down if it fails.
+func (c *Collector) Collect(bbb api.Config) error {
+	host := metrics.Host(bbb.Host)
+
+	var res api.MeetingsResponse
+	if err := client.Call(c.hCl, bbb, api.ParseMeetings(), &res); err != nil {
+		metrics.CapacityDown(host)
+		return fmt.Errorf("failed to get meetings of %s: %s", host, err)
+	}
+
+	metrics.SetCapacity(host, Usage(res.Meetings))
+	return nil
+}
+
+// Usage sum the usage of the running meetings among the given ones.
+func Usage(meetings []api.MeetingInfoResponse) metrics.Capacity {
+	var c metrics.Capacity
+	for _, m := range meetings {
+		if !m.Running {
+			continue
+		}
+		c.Meetings++
+		c.Participants += m.ParticipantCount
+		c.VoiceParticipants += m.VoiceParticipantCount
+		c.Videos += m.VideoCount
+		c.Listeners += m.ListenerCount
+		if m.Recording {
+			c.RecordingMeetings++
+		}
+	}
+
+	return c
+}
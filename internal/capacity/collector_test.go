@@ -0,0 +1,70 @@
+package capacity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	usage := Usage([]api.MeetingInfoResponse{
+		{Running: true, Recording: true, ParticipantCount: 12, VoiceParticipantCount: 9, VideoCount: 4, ListenerCount: 3},
+		{Running: true, ParticipantCount: 5, VoiceParticipantCount: 5, VideoCount: 1},
+		{Running: false, Recording: true, ParticipantCount: 0},
+	})
+
+	assert.Equal(t, metrics.Capacity{
+		Meetings:          2,
+		Participants:      17,
+		VoiceParticipants: 14,
+		Videos:            5,
+		Listeners:         3,
+		RecordingMeetings: 1,
+	}, usage)
+}
+
+func TestCollector_Collect(t *testing.T) {
+	response := `<response><returncode>SUCCESS</returncode><meetings>
+<meeting><running>true</running><recording>true</recording><participantCount>12</participantCount><videoCount>4</videoCount></meeting>
+</meetings></response>`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.True(t, strings.HasSuffix(req.URL.Path, api.GetAllMeetings))
+		rw.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	bbb := api.Config{Host: server.URL, Secret: "secret"}
+	require.NoError(t, bbb.Sanitization())
+	host := metrics.Host(bbb.Host)
+	c := NewCollector(server.Client(), []api.Config{bbb}, 0)
+
+	require.NoError(t, c.Collect(bbb))
+	assert.NoError(t, testutil.GatherAndCompare(metrics.Registry, strings.NewReader(`
+# HELP bbb_interface_bbb_participants Participants of running meetings, by host.
+# TYPE bbb_interface_bbb_participants gauge
+bbb_interface_bbb_participants{host="`+host+`"} 12
+# HELP bbb_interface_bbb_up Whether the last getMeetings call to BBB server succeeded, by host.
+# TYPE bbb_interface_bbb_up gauge
+bbb_interface_bbb_up{host="`+host+`"} 1
+`), "bbb_interface_bbb_participants", "bbb_interface_bbb_up"))
+
+	t.Run("Server that fails should be down and keep the last usage", func(t *testing.T) {
+		response = `<response><returncode>FAILED</returncode><messageKey>checksumError</messageKey></response>`
+		assert.Error(t, c.Collect(bbb))
+		assert.NoError(t, testutil.GatherAndCompare(metrics.Registry, strings.NewReader(`
+# HELP bbb_interface_bbb_participants Participants of running meetings, by host.
+# TYPE bbb_interface_bbb_participants gauge
+bbb_interface_bbb_participants{host="`+host+`"} 12
+# HELP bbb_interface_bbb_up Whether the last getMeetings call to BBB server succeeded, by host.
+# TYPE bbb_interface_bbb_up gauge
+bbb_interface_bbb_up{host="`+host+`"} 0
+`), "bbb_interface_bbb_participants", "bbb_interface_bbb_up"))
+	})
+}
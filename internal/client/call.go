@@ -0,0 +1,63 @@
+package client
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/service"
+)
+
+// Error holds error response from BBB API.
+type Error struct {
+	Key     string // A unique key defined by BBB API to identify which error are thrown.
+	Message string // Detail message about the occurred error.
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("receiving error from BBB API: [%s] %s", e.Key, e.Message)
+}
+
+// IsNotFound check whether the given error is notFound error from BBB API, which
+// is returned when the meeting is not found or not running.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Key == "notFound"
+}
+
+// Call calculate the checksum of the given parsed url, send it to BBB API then
+// bind the xml response to v. Would return error if BBB API did not respond
+// with SUCCESS return code, in that case the error would be *Error.
+func Call(hCl *http.Client, bbb api.Config, uri string, v interface{}) error {
+	// prepare url and calculate their checksum.
+	out := service.SHA1HashUrl(bbb.Secret, uri)
+	uri = fmt.Sprintf("%s%s%s", bbb.Host, api.EndPoint, uri)
+
+	ins := Instance{Cl: hCl, Url: uri, Checksum: out}
+	resp, err := ins.DispatchGET()
+	if err != nil {
+		return err
+	}
+
+	var res api.StdResponse
+	if err := xml.Unmarshal(resp, &res); err != nil {
+		return fmt.Errorf("failed parsing BBB API response to std response object: %s", err)
+	}
+
+	// check if BBB API call success
+	if res.CodeString != "SUCCESS" {
+		return &Error{Key: res.MsgKey, Message: res.MsgDetail}
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := xml.Unmarshal(resp, v); err != nil {
+		return fmt.Errorf("failed binding BBB API response: %s", err)
+	}
+
+	return nil
+}
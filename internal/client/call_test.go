@@ -0,0 +1,66 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCall(t *testing.T) {
+	testCases := []struct {
+		name    string
+		resp    string
+		wantErr bool
+	}{
+		{
+			name: "Success should bind the response",
+			resp: `<response><returncode>SUCCESS</returncode><meetingID>meet01</meetingID></response>`,
+		},
+		{
+			name:    "Error if BBB API return FAILED",
+			resp:    `<response><returncode>FAILED</returncode><messageKey>notFound</messageKey></response>`,
+			wantErr: true,
+		},
+		{
+			name:    "Error if BBB API response is not xml",
+			resp:    `{"returncode": "SUCCESS"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/bigbluebutton/api/isMeetingRunning", req.URL.Path)
+				assert.NotEmpty(t, req.URL.Query().Get("checksum"))
+				rw.Write([]byte(tc.resp))
+			}))
+			defer server.Close()
+
+			bbb := api.Config{Host: server.URL, Secret: "secret"}
+			require.NoError(t, bbb.Sanitization())
+
+			var res api.CreateMeetingResponse
+			err := Call(server.Client(), bbb, "/isMeetingRunning?meetingID=meet01", &res)
+
+			switch tc.wantErr {
+			case false:
+				require.NoError(t, err)
+				assert.Equal(t, "meet01", res.MeetingId)
+			case true:
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	assert.True(t, IsNotFound(&Error{Key: "notFound"}))
+	assert.False(t, IsNotFound(&Error{Key: "invalidPassword"}))
+	assert.False(t, IsNotFound(fmt.Errorf("notFound")))
+}
@@ -1,9 +1,16 @@
 package client
 
 import (
+	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
 )
 
 // Instance holds data to send request to BBB API.
@@ -14,8 +21,29 @@ type Instance struct {
 }
 
 // DispatchGET take json and transform it to url. Send GET request to BBB API using it.
-// Then return response from BBB API.
+// Then return response from BBB API. Every call is recorded to the metrics.
 func (i *Instance) DispatchGET() ([]byte, error) {
+	start := time.Now()
+	body, err := i.dispatchGET()
+
+	result := metrics.ResultError
+	if err == nil {
+		var res api.StdResponse
+		switch {
+		case xml.Unmarshal(body, &res) != nil:
+		case res.CodeString == "FAILED":
+			result = metrics.ResultFailed
+		default:
+			result = metrics.ResultSuccess
+		}
+	}
+	host, call := callOf(i.Url)
+	metrics.ObserveBBBCall(host, call, result, time.Since(start))
+
+	return body, err
+}
+
+func (i *Instance) dispatchGET() ([]byte, error) {
 	// append checksum at the end of url.
 	url := fmt.Sprintf("%s&checksum=%s", i.Url, i.Checksum)
 
@@ -32,3 +60,19 @@ func (i *Instance) DispatchGET() ([]byte, error) {
 
 	return body, nil
 }
+
+// callOf return the host of BBB server and the API call, such as create or hooks/create, of the
+// given url.
+func callOf(rawUrl string) (host, call string) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", ""
+	}
+
+	call = u.Path
+	if n := strings.Index(call, api.EndPoint); n >= 0 {
+		call = call[n+len(api.EndPoint):]
+	}
+
+	return u.Host, strings.Trim(call, "/")
+}
@@ -149,3 +149,33 @@ func TestClientCreateMeeting_IncreaseCoverage(t *testing.T) {
 		server.Close()
 	})
 }
+
+func TestCallOf(t *testing.T) {
+	testCases := []struct {
+		name       string
+		url        string
+		expectHost string
+		expectCall string
+	}{
+		{
+			name:       "Call should be the path after BBB API endpoint",
+			url:        "https://bbb.example/bigbluebutton/api/isMeetingRunning?meetingID=meet01",
+			expectHost: "bbb.example",
+			expectCall: api.IsMeetingRun,
+		},
+		{
+			name:       "Call of bbb-webhooks should keep its prefix",
+			url:        "https://bbb.example/bigbluebutton/api/hooks/create?callbackURL=x",
+			expectHost: "bbb.example",
+			expectCall: api.HooksCreate,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			host, call := callOf(tt.url)
+			assert.Equal(t, tt.expectHost, host)
+			assert.Equal(t, tt.expectCall, call)
+		})
+	}
+}
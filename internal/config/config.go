@@ -5,10 +5,13 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"strings"
 
 	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/preset"
+	"github.com/kurvaid/bbb-interface/internal/service"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,19 +22,56 @@ type Interface interface {
 	SanitizationLog()
 }
 
+// Ways meeting IDs that are given by clients are namespaced, so IDs of different clients don't
+// collide.
+const (
+	NamespaceNone   = "none"   // Meeting ID is used as it is.
+	NamespacePrefix = "prefix" // Meeting ID is prefixed with the client name, e.g. lms-math101.
+	NamespaceHash   = "hash"   // Meeting ID is SHA1 hex of the client name and the given ID, w a check of it.
+)
+
 // Model holds data from config file.
 type Model struct {
-	EnvIsProd                bool
-	Env                      string     `yaml:"env"`
-	Host                     string     `yaml:"host"`
-	PortNum                  uint16     `yaml:"port"`
-	LogDir                   string     `yaml:"log"`
-	RandomLen                uint8      `yaml:"random_len"`
-	BBB                      api.Config `yaml:"BBB"`
-	Token                    string     `yaml:"token"`
-	CallbackOnDestroyThisApp string     `yaml:"callback_on_destroy_this_app"`
-	CallbackOnDestroy        string     `yaml:"callback_on_destroy"`
-	LogFile                  *os.File
+	EnvIsProd                  bool
+	Env                        string                     `yaml:"env"`
+	Host                       string                     `yaml:"host"`
+	PortNum                    uint16                     `yaml:"port"`
+	LogDir                     string                     `yaml:"log"`
+	LogLevel                   string                     `yaml:"log_level"`
+	RandomLen                  uint8                      `yaml:"random_len"`
+	RandomAlphabet             string                     `yaml:"random_alphabet"`
+	RandomEntropy              uint16                     `yaml:"random_entropy"`
+	MeetingIdFormat            string                     `yaml:"meeting_id_format"`
+	MeetingIdNamespace         string                     `yaml:"meeting_id_namespace"`
+	PollInterval               uint16                     `yaml:"poll_interval"`
+	CapacityInterval           uint16                     `yaml:"capacity_interval"`
+	SeriesHorizon              uint16                     `yaml:"series_horizon"`
+	JoinLinkTTL                uint32                     `yaml:"join_link_ttl"`
+	JoinLinkMaxTTL             uint32                     `yaml:"join_link_max_ttl"`
+	JoinLinkSecret             string                     `yaml:"join_link_secret"`
+	CalendarSecret             string                     `yaml:"calendar_secret"`
+	LobbyRefresh               uint16                     `yaml:"lobby_refresh"`
+	IdempotencyTTL             uint32                     `yaml:"idempotency_ttl"`
+	DBPath                     string                     `yaml:"db"`
+	PublicUrl                  string                     `yaml:"public_url"`
+	BBB                        api.Config                 `yaml:"BBB"`
+	Token                      string                     `yaml:"token"`
+	MetricsToken               string                     `yaml:"metrics_token"`
+	CallbackOnDestroyThisApp   string                     `yaml:"callback_on_destroy_this_app"`
+	CallbackOnDestroy          string                     `yaml:"callback_on_destroy"`
+	CallbackOnWebhookThisApp   string                     `yaml:"callback_on_webhook_this_app"`
+	CallbackOnAnalyticsThisApp string                     `yaml:"callback_on_analytics_this_app"`
+	Clients                    []Client                   `yaml:"clients"`
+	Templates                  map[string]preset.Settings `yaml:"templates"`
+	LogFile                    *os.File
+}
+
+// Client holds data of a client app that use this service.
+type Client struct {
+	Name            string   `yaml:"name"`              // Name to identify this client.
+	Token           string   `yaml:"token"`             // Token to authenticate incoming request from this client.
+	CallbackOnEvent string   `yaml:"callback_on_event"` // Endpoint that would receive meeting events as json POST request.
+	Events          []string `yaml:"events"`            // Event types this client subscribed to. Empty means all events.
 }
 
 // NewConfig read io.Reader then map and load the value to the returned Model.
@@ -76,6 +116,17 @@ func (m *Model) ReloadConfig(fileBuf io.Reader) error {
 	return nil
 }
 
+// ClientByToken return the client that use the given token.
+func (m *Model) ClientByToken(token string) (Client, bool) {
+	for _, cl := range m.Clients {
+		if cl.Token != "" && cl.Token == token {
+			return cl, true
+		}
+	}
+
+	return Client{}, false
+}
+
 // Sanitization check and sanitize config Model's instance.
 func (m *Model) Sanitization() error {
 	if m.Env == "" || (m.Env != "dev" && m.Env != "prod") {
@@ -93,10 +144,100 @@ func (m *Model) Sanitization() error {
 		m.PortNum = 6767
 	}
 
+	switch m.LogLevel {
+	case "":
+		m.LogLevel = "info"
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("`log_level` must be either debug, info, warn or error")
+	}
+
+	if m.RandomAlphabet == "" {
+		m.RandomAlphabet = service.DefaultAlphabet
+	}
+	if err := service.ValidateAlphabet(m.RandomAlphabet); err != nil {
+		return fmt.Errorf("`random_alphabet` is invalid: %s", err)
+	}
+
+	// entropy is the strength that is wanted, the length is derived from it.
+	if m.RandomEntropy > 0 {
+		n := service.LengthForEntropy(int(m.RandomEntropy), m.RandomAlphabet)
+		if n > math.MaxUint8 {
+			return fmt.Errorf("`random_entropy` needs %d characters, which is more than %d", n, math.MaxUint8)
+		}
+		m.RandomLen = uint8(n)
+	}
+
 	if m.RandomLen == 0 {
 		m.RandomLen = 8
 	}
 
+	switch m.MeetingIdFormat {
+	case "":
+		m.MeetingIdFormat = service.IdLetters
+	case service.IdLetters, service.IdUUID, service.IdULID:
+	default:
+		return fmt.Errorf("`meeting_id_format` must be either letters, uuid or ulid")
+	}
+
+	switch m.MeetingIdNamespace {
+	case "":
+		m.MeetingIdNamespace = NamespaceNone
+	case NamespaceNone, NamespacePrefix, NamespaceHash:
+	default:
+		return fmt.Errorf("`meeting_id_namespace` must be either none, prefix or hash")
+	}
+
+	if m.PollInterval == 0 {
+		m.PollInterval = 10
+	}
+
+	if m.CapacityInterval == 0 {
+		m.CapacityInterval = 30
+	}
+
+	if m.SeriesHorizon == 0 {
+		m.SeriesHorizon = 14
+	}
+
+	if m.JoinLinkTTL == 0 {
+		m.JoinLinkTTL = 300
+	}
+
+	if m.JoinLinkMaxTTL == 0 {
+		m.JoinLinkMaxTTL = 86400
+	}
+	if m.JoinLinkTTL > m.JoinLinkMaxTTL {
+		return fmt.Errorf("`join_link_ttl` must not be more than `join_link_max_ttl`")
+	}
+
+	// links that were signed w a generated secret stop working when this app is restarted.
+	if m.JoinLinkSecret == "" {
+		m.JoinLinkSecret = (&service.SecureString{Length: 43}).RandString()
+	}
+
+	// calendar links that were keyed w a generated secret stop working when this app is restarted.
+	if m.CalendarSecret == "" {
+		m.CalendarSecret = (&service.SecureString{Length: 43}).RandString()
+	}
+
+	if m.LobbyRefresh == 0 {
+		m.LobbyRefresh = 5
+	}
+
+	if m.IdempotencyTTL == 0 {
+		m.IdempotencyTTL = 86400
+	}
+
+	if m.DBPath == "" {
+		m.DBPath = "./bbb-interface.db"
+	}
+
+	if m.PublicUrl == "" {
+		m.PublicUrl = fmt.Sprintf("http://%s:%d", m.Host, m.PortNum)
+	}
+	m.PublicUrl = strings.TrimSuffix(m.PublicUrl, "/")
+
 	if m.CallbackOnDestroyThisApp == "" {
 		m.CallbackOnDestroyThisApp = "http://localhost"
 	}
@@ -113,6 +254,46 @@ func (m *Model) Sanitization() error {
 		m.CallbackOnDestroy += "/"
 	}
 
+	if m.JoinLinkSecret == m.Token {
+		return fmt.Errorf("`join_link_secret` must not be the same as `token`")
+	}
+	if m.CalendarSecret == m.Token {
+		return fmt.Errorf("`calendar_secret` must not be the same as `token`")
+	}
+
+	names, tokens := make(map[string]bool), map[string]bool{m.Token: true, m.JoinLinkSecret: true, m.CalendarSecret: true}
+	for _, cl := range m.Clients {
+		if cl.Name == "" {
+			return fmt.Errorf("`name` field of every client is required")
+		}
+		if names[cl.Name] {
+			return fmt.Errorf("client `%s` is defined more than once", cl.Name)
+		}
+		// the separators would let IDs of different clients be the same once they're namespaced,
+		// e.g. `b-x` of client `a` and `x` of client `a-b`.
+		if m.MeetingIdNamespace != NamespaceNone && strings.ContainsAny(cl.Name, "-/") {
+			return fmt.Errorf("name of client `%s` must not have - or / when meeting IDs are namespaced", cl.Name)
+		}
+		names[cl.Name] = true
+
+		if cl.Token == "" {
+			continue
+		}
+		if tokens[cl.Token] {
+			return fmt.Errorf("token of client `%s` is already used", cl.Name)
+		}
+		tokens[cl.Token] = true
+	}
+
+	for name, tmpl := range m.Templates {
+		if name == "" {
+			return fmt.Errorf("name of every template is required")
+		}
+		if err := tmpl.Validate(); err != nil {
+			return fmt.Errorf("template `%s` is invalid: %s", name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -264,6 +264,43 @@ func TestSanitization_Port(t *testing.T) {
 	}
 }
 
+func TestSanitization_LogLevel(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sample    Model
+		expect    string
+		expectErr bool
+	}{
+		{
+			name:   "Log level w/o value should be default to info",
+			sample: Model{},
+			expect: "info",
+		},
+		{
+			name:   "Log level w debug should be debug",
+			sample: Model{LogLevel: "debug"},
+			expect: "debug",
+		},
+		{
+			name:      "Unknown log level should be rejected",
+			sample:    Model{LogLevel: "verbose"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.LogLevel)
+		})
+	}
+}
+
 func TestSanitization_RandomLen(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -296,6 +333,431 @@ func TestSanitization_RandomLen(t *testing.T) {
 	}
 }
 
+func TestSanitization_RandomEntropy(t *testing.T) {
+	testCases := []struct {
+		name           string
+		sample         Model
+		expectLen      uint8
+		expectAlphabet string
+		expectErr      bool
+	}{
+		{
+			name:           "Random alphabet w/o value should be default to letters",
+			sample:         Model{RandomLen: 16},
+			expectLen:      16,
+			expectAlphabet: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ",
+		},
+		{
+			name:           "Random entropy of 128 bits in hex should be 32 characters",
+			sample:         Model{RandomLen: 8, RandomAlphabet: "0123456789abcdef", RandomEntropy: 128},
+			expectLen:      32,
+			expectAlphabet: "0123456789abcdef",
+		},
+		{
+			name:      "Random alphabet that must be escaped in url should be rejected",
+			sample:    Model{RandomAlphabet: "ab/"},
+			expectErr: true,
+		},
+		{
+			name:      "Random entropy that needs more than 255 characters should be rejected",
+			sample:    Model{RandomAlphabet: "01", RandomEntropy: 256},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectLen, tt.sample.RandomLen)
+			assert.Equal(t, tt.expectAlphabet, tt.sample.RandomAlphabet)
+		})
+	}
+}
+
+func TestSanitization_MeetingId(t *testing.T) {
+	testCases := []struct {
+		name            string
+		sample          Model
+		expectFormat    string
+		expectNamespace string
+		expectErr       bool
+	}{
+		{
+			name:            "Meeting ID w/o format nor namespace should be default to letters w/o namespace",
+			sample:          Model{},
+			expectFormat:    "letters",
+			expectNamespace: "none",
+		},
+		{
+			name:            "Meeting ID w ulid format and hash namespace should be kept",
+			sample:          Model{MeetingIdFormat: "ulid", MeetingIdNamespace: "hash"},
+			expectFormat:    "ulid",
+			expectNamespace: "hash",
+		},
+		{
+			name:      "Unknown meeting ID format should be rejected",
+			sample:    Model{MeetingIdFormat: "snowflake"},
+			expectErr: true,
+		},
+		{
+			name:      "Unknown meeting ID namespace should be rejected",
+			sample:    Model{MeetingIdNamespace: "suffix"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectFormat, tt.sample.MeetingIdFormat)
+			assert.Equal(t, tt.expectNamespace, tt.sample.MeetingIdNamespace)
+		})
+	}
+}
+
+func TestSanitization_PollInterval(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect uint16
+	}{
+		{
+			name:   "Poll interval w 30 should be 30",
+			sample: Model{PollInterval: 30},
+			expect: 30,
+		},
+		{
+			name:   "Poll interval w/o value should be default to 10",
+			sample: Model{},
+			expect: 10,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.PollInterval)
+		})
+	}
+}
+
+func TestSanitization_CapacityInterval(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect uint16
+	}{
+		{
+			name:   "Capacity interval w 60 should be 60",
+			sample: Model{CapacityInterval: 60},
+			expect: 60,
+		},
+		{
+			name:   "Capacity interval w/o value should be default to 30",
+			sample: Model{},
+			expect: 30,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.CapacityInterval)
+		})
+	}
+}
+
+func TestSanitization_SeriesHorizon(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect uint16
+	}{
+		{
+			name:   "Series horizon w 30 should be 30",
+			sample: Model{SeriesHorizon: 30},
+			expect: 30,
+		},
+		{
+			name:   "Series horizon w/o value should be default to 14",
+			sample: Model{},
+			expect: 14,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.SeriesHorizon)
+		})
+	}
+}
+
+func TestSanitization_JoinLinkTTL(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect uint32
+	}{
+		{
+			name:   "Join link TTL w 3600 should be 3600",
+			sample: Model{JoinLinkTTL: 3600},
+			expect: 3600,
+		},
+		{
+			name:   "Join link TTL w/o value should be default to 300",
+			sample: Model{},
+			expect: 300,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.JoinLinkTTL)
+		})
+	}
+}
+
+func TestSanitization_JoinLinkMaxTTL(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect uint32
+		isErr  bool
+	}{
+		{
+			name:   "Join link max TTL w 3600 should be 3600",
+			sample: Model{JoinLinkMaxTTL: 3600},
+			expect: 3600,
+		},
+		{
+			name:   "Join link max TTL w/o value should be default to 86400",
+			sample: Model{},
+			expect: 86400,
+		},
+		{
+			name:   "Error if join link TTL is more than the max",
+			sample: Model{JoinLinkTTL: 600, JoinLinkMaxTTL: 300},
+			isErr:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.JoinLinkMaxTTL)
+		})
+	}
+}
+
+func TestSanitization_JoinLinkSecret(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		isErr  bool
+	}{
+		{
+			name:   "Pass w a secret of its own",
+			sample: Model{Token: "t", JoinLinkSecret: "s"},
+		},
+		{
+			name:   "Pass w/o secret, which is generated",
+			sample: Model{Token: "t"},
+		},
+		{
+			name:   "Error if the secret is the same as the main token",
+			sample: Model{Token: "t", JoinLinkSecret: "t"},
+			isErr:  true,
+		},
+		{
+			name:   "Error if the secret is the same as a client's token",
+			sample: Model{Token: "t", JoinLinkSecret: "s", Clients: []Client{{Name: "lms", Token: "s"}}},
+			isErr:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, tt.sample.JoinLinkSecret)
+			assert.NotEqual(t, tt.sample.Token, tt.sample.JoinLinkSecret)
+		})
+	}
+}
+
+func TestSanitization_CalendarSecret(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		isErr  bool
+	}{
+		{
+			name:   "Pass w a secret of its own",
+			sample: Model{Token: "t", CalendarSecret: "c"},
+		},
+		{
+			name:   "Pass w/o secret, which is generated",
+			sample: Model{Token: "t"},
+		},
+		{
+			name:   "Error if the secret is the same as the main token",
+			sample: Model{Token: "t", CalendarSecret: "t"},
+			isErr:  true,
+		},
+		{
+			name:   "Error if the secret is the same as a client's token",
+			sample: Model{Token: "t", CalendarSecret: "c", Clients: []Client{{Name: "lms", Token: "c"}}},
+			isErr:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, tt.sample.CalendarSecret)
+			assert.NotEqual(t, tt.sample.Token, tt.sample.CalendarSecret)
+		})
+	}
+}
+
+func TestSanitization_LobbyRefresh(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect uint16
+	}{
+		{
+			name:   "Lobby refresh w 10 should be 10",
+			sample: Model{LobbyRefresh: 10},
+			expect: 10,
+		},
+		{
+			name:   "Lobby refresh w/o value should be default to 5",
+			sample: Model{},
+			expect: 5,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.LobbyRefresh)
+		})
+	}
+}
+
+func TestSanitization_IdempotencyTTL(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect uint32
+	}{
+		{
+			name:   "Idempotency TTL w 3600 should be 3600",
+			sample: Model{IdempotencyTTL: 3600},
+			expect: 3600,
+		},
+		{
+			name:   "Idempotency TTL w/o value should be default to a day",
+			sample: Model{},
+			expect: 86400,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.IdempotencyTTL)
+		})
+	}
+}
+
+func TestSanitization_DBPath(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect string
+	}{
+		{
+			name:   "DB path w /var/lib/bbb.db should be /var/lib/bbb.db",
+			sample: Model{DBPath: "/var/lib/bbb.db"},
+			expect: "/var/lib/bbb.db",
+		},
+		{
+			name:   "DB path w/o value should be default to ./bbb-interface.db",
+			sample: Model{},
+			expect: "./bbb-interface.db",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.DBPath)
+		})
+	}
+}
+
+func TestSanitization_PublicUrl(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		expect string
+	}{
+		{
+			name:   "Public url w trailing slash should be trimmed",
+			sample: Model{PublicUrl: "https://meet.example/"},
+			expect: "https://meet.example",
+		},
+		{
+			name:   "Public url w/o value should be default to this app's host and port",
+			sample: Model{},
+			expect: "http://localhost:6767",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.sample.PublicUrl)
+		})
+	}
+}
+
 func TestSanitization_CallbackOnDestroy(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -380,3 +842,146 @@ func TestSanitizationLog(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitization_Clients(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Model
+		isErr  bool
+	}{
+		{
+			name:   "Pass w/o any client",
+			sample: Model{},
+		},
+		{
+			name:   "Pass w clients that have unique name",
+			sample: Model{Clients: []Client{{Name: "lms"}, {Name: "dashboard"}}},
+		},
+		{
+			name:   "Error if client's name is empty",
+			sample: Model{Clients: []Client{{CallbackOnEvent: "http://localhost"}}},
+			isErr:  true,
+		},
+		{
+			name:   "Error if client's name is duplicated",
+			sample: Model{Clients: []Client{{Name: "lms"}, {Name: "lms"}}},
+			isErr:  true,
+		},
+		{
+			name:   "Error if client's token is used by another client",
+			sample: Model{Clients: []Client{{Name: "lms", Token: "t"}, {Name: "dashboard", Token: "t"}}},
+			isErr:  true,
+		},
+		{
+			name:   "Error if client's token is the same as the main token",
+			sample: Model{Token: "t", Clients: []Client{{Name: "lms", Token: "t"}}},
+			isErr:  true,
+		},
+		{
+			name:   "Pass w - in client's name if meeting IDs are not namespaced",
+			sample: Model{Clients: []Client{{Name: "lms-1"}}},
+		},
+		{
+			name:   "Error if client's name has - and meeting IDs are prefixed",
+			sample: Model{MeetingIdNamespace: NamespacePrefix, Clients: []Client{{Name: "lms-1"}}},
+			isErr:  true,
+		},
+		{
+			name:   "Error if client's name has / and meeting IDs are hashed",
+			sample: Model{MeetingIdNamespace: NamespaceHash, Clients: []Client{{Name: "lms/1"}}},
+			isErr:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Sanitization()
+
+			switch tt.isErr {
+			case true:
+				require.Error(t, err)
+			case false:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSanitization_Templates(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample string
+		isErr  bool
+	}{
+		{
+			name: "Pass w valid templates",
+			sample: `
+templates:
+  exam:
+    is_recording: true
+    mute_on_start: true
+    max_participant: 40
+    lock_settings:
+      disable_cam: true
+      disable_private_chat: true
+`,
+		},
+		{
+			name: "Error if template has unknown field",
+			sample: `
+templates:
+  exam:
+    mute_on_join: true
+`,
+			isErr: true,
+		},
+		{
+			name: "Error if template field has invalid value",
+			sample: `
+templates:
+  exam:
+    max_participant: 1000
+`,
+			isErr: true,
+		},
+		{
+			name: "Error if template has password",
+			sample: `
+templates:
+  exam:
+    moderator_pass: secret
+`,
+			isErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, err := NewConfig(bytes.NewBufferString(tt.sample))
+			require.NoError(t, err)
+			err = mod.Sanitization()
+
+			switch tt.isErr {
+			case true:
+				require.Error(t, err)
+			case false:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClientByToken(t *testing.T) {
+	sample := Model{Clients: []Client{{Name: "lms", Token: "lmsToken"}, {Name: "dashboard"}}}
+
+	t.Run("Should return the client that use the token", func(t *testing.T) {
+		cl, ok := sample.ClientByToken("lmsToken")
+		require.True(t, ok)
+		assert.Equal(t, "lms", cl.Name)
+	})
+
+	t.Run("Empty token should not match client without token", func(t *testing.T) {
+		_, ok := sample.ClientByToken("")
+		assert.False(t, ok)
+	})
+}
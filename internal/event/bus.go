@@ -0,0 +1,181 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// Filter decide whether an event should be delivered to a subscriber. Nil
+// Filter means every event would be delivered.
+type Filter func(Event) bool
+
+// subscription holds a subscriber channel along with its filter.
+type subscription struct {
+	ch     chan Event
+	filter Filter
+}
+
+// Bus in-process event bus that fan out every published event to all of
+// its matching subscribers. Bus also keep track the state of every meeting
+// it heard of, so the same lifecycle event that came from different sources
+// would only be delivered once.
+type Bus struct {
+	mu       sync.RWMutex
+	subs     map[*subscription]struct{}
+	meetings map[string]*meetingState
+	owner    func(meetingId string) string
+	closed   bool
+}
+
+// NewBus return new empty event bus.
+func NewBus() *Bus {
+	return &Bus{
+		subs:     make(map[*subscription]struct{}),
+		meetings: make(map[string]*meetingState),
+	}
+}
+
+// ResolveOwner set fn to look up the client that owns a meeting the bus doesn't know
+// of yet, e.g. meetings that were created before this app restarted.
+func (b *Bus) ResolveOwner(fn func(meetingId string) string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.owner = fn
+}
+
+// Subscribe register new subscriber that only receive events that match the
+// given filter. The returned function must be called to unsubscribe which
+// would also close the returned channel.
+func (b *Bus) Subscribe(size int, f Filter) (<-chan Event, func()) {
+	sub := &subscription{ch: make(chan Event, size), filter: f}
+
+	b.mu.Lock()
+	if b.closed {
+		close(sub.ch)
+	} else {
+		b.subs[sub] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Publish deliver the given event to every matching subscriber. Slow
+// subscribers whose buffer is full would miss the event instead of blocking
+// the publisher.
+func (b *Bus) Publish(e Event) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	events := b.track(e)
+	b.mu.Unlock()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, e := range events {
+		b.deliver(e)
+	}
+}
+
+// Observe record the running state of a meeting that was learned by polling
+// BBB API, then publish meeting-started or meeting-ended if it has changed.
+func (b *Bus) Observe(meetingId string, running bool) {
+	b.mu.RLock()
+	st, ok := b.meetings[meetingId]
+	changed := (ok && st.running != running && !st.ended) || (!ok && running)
+	b.mu.RUnlock()
+
+	if !changed {
+		return
+	}
+
+	typ := MeetingEnded
+	if running {
+		typ = MeetingStarted
+	}
+	b.Publish(Event{Type: typ, MeetingId: meetingId})
+}
+
+// Close unsubscribe every subscriber so all of them would stop receiving events.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+	b.closed = true
+}
+
+// deliver send the given event to every matching subscriber. Must be
+// called while holding the read lock.
+func (b *Bus) deliver(e Event) {
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(e) {
+			continue
+		}
+
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// ByMeeting filter that only match events of the given meeting id.
+func ByMeeting(id string) Filter {
+	return func(e Event) bool {
+		return e.MeetingId == id
+	}
+}
+
+// ByClient filter that only match events of meetings that belong to the given client.
+func ByClient(name string) Filter {
+	return func(e Event) bool {
+		return e.Client == name
+	}
+}
+
+// ByTypes filter that only match events with one of the given types. Empty
+// types would match every event.
+func ByTypes(types ...string) Filter {
+	return func(e Event) bool {
+		if len(types) == 0 {
+			return true
+		}
+
+		for _, t := range types {
+			if e.Type == t {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// All combine filters and only match events that match every given filter.
+func All(filters ...Filter) Filter {
+	return func(e Event) bool {
+		for _, f := range filters {
+			if f != nil && !f(e) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,226 @@
+package event
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+
+	all, unsubAll := bus.Subscribe(4, nil)
+	meet, unsubMeet := bus.Subscribe(4, ByMeeting("meet01"))
+	joined, unsubJoined := bus.Subscribe(4, All(ByMeeting("meet01"), ByTypes(UserLeft)))
+
+	bus.Publish(Event{Type: MeetingCreated, MeetingId: "meet01"})
+	bus.Publish(Event{Type: UserLeft, MeetingId: "meet01"})
+	bus.Publish(Event{Type: UserLeft, MeetingId: "meet02"})
+
+	t.Run("Subscriber without filter should receive every event", func(t *testing.T) {
+		assert.Len(t, all, 3)
+	})
+
+	t.Run("Subscriber should only receive events of the filtered meeting", func(t *testing.T) {
+		require.Len(t, meet, 2)
+		assert.Equal(t, MeetingCreated, (<-meet).Type)
+		assert.Equal(t, UserLeft, (<-meet).Type)
+	})
+
+	t.Run("Combined filters should match every given filter", func(t *testing.T) {
+		require.Len(t, joined, 1)
+		e := <-joined
+		assert.Equal(t, "meet01", e.MeetingId)
+		assert.Equal(t, UserLeft, e.Type)
+	})
+
+	t.Run("Unsubscribe should close the channel and can be called twice", func(t *testing.T) {
+		unsubAll()
+		unsubAll()
+		unsubMeet()
+		unsubJoined()
+
+		for range all {
+		}
+		_, ok := <-all
+		assert.False(t, ok)
+	})
+
+	t.Run("Publish should not block when subscriber buffer is full", func(t *testing.T) {
+		_, unsub := bus.Subscribe(1, nil)
+		defer unsub()
+
+		bus.Publish(Event{Type: UserLeft})
+		bus.Publish(Event{Type: UserLeft})
+	})
+}
+
+func TestForward(t *testing.T) {
+	received := make(chan Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "req-1", req.Header.Get("X-Request-ID"))
+
+		var e Event
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&e))
+		received <- e
+	}))
+	defer server.Close()
+
+	bus := NewBus()
+	stop := Forward(bus, server.Client(), server.URL, ByTypes(MeetingEnded), nil)
+	defer stop()
+
+	bus.Publish(Event{Type: UserJoined, MeetingId: "meet01"})
+	bus.Publish(Event{Type: MeetingEnded, MeetingId: "meet01", RequestId: "req-1"})
+
+	select {
+	case e := <-received:
+		assert.Equal(t, MeetingEnded, e.Type)
+		assert.Equal(t, "meet01", e.MeetingId)
+		assert.Equal(t, "req-1", e.RequestId)
+	case <-time.After(time.Second):
+		t.Fatal("event was not forwarded")
+	}
+}
+
+func TestBus_MeetingState(t *testing.T) {
+	bus := NewBus()
+	ch, unsub := bus.Subscribe(16, nil)
+	defer unsub()
+
+	// collect every delivered event types so far.
+	types := func() (out []string) {
+		for len(ch) > 0 {
+			out = append(out, (<-ch).Type)
+		}
+		return
+	}
+
+	t.Run("Duplicated meeting-created should only be delivered once", func(t *testing.T) {
+		bus.Publish(Event{Type: MeetingCreated, MeetingId: "meet01", Client: "lms"})
+		bus.Publish(Event{Type: MeetingCreated, MeetingId: "meet01"})
+		assert.Equal(t, []string{MeetingCreated}, types())
+	})
+
+	t.Run("First user joined should also deliver meeting-started", func(t *testing.T) {
+		bus.Publish(Event{Type: UserJoined, MeetingId: "meet01"})
+		bus.Publish(Event{Type: UserJoined, MeetingId: "meet01"})
+		assert.Equal(t, []string{MeetingStarted, UserJoined, UserJoined}, types())
+	})
+
+	t.Run("Events from BBB should be attributed to the client that created the meeting", func(t *testing.T) {
+		bus.Publish(Event{Type: UserLeft, MeetingId: "meet01"})
+		e := <-ch
+		assert.Equal(t, "lms", e.Client)
+	})
+
+	t.Run("Observing the same running state should not deliver anything", func(t *testing.T) {
+		bus.Observe("meet01", true)
+		assert.Empty(t, types())
+	})
+
+	t.Run("Observing meeting is not running anymore should deliver meeting-ended once", func(t *testing.T) {
+		bus.Observe("meet01", false)
+		bus.Publish(Event{Type: MeetingEnded, MeetingId: "meet01"})
+		assert.Equal(t, []string{MeetingEnded}, types())
+	})
+
+	t.Run("Observing unknown meeting is running should deliver meeting-started", func(t *testing.T) {
+		bus.Observe("meet02", false)
+		bus.Observe("meet02", true)
+		assert.Equal(t, []string{MeetingStarted}, types())
+	})
+
+	t.Run("Close should close every subscriber channel", func(t *testing.T) {
+		bus.Close()
+		_, ok := <-ch
+		assert.False(t, ok)
+
+		late, _ := bus.Subscribe(1, nil)
+		_, ok = <-late
+		assert.False(t, ok)
+	})
+}
+
+func TestBus_Roster(t *testing.T) {
+	bus := NewBus()
+	ch, unsub := bus.Subscribe(16, ByTypes(UserJoined, UserLeft, PresenterAssigned, UserMuted))
+	defer unsub()
+
+	usr01 := &User{Id: "usr01", Name: "B"}
+	usr02 := &User{Id: "usr02", Name: "A"}
+
+	bus.Publish(Event{Type: UserJoined, MeetingId: "meet01", User: usr01})
+	bus.Publish(Event{Type: UserJoined, MeetingId: "meet01", User: usr01})
+	bus.Publish(Event{Type: UserJoined, MeetingId: "meet01", User: usr02})
+	bus.Publish(Event{Type: PresenterAssigned, MeetingId: "meet01", User: usr01})
+	bus.Publish(Event{Type: PresenterAssigned, MeetingId: "meet01", User: usr01})
+	bus.Publish(Event{Type: UserMuted, MeetingId: "meet01", User: usr02})
+	bus.Publish(Event{Type: UserLeft, MeetingId: "meet01", User: &User{Id: "usr03"}})
+
+	t.Run("Duplicated roster changes should only be delivered once", func(t *testing.T) {
+		assert.Len(t, ch, 4)
+	})
+
+	t.Run("Roster should reflect every delivered change sorted by name", func(t *testing.T) {
+		roster := bus.Roster("meet01")
+		require.Len(t, roster, 2)
+		assert.Equal(t, "usr02", roster[0].Id)
+		assert.True(t, roster[0].Muted)
+		assert.Equal(t, "usr01", roster[1].Id)
+		assert.True(t, roster[1].Presenter)
+	})
+
+	t.Run("Roster should be empty after user left and meeting ended", func(t *testing.T) {
+		bus.Publish(Event{Type: UserLeft, MeetingId: "meet01", User: usr01})
+		assert.Len(t, bus.Roster("meet01"), 1)
+
+		bus.Publish(Event{Type: MeetingEnded, MeetingId: "meet01"})
+		assert.Empty(t, bus.Roster("meet01"))
+		assert.Empty(t, bus.Roster("unknown"))
+	})
+}
+
+func TestBus_ResolveOwner(t *testing.T) {
+	bus := NewBus()
+	bus.ResolveOwner(func(meetingId string) string {
+		if meetingId == "meet01" {
+			return "lms"
+		}
+		return ""
+	})
+	ch, unsub := bus.Subscribe(16, nil)
+	defer unsub()
+
+	testCases := []struct {
+		name   string
+		sample Event
+		expect string
+	}{
+		{
+			name:   "Event of unknown meeting should be attributed to the resolved owner",
+			sample: Event{Type: UserJoined, MeetingId: "meet01"},
+			expect: "lms",
+		},
+		{
+			name:   "Event of meeting w/o owner should not be attributed to any client",
+			sample: Event{Type: UserJoined, MeetingId: "meet02"},
+			expect: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			bus.Publish(tt.sample)
+			for len(ch) > 0 {
+				assert.Equal(t, tt.expect, (<-ch).Client)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package event
+
+import "time"
+
+// Normalized event types that would be published to the bus. Event types from
+// bbb-webhooks that are not listed here keep their original id.
+const (
+	MeetingCreated     = "meeting-created"     // A meeting was created in BBB server.
+	MeetingStarted     = "meeting-started"     // A meeting started running because someone joined.
+	MeetingEnded       = "meeting-ended"       // A meeting was ended or destroyed.
+	UserJoined         = "user-joined"         // A user joined a meeting.
+	UserLeft           = "user-left"           // A user left a meeting.
+	PresenterAssigned  = "presenter-assigned"  // A user became the presenter.
+	PresenterRemoved   = "presenter-removed"   // A user is no longer the presenter.
+	UserMuted          = "user-muted"          // A user muted the microphone.
+	UserUnmuted        = "user-unmuted"        // A user unmuted the microphone.
+	ChatMessage        = "chat-message"        // A message was sent to a chat.
+	RecordingStarted   = "recording-started"   // Recording of a meeting was started.
+	RecordingStopped   = "recording-stopped"   // Recording of a meeting was stopped.
+	RecordingProcessed = "recording-processed" // Recording of a meeting was archived and processed.
+	RecordingReady     = "recording-ready"     // Recording of a meeting was published and ready to watch.
+	AnalyticsReady     = "analytics-ready"     // Learning dashboard data of a meeting was received.
+	GuestWaiting       = "guest-waiting"       // A guest asked to join a meeting and is waiting for approval.
+	GuestApproved      = "guest-approved"      // A waiting guest was approved to join.
+	GuestDenied        = "guest-denied"        // A waiting guest was denied.
+)
+
+// Event normalized event that hold what happened to which meeting.
+type Event struct {
+	Type              string    `json:"type"`                          // One of the normalized event types.
+	MeetingId         string    `json:"meeting_id"`                    // The meeting ID that was given when creating the meeting.
+	InternalMeetingId string    `json:"internal_meeting_id,omitempty"` // The meeting ID that was generated by BBB server.
+	Client            string    `json:"client,omitempty"`              // Name of the client that owns the meeting if known.
+	User              *User     `json:"user,omitempty"`                // The user that triggered this event if any.
+	Message           string    `json:"message,omitempty"`             // Chat message or any other text that came with this event.
+	RecordId          string    `json:"record_id,omitempty"`           // The recording ID for recording-related events.
+	Timestamp         time.Time `json:"timestamp"`                     // When this event happened.
+	RequestId         string    `json:"request_id,omitempty"`          // ID of the request to this app that caused this event if any.
+}
+
+// User holds data of a user that triggered an event.
+type User struct {
+	Id         string `json:"user_id"`            // The user ID that was given when joining the meeting.
+	InternalId string `json:"internal_user_id"`   // The user ID that was generated by BBB server.
+	Name       string `json:"name"`               // The full name of the user.
+	Role       string `json:"role"`               // Either MODERATOR or VIEWER.
+	Presenter  bool   `json:"presenter"`          // Whether this user is the presenter.
+	Muted      bool   `json:"muted"`              // Whether this user's microphone is muted.
+	IsGuest    bool   `json:"is_guest,omitempty"` // Whether this user joined as guest.
+}
@@ -0,0 +1,76 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kurvaid/bbb-interface/internal/logger"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+)
+
+// Forward subscribe to the bus and send every matching event as json POST
+// request to the given url until the returned function is called. Failed
+// deliveries would be reported to onErr if not nil.
+func Forward(bus *Bus, hCl *http.Client, url string, f Filter, onErr func(error)) func() {
+	ch, unsubscribe := bus.Subscribe(64, f)
+
+	go func() {
+		for e := range ch {
+			if err := send(hCl, url, e); err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+	}()
+
+	return unsubscribe
+}
+
+// send POST the given event as json to the given url, and record the delivery to the metrics.
+func send(hCl *http.Client, url string, e Event) error {
+	err := post(hCl, url, e)
+	switch {
+	case err == nil:
+		metrics.ObserveCallback(metrics.CallbackEvent, metrics.ResultSuccess)
+	case errors.Is(err, errRejected):
+		metrics.ObserveCallback(metrics.CallbackEvent, metrics.ResultFailed)
+	default:
+		metrics.ObserveCallback(metrics.CallbackEvent, metrics.ResultError)
+	}
+
+	return err
+}
+
+// errRejected returned when the endpoint responded the event with error status code.
+var errRejected = errors.New("event is rejected")
+
+func post(hCl *http.Client, url string, e Event) error {
+	payload, err := json.Marshal(&e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event to json: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request of %s event: %s", e.Type, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// so clients could trace the event back to the request that caused it.
+	if e.RequestId != "" {
+		req.Header.Set(logger.RequestIdHeader, e.RequestId)
+	}
+
+	res, err := hCl.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s event to %s: %s", e.Type, url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s responded %s event with status %d: %w", url, e.Type, res.StatusCode, errRejected)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package event
+
+import (
+	"sort"
+	"time"
+)
+
+// endedRetention how long an ended meeting is remembered so duplicated
+// meeting-ended events from different sources could be dropped.
+const endedRetention = time.Hour
+
+// meetingState holds what the bus know about a meeting.
+type meetingState struct {
+	client  string
+	created bool
+	running bool
+	ended   bool
+	endedAt time.Time
+	users   map[string]*User // Users that are currently in the meeting.
+}
+
+// track update the state of the meeting of the given event and return the
+// events that should be delivered because of it. Must be called while
+// holding the write lock.
+func (b *Bus) track(e Event) []Event {
+	b.forgetEnded()
+
+	if e.MeetingId == "" {
+		return []Event{e}
+	}
+
+	st, ok := b.meetings[e.MeetingId]
+	if !ok {
+		st = &meetingState{}
+		if b.owner != nil {
+			st.client = b.owner(e.MeetingId)
+		}
+		b.meetings[e.MeetingId] = st
+	}
+
+	// remember which client owns the meeting, so events that came from BBB
+	// server could also be filtered by client.
+	switch {
+	case e.Client == "":
+		e.Client = st.client
+	case st.client == "":
+		st.client = e.Client
+	}
+
+	switch e.Type {
+	case MeetingCreated:
+		if st.created && !st.ended {
+			return nil
+		}
+		*st = meetingState{client: st.client, created: true}
+	case MeetingStarted:
+		if st.running {
+			return nil
+		}
+		st.running, st.ended = true, false
+	case UserJoined:
+		if e.User != nil {
+			if st.users[userKey(e.User)] != nil {
+				return nil
+			}
+			if st.users == nil {
+				st.users = make(map[string]*User)
+			}
+			u := *e.User
+			st.users[userKey(e.User)] = &u
+		}
+
+		if !st.running {
+			st.running, st.ended = true, false
+			started := Event{
+				Type:              MeetingStarted,
+				MeetingId:         e.MeetingId,
+				InternalMeetingId: e.InternalMeetingId,
+				Client:            e.Client,
+				Timestamp:         e.Timestamp,
+			}
+			return []Event{started, e}
+		}
+	case UserLeft:
+		// users that are not known to be in the meeting can't leave.
+		if e.User != nil {
+			if st.users[userKey(e.User)] == nil {
+				return nil
+			}
+			delete(st.users, userKey(e.User))
+		}
+	case PresenterAssigned, PresenterRemoved:
+		if u := st.user(e.User); u != nil {
+			if u.Presenter == (e.Type == PresenterAssigned) {
+				return nil
+			}
+			u.Presenter = e.Type == PresenterAssigned
+		}
+	case UserMuted, UserUnmuted:
+		if u := st.user(e.User); u != nil {
+			if u.Muted == (e.Type == UserMuted) {
+				return nil
+			}
+			u.Muted = e.Type == UserMuted
+		}
+	case MeetingEnded:
+		if st.ended {
+			return nil
+		}
+		st.running, st.ended, st.endedAt = false, true, time.Now()
+		st.users = nil
+	}
+
+	return []Event{e}
+}
+
+// Roster return users that are currently in the given meeting as far as the bus
+// know, sorted by their name.
+func (b *Bus) Roster(meetingId string) []User {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	users := make([]User, 0)
+	if st, ok := b.meetings[meetingId]; ok {
+		for _, u := range st.users {
+			users = append(users, *u)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+
+	return users
+}
+
+// user return the given user if it's currently in the meeting.
+func (st *meetingState) user(u *User) *User {
+	if u == nil {
+		return nil
+	}
+
+	return st.users[userKey(u)]
+}
+
+// userKey return the key to identify the given user in a meeting, which is the user
+// ID that was given when joining or the one generated by BBB server if none.
+func userKey(u *User) string {
+	if u.Id != "" {
+		return u.Id
+	}
+
+	return u.InternalId
+}
+
+// forgetEnded remove meetings that have ended longer than the retention.
+func (b *Bus) forgetEnded() {
+	for id, st := range b.meetings {
+		if st.ended && time.Since(st.endedAt) > endedRetention {
+			delete(b.meetings, id)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+)
+
+// webhookTypes map event ids from bbb-webhooks to the normalized event types.
+var webhookTypes = map[string]string{
+	"meeting-created":           MeetingCreated,
+	"meeting-ended":             MeetingEnded,
+	"user-joined":               UserJoined,
+	"user-left":                 UserLeft,
+	"user-presenter-assigned":   PresenterAssigned,
+	"user-presenter-unassigned": PresenterRemoved,
+	"user-audio-muted":          UserMuted,
+	"user-audio-unmuted":        UserUnmuted,
+	"chat-group-message-sent":   ChatMessage,
+	"meeting-recording-started": RecordingStarted,
+	"meeting-recording-stopped": RecordingStopped,
+	"rap-process-ended":         RecordingProcessed,
+	"rap-publish-ended":         RecordingReady,
+}
+
+// FromWebhook normalize the given event from bbb-webhooks.
+func FromWebhook(w api.WebhookEvent) Event {
+	typ, ok := webhookTypes[w.Data.Id]
+	if !ok {
+		typ = w.Data.Id
+	}
+
+	attr := w.Data.Attributes
+	e := Event{
+		Type:              typ,
+		MeetingId:         attr.Meeting.ExternalMeetingId,
+		InternalMeetingId: attr.Meeting.InternalMeetingId,
+		RecordId:          attr.RecordId,
+		Timestamp:         time.Now(),
+	}
+	if w.Data.Event.Ts > 0 {
+		e.Timestamp = time.Unix(0, w.Data.Event.Ts*int64(time.Millisecond))
+	}
+
+	if attr.User != nil {
+		e.User = &User{
+			Id:         attr.User.ExternalUserId,
+			InternalId: attr.User.InternalUserId,
+			Name:       attr.User.Name,
+			Role:       attr.User.Role,
+			Presenter:  attr.User.Presenter,
+			Muted:      attr.User.Muted,
+			IsGuest:    attr.User.Guest,
+		}
+	}
+
+	if attr.ChatMessage != nil {
+		e.Message = attr.ChatMessage.Message
+		if e.User == nil {
+			e.User = &User{
+				InternalId: attr.ChatMessage.Sender.InternalUserId,
+				Name:       attr.ChatMessage.Sender.Name,
+			}
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,45 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromWebhook(t *testing.T) {
+	payload := api.WebhookPayload{Event: `[
+{"data":{"id":"user-presenter-assigned","attributes":{"meeting":{"internal-meeting-id":"int01","external-meeting-id":"meet01"},"user":{"internal-user-id":"w_1","external-user-id":"usr01","name":"NzK","role":"MODERATOR","presenter":true}},"event":{"ts":1502810164922}}},
+{"data":{"id":"chat-group-message-sent","attributes":{"meeting":{"external-meeting-id":"meet01"},"chat-message":{"message":"hello","sender":{"internal-user-id":"w_1","name":"NzK"}}},"event":{"ts":1}}},
+{"data":{"id":"user-emoji-changed","attributes":{"meeting":{"external-meeting-id":"meet01"}}}}
+]`}
+	events, err := payload.ParseEvents()
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	t.Run("Known event id should be normalized", func(t *testing.T) {
+		e := FromWebhook(events[0])
+		assert.Equal(t, PresenterAssigned, e.Type)
+		assert.Equal(t, "meet01", e.MeetingId)
+		assert.Equal(t, int64(1502810164922), e.Timestamp.UnixNano()/1e6)
+		require.NotNil(t, e.User)
+		assert.Equal(t, "usr01", e.User.Id)
+		assert.True(t, e.User.Presenter)
+	})
+
+	t.Run("Chat message should use the sender as user", func(t *testing.T) {
+		e := FromWebhook(events[1])
+		assert.Equal(t, ChatMessage, e.Type)
+		assert.Equal(t, "hello", e.Message)
+		require.NotNil(t, e.User)
+		assert.Equal(t, "w_1", e.User.InternalId)
+	})
+
+	t.Run("Unknown event id should be kept as is", func(t *testing.T) {
+		e := FromWebhook(events[2])
+		assert.Equal(t, "user-emoji-changed", e.Type)
+		assert.Nil(t, e.User)
+		assert.False(t, e.Timestamp.IsZero())
+	})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// Analytics handler that send the learning dashboard data of the latest meeting with ID in `id`
+// param. Requester that authenticated as a client would only get data of its own meetings.
+func Analytics(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		meet, err := clientMeeting(c, st, c.Params("id"))
+		if err == store.ErrNotFound {
+			c.Status(fiber.StatusNotFound)
+			return c.JSON(fiber.Map{
+				"message": "meeting is not found",
+			})
+		}
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get meeting: %s", err),
+			})
+		}
+
+		analytics, err := st.Analytics(meet.Key)
+		if err == store.ErrNotFound {
+			c.Status(fiber.StatusNotFound)
+			return c.JSON(fiber.Map{
+				"message": "analytics of the meeting is not received yet",
+			})
+		}
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get analytics: %s", err),
+			})
+		}
+
+		return c.JSON(analytics)
+	}
+}
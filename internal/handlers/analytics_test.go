@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalytics(t *testing.T) {
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Client: "lms", CreateTime: 1000}))
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet02", Client: "lms", CreateTime: 1000}))
+	require.NoError(t, st.SaveAnalytics("meet01", api.AnalyticsCallback{MeetingId: "meet01", Data: api.AnalyticsData{Duration: 3600}}))
+
+	app := fiber.New()
+	app.Get("/meetings/:id/analytics", Analytics(st))
+	app.Get("/hr/meetings/:id/analytics", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "hr")
+		return c.Next()
+	}, Analytics(st))
+
+	testCases := []struct {
+		name   string
+		uri    string
+		status int
+	}{
+		{name: "Should return the saved analytics", uri: "/meetings/meet01/analytics", status: fiber.StatusOK},
+		{name: "Meeting w/o analytics should not be found", uri: "/meetings/meet02/analytics", status: fiber.StatusNotFound},
+		{name: "Unknown meeting should not be found", uri: "/meetings/unknown/analytics", status: fiber.StatusNotFound},
+		{name: "Meeting of other client should not be found", uri: "/hr/meetings/meet01/analytics", status: fiber.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.uri, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.status != fiber.StatusOK {
+				return
+			}
+
+			var a store.Analytics
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&a))
+			assert.Equal(t, int64(3600), a.Data.Data.Duration)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/logger"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// AttendanceEntry attendance of a user in the attendance report.
+type AttendanceEntry struct {
+	UserId     string          `json:"user_id"`
+	Name       string          `json:"name"`
+	Role       string          `json:"role"`
+	FirstJoin  time.Time       `json:"first_join"`
+	LastLeave  *time.Time      `json:"last_leave,omitempty"` // Omitted if the user is still in the meeting.
+	TotalTime  int64           `json:"total_time"`           // In seconds.
+	Reconnects int             `json:"reconnects"`
+	Present    bool            `json:"present"`
+	Sessions   []store.Session `json:"sessions"`
+}
+
+// AttendanceReport attendance of every user in a meeting.
+type AttendanceReport struct {
+	MeetingId string            `json:"meeting_id"`
+	Name      string            `json:"name"`
+	StartedAt *time.Time        `json:"started_at,omitempty"`
+	EndedAt   *time.Time        `json:"ended_at,omitempty"`
+	Attendees []AttendanceEntry `json:"attendees"`
+}
+
+// Attendance handler that send the attendance report of the latest meeting with ID in `id` param,
+// as json or as csv if `format` query is csv. Requester that authenticated as a client would only
+// get report of its own meetings.
+func Attendance(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		format := c.Query("format", "json")
+		if format != "json" && format != "csv" {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": "`format` must be either json or csv",
+			})
+		}
+
+		meet, err := clientMeeting(c, st, c.Params("id"))
+		if err == store.ErrNotFound {
+			c.Status(fiber.StatusNotFound)
+			return c.JSON(fiber.Map{
+				"message": "meeting is not found",
+			})
+		}
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get meeting: %s", err),
+			})
+		}
+
+		atts, err := st.Attendances(meet.Key)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get attendances: %s", err),
+			})
+		}
+
+		// users who are still in an ended meeting are counted until it ended.
+		now := time.Now()
+		if meet.EndedAt != nil {
+			now = *meet.EndedAt
+		}
+
+		report := AttendanceReport{
+			MeetingId: meet.MeetingId,
+			Name:      meet.Name,
+			StartedAt: meet.StartedAt,
+			EndedAt:   meet.EndedAt,
+			Attendees: make([]AttendanceEntry, 0, len(atts)),
+		}
+		for _, a := range atts {
+			report.Attendees = append(report.Attendees, AttendanceEntry{
+				UserId:     a.UserId,
+				Name:       a.Name,
+				Role:       a.Role,
+				FirstJoin:  a.FirstJoin(),
+				LastLeave:  a.LastLeave(),
+				TotalTime:  int64(a.TotalTime(now) / time.Second),
+				Reconnects: a.Reconnects(),
+				Present:    a.Present(),
+				Sessions:   a.Sessions,
+			})
+		}
+
+		if format == "json" {
+			return c.JSON(report)
+		}
+
+		out, err := attendanceCSV(report)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to write attendance csv: %s", err),
+			})
+		}
+
+		c.Set(fiber.HeaderContentType, "text/csv")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="attendance-%s.csv"`, meet.MeetingId))
+		return c.Send(out)
+	}
+}
+
+// clientMeeting return the latest meeting with the given ID. Meeting of other client is not
+// found if the requester authenticated as a client.
+func clientMeeting(c *fiber.Ctx, st *store.Store, meetingId string) (store.Meeting, error) {
+	middlewares.SetMeeting(c, meetingId)
+	meet, err := st.Meeting(meetingId)
+	if own := middlewares.Client(c); err == nil && own != "" && meet.Client != own {
+		return store.Meeting{}, store.ErrNotFound
+	}
+
+	return meet, err
+}
+
+// checkMeetingOwner return *fiber.Error unless the meeting with the given ID has been created by
+// the client that made the request. Requests w the main token could reach every meeting, including
+// the ones that aren't in the store.
+func checkMeetingOwner(c *fiber.Ctx, st *store.Store, meetingId string) error {
+	if middlewares.Client(c) == "" {
+		middlewares.SetMeeting(c, meetingId)
+		return nil
+	}
+
+	_, err := clientMeeting(c, st, meetingId)
+	switch {
+	case err == store.ErrNotFound:
+		return fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+	case err != nil:
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+	}
+
+	return nil
+}
+
+// requestClient return copy of the given client that send the ID of the request along every
+// call to BBB API and callback, so they could be traced back to the request.
+func requestClient(c *fiber.Ctx, hCl *http.Client) *http.Client {
+	return logger.HTTPClient(hCl, middlewares.RequestId(c))
+}
+
+// attendanceCSV write the attendees of the report as csv, one user each row.
+func attendanceCSV(report AttendanceReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{{"user_id", "name", "role", "first_join", "last_leave", "total_time", "reconnects"}}
+	for _, a := range report.Attendees {
+		var lastLeave string
+		if a.LastLeave != nil {
+			lastLeave = a.LastLeave.Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			a.UserId,
+			a.Name,
+			a.Role,
+			a.FirstJoin.Format(time.RFC3339),
+			lastLeave,
+			strconv.FormatInt(a.TotalTime, 10),
+			strconv.Itoa(a.Reconnects),
+		})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttendance(t *testing.T) {
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Name: "Class", Client: "lms", CreateTime: 1000}))
+	joined, left := time.Date(2022, 2, 22, 10, 0, 0, 0, time.UTC), time.Date(2022, 2, 22, 10, 45, 0, 0, time.UTC)
+	for _, e := range []event.Event{
+		{Type: event.UserJoined, MeetingId: "meet01", User: &event.User{Id: "usr01", Name: "NzK, S.Kom", Role: "MODERATOR"}, Timestamp: joined},
+		{Type: event.UserLeft, MeetingId: "meet01", User: &event.User{Id: "usr01"}, Timestamp: left},
+	} {
+		require.NoError(t, st.Record(e))
+	}
+
+	app := fiber.New()
+	app.Get("/meetings/:id/attendance", Attendance(st))
+	app.Get("/hr/meetings/:id/attendance", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "hr")
+		return c.Next()
+	}, Attendance(st))
+
+	testCases := []struct {
+		name   string
+		uri    string
+		status int
+		expect func(t *testing.T, body []byte)
+	}{
+		{
+			name:   "Should return attendance report as json by default",
+			uri:    "/meetings/meet01/attendance",
+			status: fiber.StatusOK,
+			expect: func(t *testing.T, body []byte) {
+				var report AttendanceReport
+				require.NoError(t, json.Unmarshal(body, &report))
+				assert.Equal(t, "Class", report.Name)
+				require.Len(t, report.Attendees, 1)
+				assert.Equal(t, "usr01", report.Attendees[0].UserId)
+				assert.Equal(t, int64(45*60), report.Attendees[0].TotalTime)
+				assert.False(t, report.Attendees[0].Present)
+			},
+		},
+		{
+			name:   "Should return attendance report as csv",
+			uri:    "/meetings/meet01/attendance?format=csv",
+			status: fiber.StatusOK,
+			expect: func(t *testing.T, body []byte) {
+				assert.Equal(t, "user_id,name,role,first_join,last_leave,total_time,reconnects\n"+
+					"usr01,\"NzK, S.Kom\",MODERATOR,2022-02-22T10:00:00Z,2022-02-22T10:45:00Z,2700,0\n", string(body))
+			},
+		},
+		{
+			name:   "Unknown format should be rejected",
+			uri:    "/meetings/meet01/attendance?format=xml",
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Unknown meeting should not be found",
+			uri:    "/meetings/unknown/attendance",
+			status: fiber.StatusNotFound,
+		},
+		{
+			name:   "Meeting of other client should not be found",
+			uri:    "/hr/meetings/meet01/attendance",
+			status: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.uri, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.expect != nil {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				tt.expect(t, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// maxBreakoutRooms the most breakout rooms that a meeting could have, same as BBB client.
+const maxBreakoutRooms = 16
+
+// BreakoutsRequest format to create breakout rooms of a meeting.
+type BreakoutsRequest struct {
+	Rooms    int        `json:"rooms"`     // How many rooms to create. Default to the number of `users` groups.
+	Name     string     `json:"name"`      // Name of every room, `{n}` is replaced with the room number. Default to "<meeting name> Room {n}".
+	FreeJoin bool       `json:"free_join"` // Users could choose which room to join.
+	Users    [][]string `json:"users"`     // User IDs that are assigned to every room, in order of the rooms.
+}
+
+// BreakoutRoom a breakout room of a meeting.
+type BreakoutRoom struct {
+	MeetingId    string   `json:"meeting_id"`
+	Name         string   `json:"name"`
+	Sequence     int      `json:"sequence"`
+	FreeJoin     bool     `json:"free_join"`
+	Users        []string `json:"users"` // User IDs that are assigned to the room.
+	CreateTime   string   `json:"create_time"`
+	Running      bool     `json:"running"`
+	Participants int      `json:"participants"`
+}
+
+// CreateBreakouts handler that create breakout rooms of the meeting with ID in `id` param, and
+// assign users to them, so group work could be set up before the meeting starts. The meeting
+// must have been created. Rooms that are left by a request that failed part way are kept, so
+// retrying it only create the rest.
+func CreateBreakouts(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		hCl := requestClient(c, hCl)
+
+		var req BreakoutsRequest
+		if err := c.BodyParser(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to breakout rooms object: %s", err),
+			})
+		}
+		if err := validateBreakouts(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("breakout rooms are invalid: %s", err),
+			})
+		}
+
+		// rooms are checked and created under the lock of the parent, so concurrent requests don't
+		// both create them.
+		unlock := meetingLocks.Lock(c.Params("id"))
+		defer unlock()
+
+		parent, err := breakoutParent(c, st)
+		if err != nil {
+			return sendError(c, err)
+		}
+		switch {
+		case parent.ParentId != "":
+			return sendError(c, fiber.NewError(fiber.StatusBadRequest, "breakout room could not have breakout rooms"))
+		case parent.InternalMeetingId == "":
+			return sendError(c, fiber.NewError(fiber.StatusConflict, "internal meeting ID of the meeting is not known yet"))
+		}
+
+		rooms, err := breakoutRooms(st, parent)
+		if err != nil {
+			return sendError(c, err)
+		}
+		existing := make(map[int]store.Meeting)
+		for _, r := range rooms {
+			if !r.Ended() {
+				existing[int(r.Settings.Sequence)] = r
+			}
+		}
+		if existingRooms(existing, req.Rooms) {
+			return sendError(c, fiber.NewError(fiber.StatusConflict, "breakout rooms of the meeting have been created"))
+		}
+
+		created := make([]BreakoutRoom, 0, req.Rooms)
+		for n := 1; n <= req.Rooms; n++ {
+			var users []string
+			if n <= len(req.Users) {
+				users = req.Users[n-1]
+			}
+
+			var m store.Meeting
+			if r, ok := existing[n]; ok {
+				m, err = assignBreakout(st, parent, r.MeetingId, users)
+			} else {
+				m, err = createBreakout(conf, hCl, bus, st, parent, req, n, users)
+			}
+			if err != nil {
+				return sendError(c, err)
+			}
+			created = append(created, newBreakoutRoom(m))
+		}
+
+		c.Status(fiber.StatusCreated)
+		return c.JSON(created)
+	}
+}
+
+// ListBreakouts handler that send the breakout rooms of the meeting with ID in `id` param, in
+// order of their number.
+func ListBreakouts(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		parent, err := breakoutParent(c, st)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		rooms, err := breakoutRooms(st, parent)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		resp := make([]BreakoutRoom, 0, len(rooms))
+		for _, m := range rooms {
+			resp = append(resp, newBreakoutRoom(m))
+		}
+		sort.Slice(resp, func(i, j int) bool {
+			return resp[i].Sequence < resp[j].Sequence
+		})
+
+		return c.JSON(resp)
+	}
+}
+
+// validateBreakouts check the request and fill the defaults.
+func validateBreakouts(req *BreakoutsRequest) error {
+	if req.Rooms == 0 {
+		req.Rooms = len(req.Users)
+	}
+
+	switch {
+	case req.Rooms < 1 || req.Rooms > maxBreakoutRooms:
+		return fmt.Errorf("`rooms` must be between 1 and %d", maxBreakoutRooms)
+	case len(req.Users) > req.Rooms:
+		return fmt.Errorf("`users` has more groups than `rooms`")
+	}
+
+	assigned := make(map[string]bool)
+	for _, users := range req.Users {
+		for _, id := range users {
+			if assigned[id] {
+				return fmt.Errorf("user `%s` is assigned to more than one room", id)
+			}
+			assigned[id] = true
+		}
+	}
+
+	return nil
+}
+
+// createBreakout create the breakout room with the given number, using the settings of the
+// parent meeting. Returned error is *fiber.Error.
+func createBreakout(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store, parent store.Meeting, req BreakoutsRequest, n int, users []string) (store.Meeting, error) {
+	name := req.Name
+	if name == "" {
+		name = parent.Name + " Room {n}"
+	}
+	if !strings.Contains(name, "{n}") {
+		name += " {n}"
+	}
+
+	settings := parent.Settings
+	settings.Name = strings.Replace(name, "{n}", strconv.Itoa(n), -1)
+	settings.MeetingId = fmt.Sprintf("%s-breakout-%d", parent.MeetingId, n)
+	settings.AttendeePass, settings.ModeratorPass, settings.Template = "", "", ""
+	settings.IsBreakout = true
+	settings.ParentMeetingId = parent.InternalMeetingId
+	settings.Sequence = uint8(n)
+	settings.FreeJoin = req.FreeJoin
+
+	unlock := meetingLocks.Lock(settings.MeetingId)
+	defer unlock()
+
+	if _, err := createMeeting(conf, hCl, bus, st, settings, parent.Client); err != nil {
+		return store.Meeting{}, err
+	}
+
+	return assignBreakout(st, parent, settings.MeetingId, users)
+}
+
+// assignBreakout record the parent of the breakout room with the given meeting ID and the users
+// that are assigned to it. Returned error is *fiber.Error.
+func assignBreakout(st *store.Store, parent store.Meeting, meetingId string, users []string) (store.Meeting, error) {
+	var m store.Meeting
+	err := st.UpdateMeeting(meetingId, func(rec *store.Meeting) error {
+		rec.ParentId, rec.AssignedUsers = parent.MeetingId, users
+		m = *rec
+		return nil
+	})
+	if err != nil {
+		return m, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to record parent of the breakout room: %s", err))
+	}
+
+	return m, nil
+}
+
+// existingRooms return whether every room up to the given number is in the given rooms, which are
+// keyed by their number.
+func existingRooms(rooms map[int]store.Meeting, n int) bool {
+	for i := 1; i <= n; i++ {
+		if _, ok := rooms[i]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// breakoutParent return the meeting with ID in `id` param if it has been created by the client
+// that made the request. Returned error is *fiber.Error.
+func breakoutParent(c *fiber.Ctx, st *store.Store) (store.Meeting, error) {
+	m, err := clientMeeting(c, st, c.Params("id"))
+	switch {
+	case err == store.ErrNotFound:
+		return m, fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+	case err != nil:
+		return m, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+	case m.Ended() || m.CreateTime == 0:
+		return m, fiber.NewError(fiber.StatusConflict, "meeting is not created")
+	}
+
+	return m, nil
+}
+
+// breakoutRooms return the records of breakout rooms that were created for the given record of
+// the parent meeting. Returned error is *fiber.Error.
+func breakoutRooms(st *store.Store, parent store.Meeting) ([]store.Meeting, error) {
+	rooms, _, err := st.History(store.HistoryQuery{ParentId: parent.MeetingId, From: parent.CreatedAt})
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get breakout rooms: %s", err))
+	}
+
+	return rooms, nil
+}
+
+// newBreakoutRoom return the record of a breakout room as response.
+func newBreakoutRoom(m store.Meeting) BreakoutRoom {
+	users := m.AssignedUsers
+	if users == nil {
+		users = []string{}
+	}
+
+	return BreakoutRoom{
+		MeetingId:    m.MeetingId,
+		Name:         m.Name,
+		Sequence:     int(m.Settings.Sequence),
+		FreeJoin:     m.Settings.FreeJoin,
+		Users:        users,
+		CreateTime:   strconv.FormatInt(m.CreateTime, 10),
+		Running:      m.Running(),
+		Participants: m.Participants,
+	}
+}
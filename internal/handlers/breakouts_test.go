@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreakouts(t *testing.T) {
+	var mu sync.Mutex
+	var creates []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		mu.Lock()
+		creates = append(creates, q)
+		mu.Unlock()
+
+		xm, err := xml.Marshal(api.CreateMeetingResponse{
+			StdResponse:       api.StdResponse{CodeString: "SUCCESS"},
+			MeetingId:         q.Get("meetingID"),
+			InternalMeetingId: q.Get("meetingID") + "-internal",
+			AttendeePass:      q.Get("attendeePW"),
+			ModeratorPass:     q.Get("moderatorPW"),
+			CreateTime:        "131313",
+		})
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	for _, m := range []store.Meeting{
+		{MeetingId: "class", InternalMeetingId: "class-internal", Name: "Math", Client: "lms", CreateTime: 121212, Settings: api.CreateMeeting{Name: "Math", MuteOnStart: true, Template: "lecture"}},
+		{MeetingId: "fresh", Name: "Physics", Client: "lms", CreateTime: 121212},
+		{MeetingId: "other", InternalMeetingId: "other-internal", Client: "hr", CreateTime: 121212},
+	} {
+		require.NoError(t, st.SaveMeeting(m))
+	}
+
+	app := fiber.New()
+	asClient := func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	}
+	app.Post("/meetings/:id/breakouts", asClient, CreateBreakouts(conf, server.Client(), event.NewBus(), st))
+	app.Get("/meetings/:id/breakouts", asClient, ListBreakouts(st))
+	app.Post("/join", asClient, JoinMeeting(conf, st))
+
+	request := func(t *testing.T, method, target, body string, v interface{}) int {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		if v != nil && res.StatusCode < 300 {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(v))
+		}
+		return res.StatusCode
+	}
+
+	t.Run("Rooms should be created under the parent meeting", func(t *testing.T) {
+		var rooms []BreakoutRoom
+		status := request(t, fiber.MethodPost, "/meetings/class/breakouts", `{"rooms": 3, "name": "Group {n}", "users": [["mhs 01", "mhs 02"], ["mhs 03"]]}`, &rooms)
+		require.Equal(t, fiber.StatusCreated, status)
+		require.Len(t, rooms, 3)
+		assert.Equal(t, "class-breakout-1", rooms[0].MeetingId)
+		assert.Equal(t, "Group 1", rooms[0].Name)
+		assert.Equal(t, []string{"mhs 01", "mhs 02"}, rooms[0].Users)
+		assert.Equal(t, []string{}, rooms[2].Users)
+
+		require.Len(t, creates, 3)
+		assert.Equal(t, "true", creates[1].Get("isBreakout"))
+		assert.Equal(t, "class-internal", creates[1].Get("parentMeetingID"))
+		assert.Equal(t, "2", creates[1].Get("sequence"))
+		assert.Equal(t, "true", creates[1].Get("muteOnStart"), "settings of the parent should be used")
+
+		m, err := st.Meeting("class-breakout-2")
+		require.NoError(t, err)
+		assert.Equal(t, "class", m.ParentId)
+		assert.Equal(t, "lms", m.Client)
+		assert.Empty(t, m.Settings.Template)
+	})
+
+	t.Run("Rooms should be listed in order", func(t *testing.T) {
+		var rooms []BreakoutRoom
+		require.Equal(t, fiber.StatusOK, request(t, fiber.MethodGet, "/meetings/class/breakouts", "", &rooms))
+		require.Len(t, rooms, 3)
+		for i, r := range rooms {
+			assert.Equal(t, i+1, r.Sequence)
+		}
+	})
+
+	t.Run("Only assigned users should join the room as attendee", func(t *testing.T) {
+		status := request(t, fiber.MethodPost, "/join", `{"name": "Mhs", "meeting_id": "class-breakout-1", "user_id": "mhs 01", "role": "viewer"}`, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+		status = request(t, fiber.MethodPost, "/join", `{"name": "Mhs", "meeting_id": "class-breakout-1", "user_id": "mhs 03", "role": "viewer"}`, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status = request(t, fiber.MethodPost, "/join", `{"name": "Dosen", "meeting_id": "class-breakout-1", "role": "moderator"}`, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+	})
+
+	testCases := []struct {
+		name   string
+		target string
+		body   string
+		expect int
+	}{
+		{name: "Rooms should not be created twice", target: "/meetings/class/breakouts", body: `{"rooms": 2}`, expect: fiber.StatusConflict},
+		{name: "Breakout room should not have rooms", target: "/meetings/class-breakout-1/breakouts", body: `{"rooms": 2}`, expect: fiber.StatusBadRequest},
+		{name: "Meeting w/o internal ID", target: "/meetings/fresh/breakouts", body: `{"rooms": 2}`, expect: fiber.StatusConflict},
+		{name: "Meeting of other client", target: "/meetings/other/breakouts", body: `{"rooms": 2}`, expect: fiber.StatusNotFound},
+		{name: "Too many rooms", target: "/meetings/class/breakouts", body: `{"rooms": 17}`, expect: fiber.StatusBadRequest},
+		{name: "No room", target: "/meetings/class/breakouts", body: `{}`, expect: fiber.StatusBadRequest},
+		{name: "More groups than rooms", target: "/meetings/class/breakouts", body: `{"rooms": 1, "users": [["a"], ["b"]]}`, expect: fiber.StatusBadRequest},
+		{name: "User in two rooms", target: "/meetings/class/breakouts", body: `{"users": [["a"], ["a"]]}`, expect: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, request(t, fiber.MethodPost, tt.target, tt.body, nil))
+		})
+	}
+}
+
+func TestBreakouts_PartialFailure(t *testing.T) {
+	var mu sync.Mutex
+	var failing = true
+	creates := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		mu.Lock()
+		creates[q.Get("meetingID")]++
+		fail := failing && q.Get("sequence") == "2"
+		mu.Unlock()
+
+		res := api.CreateMeetingResponse{
+			StdResponse:       api.StdResponse{CodeString: "SUCCESS"},
+			MeetingId:         q.Get("meetingID"),
+			InternalMeetingId: q.Get("meetingID") + "-internal",
+			AttendeePass:      q.Get("attendeePW"),
+			ModeratorPass:     q.Get("moderatorPW"),
+			CreateTime:        "131313",
+		}
+		if fail {
+			res = api.CreateMeetingResponse{StdResponse: api.StdResponse{CodeString: "FAILED", MsgKey: "internalError"}}
+		}
+		xm, err := xml.Marshal(res)
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "class", InternalMeetingId: "class-internal", Name: "Math", Client: "lms", CreateTime: 121212}))
+
+	app := fiber.New()
+	app.Post("/meetings/:id/breakouts", CreateBreakouts(conf, server.Client(), event.NewBus(), st))
+
+	request := func(t *testing.T, body string) (int, []BreakoutRoom) {
+		req := httptest.NewRequest(fiber.MethodPost, "/meetings/class/breakouts", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+
+		var rooms []BreakoutRoom
+		if res.StatusCode == fiber.StatusCreated {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&rooms))
+		}
+		return res.StatusCode, rooms
+	}
+
+	body := `{"rooms": 3, "users": [["mhs 01"], ["mhs 02"], ["mhs 03"]]}`
+	status, _ := request(t, body)
+	require.NotEqual(t, fiber.StatusCreated, status)
+	m, err := st.Meeting("class-breakout-1")
+	require.NoError(t, err)
+	assert.Equal(t, "class", m.ParentId, "room that was created before the failure is kept")
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	status, rooms := request(t, body)
+	require.Equal(t, fiber.StatusCreated, status, "retry should not conflict w the rooms that were left")
+	require.Len(t, rooms, 3)
+	for i, r := range rooms {
+		assert.Equal(t, i+1, r.Sequence)
+		assert.Equal(t, []string{fmt.Sprintf("mhs 0%d", i+1)}, r.Users)
+	}
+	assert.Equal(t, map[string]int{"class-breakout-1": 1, "class-breakout-2": 2, "class-breakout-3": 1}, creates, "rooms that were left should not be created again")
+
+	status, _ = request(t, body)
+	assert.Equal(t, fiber.StatusConflict, status)
+}
+
+func TestBreakouts_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	var creates int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		mu.Lock()
+		creates++
+		mu.Unlock()
+
+		xm, err := xml.Marshal(api.CreateMeetingResponse{
+			StdResponse:       api.StdResponse{CodeString: "SUCCESS"},
+			MeetingId:         q.Get("meetingID"),
+			InternalMeetingId: q.Get("meetingID") + "-internal",
+			AttendeePass:      q.Get("attendeePW"),
+			ModeratorPass:     q.Get("moderatorPW"),
+			CreateTime:        "131313",
+		})
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "class", InternalMeetingId: "class-internal", Name: "Math", Client: "lms", CreateTime: 121212}))
+
+	app := fiber.New()
+	app.Post("/meetings/:id/breakouts", CreateBreakouts(conf, server.Client(), event.NewBus(), st))
+
+	statuses := make(chan int, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(statuses); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(fiber.MethodPost, "/meetings/class/breakouts", bytes.NewBufferString(`{"rooms": 2}`))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			assert.NoError(t, err)
+			statuses <- res.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	got := make(map[int]int)
+	for s := range statuses {
+		got[s]++
+	}
+	assert.Equal(t, map[int]int{fiber.StatusCreated: 1, fiber.StatusConflict: 3}, got)
+	assert.Equal(t, 2, creates, "rooms should be created once")
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/ical"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// Roles of join links in calendar.
+const (
+	roleAttendee  = "attendee"
+	roleModerator = "moderator"
+)
+
+// CalendarLinks links to subscribe to the calendar of a client.
+type CalendarLinks struct {
+	AttendeeUrl  string `json:"attendee_url"`  // Calendar with links to join as attendee.
+	ModeratorUrl string `json:"moderator_url"` // Calendar with links to join as moderator.
+}
+
+// Calendar handler that send links to subscribe to the calendar of the authenticated client, or
+// of the client in `client` query if authenticated using the main token. Calendar apps can't
+// send token, so the links carry a key that only works for that client and role.
+func Calendar(conf *config.Model) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		name := middlewares.Client(c)
+		if name == "" {
+			name = c.Query("client")
+		}
+		if !configuredClient(conf, name) {
+			c.Status(fiber.StatusNotFound)
+			return c.JSON(fiber.Map{
+				"message": "client is not found",
+			})
+		}
+
+		link := func(role string) string {
+			return fmt.Sprintf("%s/calendar/%s.ics?role=%s&key=%s", conf.PublicUrl, url.PathEscape(name), role, calendarKey(conf, name, role))
+		}
+
+		return c.JSON(CalendarLinks{
+			AttendeeUrl:  link(roleAttendee),
+			ModeratorUrl: link(roleModerator),
+		})
+	}
+}
+
+// ClientCalendar handler that send every scheduled meeting and series of the client in `client`
+// param as iCalendar, to be subscribed by calendar apps. Authenticated by the key in the link
+// from Calendar.
+func ClientCalendar(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		name := c.Params("client")
+		moderator, err := calendarRole(c)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		role := roleAttendee
+		if moderator {
+			role = roleModerator
+		}
+		if subtle.ConstantTimeCompare([]byte(c.Query("key")), []byte(calendarKey(conf, name, role))) != 1 {
+			c.Status(fiber.StatusForbidden)
+			return c.JSON(fiber.Map{
+				"message": "calendar key is invalid",
+			})
+		}
+
+		cal := ical.Calendar{Name: name}
+		err = st.EachSchedule(func(sc store.Schedule) error {
+			// occurrences are written as a part of their series.
+			if sc.Client == name && sc.SeriesId == "" {
+				cal.Events = append(cal.Events, scheduleEvent(conf, sc, moderator))
+			}
+			return nil
+		})
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to read schedules: %s", err),
+			})
+		}
+
+		err = st.EachSeries(func(sr store.Series) error {
+			if sr.Client == name {
+				cal.Events = append(cal.Events, seriesEvent(conf, sr, moderator))
+			}
+			return nil
+		})
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to read series: %s", err),
+			})
+		}
+
+		c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+		return c.Send(cal.Bytes())
+	}
+}
+
+// ScheduleCalendar handler that send the schedule with ID in `id` param as iCalendar file with
+// link to join as the role in `role` query.
+func ScheduleCalendar(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		moderator, err := calendarRole(c)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		sc, err := clientSchedule(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		cal := ical.Calendar{Events: []ical.Event{scheduleEvent(conf, sc, moderator)}}
+		return sendCalendar(c, sc.MeetingId, cal)
+	}
+}
+
+// SeriesCalendar handler that send the series with ID in `id` param as iCalendar file of a
+// recurring event with link to join as the role in `role` query.
+func SeriesCalendar(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		moderator, err := calendarRole(c)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		sr, err := clientSeries(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		cal := ical.Calendar{Events: []ical.Event{seriesEvent(conf, sr, moderator)}}
+		return sendCalendar(c, sr.Id, cal)
+	}
+}
+
+// sendCalendar send the calendar as file with the given name.
+func sendCalendar(c *fiber.Ctx, name string, cal ical.Calendar) error {
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.ics"`, name))
+	return c.Send(cal.Bytes())
+}
+
+// scheduleEvent return the schedule as calendar event.
+func scheduleEvent(conf *config.Model, sc store.Schedule, moderator bool) ical.Event {
+	link := scheduleLink(conf, sc, moderator)
+
+	return ical.Event{
+		UID:         fmt.Sprintf("schedule-%s@%s", sc.Id, calendarDomain(conf)),
+		Summary:     sc.Settings.Name,
+		Description: calendarDescription(link),
+		URL:         link,
+		Start:       sc.StartAt,
+		End:         sc.EndAt,
+		Created:     sc.CreatedAt,
+	}
+}
+
+// seriesEvent return the series as recurring calendar event in its time zone.
+func seriesEvent(conf *config.Model, sr store.Series, moderator bool) ical.Event {
+	link := seriesLink(conf, sr, moderator)
+	loc, err := sr.Location()
+	if err != nil {
+		loc = time.UTC
+	}
+
+	// the first occurrence could be after the start of the series if it's not on the
+	// given days, and calendar apps always count the start as an occurrence.
+	start := sr.StartAt.In(loc)
+	all := sr
+	all.Exceptions = nil
+	interval := sr.Recurrence.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	if occ := all.Occurrences(sr.StartAt, sr.StartAt.AddDate(0, 0, 7*(interval+1))); len(occ) > 0 {
+		start = occ[0].StartAt
+	}
+
+	var exDates []time.Time
+	for _, date := range sr.Exceptions {
+		day, err := time.ParseInLocation("20060102", date, loc)
+		if err != nil {
+			continue
+		}
+		exDates = append(exDates, time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc))
+	}
+
+	return ical.Event{
+		UID:         fmt.Sprintf("series-%s@%s", sr.Id, calendarDomain(conf)),
+		Summary:     sr.Settings.Name,
+		Description: calendarDescription(link),
+		URL:         link,
+		Start:       start,
+		End:         start.Add(sr.Duration),
+		RRule:       seriesRRule(sr, start),
+		ExDates:     exDates,
+		Created:     sr.CreatedAt,
+	}
+}
+
+// seriesRRule return recurrence rule of the series that starts at the given first occurrence.
+func seriesRRule(sr store.Series, first time.Time) string {
+	r := sr.Recurrence
+	parts := []string{"FREQ=" + strings.ToUpper(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Freq == store.Weekly {
+		parts = append(parts, "BYDAY="+strings.Join(r.ByDay, ","), "WKST=MO")
+	}
+
+	// either count or until could end the rule, so the one that is reached first is used.
+	useCount := r.Count > 0
+	if useCount && !r.Until.IsZero() {
+		all := sr
+		all.Exceptions = nil
+		useCount = len(all.Occurrences(first, r.Until.Add(time.Second))) >= r.Count
+	}
+	switch {
+	case useCount:
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	case !r.Until.IsZero():
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// calendarDescription return description of calendar event with the join link.
+func calendarDescription(link string) string {
+	return fmt.Sprintf("Join the meeting: %s", link)
+}
+
+// calendarDomain return domain of this app to make event UID globally unique.
+func calendarDomain(conf *config.Model) string {
+	if u, err := url.Parse(conf.PublicUrl); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+
+	return "bbb-interface"
+}
+
+// calendarRole return whether the `role` query ask for moderator's join link. Returned error
+// is *fiber.Error.
+func calendarRole(c *fiber.Ctx) (bool, error) {
+	switch c.Query("role", roleAttendee) {
+	case roleAttendee:
+		return false, nil
+	case roleModerator:
+		return true, nil
+	}
+
+	return false, fiber.NewError(fiber.StatusBadRequest, "`role` must be either attendee or moderator")
+}
+
+// calendarKey return key to subscribe to the calendar of the client with join links of the role.
+func calendarKey(conf *config.Model, clientName, role string) string {
+	return service.HMACSHA256(conf.CalendarSecret, "calendar/"+clientName+"/"+role)[:32]
+}
+
+// configuredClient return whether the client with the given name is in the config.
+func configuredClient(conf *config.Model, name string) bool {
+	for _, cl := range conf.Clients {
+		if cl.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
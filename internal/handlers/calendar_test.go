@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendar(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	conf.Token = "superSecret"
+	conf.Clients = []config.Client{{Name: "lms"}, {Name: "hr"}}
+	require.NoError(t, conf.Sanitization())
+	conf.PublicUrl = "https://meet.example"
+
+	st := store.New(store.NewMemory())
+	loc := time.FixedZone("", 7*60*60)
+	start := time.Date(2022, 2, 22, 10, 0, 0, 0, loc)
+	for _, sc := range []store.Schedule{
+		{Id: "sch01", MeetingId: "meet01", Client: "lms", StartAt: start, EndAt: start.Add(time.Hour), ModeratorKey: "mdr", AttendeeKey: "att"},
+		{Id: "sch02", MeetingId: "meet02", Client: "hr", StartAt: start, EndAt: start.Add(time.Hour), ModeratorKey: "mdr", AttendeeKey: "att"},
+		{Id: "math-20220221", MeetingId: "math-20220221", SeriesId: "math", Client: "lms", StartAt: start, EndAt: start.Add(time.Hour)},
+	} {
+		sc.Settings.Name = "Class " + sc.Id
+		require.NoError(t, st.SaveSchedule(sc))
+	}
+	sr := store.Series{
+		Id:           "math",
+		Client:       "lms",
+		StartAt:      time.Date(2022, 2, 20, 10, 0, 0, 0, loc), // sunday.
+		Duration:     90 * time.Minute,
+		Recurrence:   store.Recurrence{Freq: store.Weekly, ByDay: []string{"MO", "WE"}, Count: 10},
+		Exceptions:   []string{"20220302"},
+		ModeratorKey: "smdr",
+		AttendeeKey:  "satt",
+	}
+	sr.Settings.Name = "Math"
+	require.NoError(t, sr.Validate())
+	require.NoError(t, st.SaveSeries(sr))
+
+	app := fiber.New()
+	app.Get("/calendar/:client.ics", ClientCalendar(conf, st))
+	own := app.Group("/own", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	})
+	for _, r := range []fiber.Router{app, own} {
+		r.Get("/calendar", Calendar(conf))
+		r.Get("/schedules/:id.ics", ScheduleCalendar(conf, st))
+		r.Get("/series/:id.ics", SeriesCalendar(conf, st))
+	}
+
+	request := func(t *testing.T, uri string, status int) string {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, uri, nil))
+		require.NoError(t, err)
+		require.Equal(t, status, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		// unfold the lines so they could be matched.
+		return strings.ReplaceAll(string(body), "\r\n ", "")
+	}
+
+	var links CalendarLinks
+	require.NoError(t, json.Unmarshal([]byte(request(t, "/own/calendar", fiber.StatusOK)), &links))
+
+	t.Run("Calendar links should belong to the authenticated client", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(links.AttendeeUrl, "https://meet.example/calendar/lms.ics?role=attendee&key="))
+		assert.True(t, strings.HasPrefix(links.ModeratorUrl, "https://meet.example/calendar/lms.ics?role=moderator&key="))
+		request(t, "/calendar", fiber.StatusNotFound)
+		request(t, "/calendar?client=unknown", fiber.StatusNotFound)
+	})
+
+	t.Run("Client calendar should have its schedules and series", func(t *testing.T) {
+		u, err := url.Parse(links.AttendeeUrl)
+		require.NoError(t, err)
+		body := request(t, u.RequestURI(), fiber.StatusOK)
+
+		assert.Contains(t, body, "UID:schedule-sch01@meet.example\r\n")
+		assert.Contains(t, body, "URL:https://meet.example/schedules/sch01/join?key=att\r\n")
+		assert.Contains(t, body, "UID:series-math@meet.example\r\n")
+		assert.NotContains(t, body, "sch02", "schedule of other client should not be included")
+		assert.NotContains(t, body, "schedule-math-20220221", "occurrence should be a part of its series")
+	})
+
+	t.Run("Key should only change w the calendar secret", func(t *testing.T) {
+		key := calendarKey(conf, "lms", roleAttendee)
+
+		other := *conf
+		other.Token = "rotatedSecret"
+		assert.Equal(t, key, calendarKey(&other, "lms", roleAttendee), "rotating the main token should keep calendars working")
+
+		other.CalendarSecret = "otherCalendarSecret"
+		assert.NotEqual(t, key, calendarKey(&other, "lms", roleAttendee))
+	})
+
+	t.Run("Client calendar w key of other role or client should be rejected", func(t *testing.T) {
+		u, err := url.Parse(links.AttendeeUrl)
+		require.NoError(t, err)
+		key := u.Query().Get("key")
+		request(t, "/calendar/lms.ics?role=moderator&key="+key, fiber.StatusForbidden)
+		request(t, "/calendar/hr.ics?role=attendee&key="+key, fiber.StatusForbidden)
+	})
+
+	t.Run("Series should be a recurring event from its first occurrence", func(t *testing.T) {
+		body := request(t, "/own/series/math.ics?role=moderator", fiber.StatusOK)
+
+		assert.Contains(t, body, "DTSTART;TZID=UTC+0700:20220221T100000\r\n")
+		assert.Contains(t, body, "DTEND;TZID=UTC+0700:20220221T113000\r\n")
+		assert.Contains(t, body, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;WKST=MO;COUNT=10\r\n")
+		assert.Contains(t, body, "EXDATE;TZID=UTC+0700:20220302T100000\r\n")
+		assert.Contains(t, body, "URL:https://meet.example/series/math/join?key=smdr\r\n")
+	})
+
+	t.Run("Schedule should be downloaded as file", func(t *testing.T) {
+		body := request(t, "/own/schedules/sch01.ics", fiber.StatusOK)
+		assert.Contains(t, body, "SUMMARY:Class sch01\r\n")
+		request(t, "/own/schedules/sch02.ics", fiber.StatusNotFound)
+		request(t, "/own/schedules/sch01.ics?role=admin", fiber.StatusBadRequest)
+	})
+}
+
+func TestSeriesRRule(t *testing.T) {
+	start := time.Date(2022, 3, 7, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		recurrence store.Recurrence
+		expect     string
+	}{
+		{name: "Daily w/o end", recurrence: store.Recurrence{Freq: store.Daily}, expect: "FREQ=DAILY"},
+		{name: "Daily w interval", recurrence: store.Recurrence{Freq: store.Daily, Interval: 2, Count: 5}, expect: "FREQ=DAILY;INTERVAL=2;COUNT=5"},
+		{name: "Weekly until", recurrence: store.Recurrence{Freq: store.Weekly, Until: start.AddDate(0, 1, 0)}, expect: "FREQ=WEEKLY;BYDAY=MO;WKST=MO;UNTIL=20220407T100000Z"},
+		{name: "Until that is reached before count", recurrence: store.Recurrence{Freq: store.Daily, Count: 10, Until: start.AddDate(0, 0, 2)}, expect: "FREQ=DAILY;UNTIL=20220309T100000Z"},
+		{name: "Count that is reached before until", recurrence: store.Recurrence{Freq: store.Daily, Count: 2, Until: start.AddDate(0, 0, 5)}, expect: "FREQ=DAILY;COUNT=2"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := store.Series{StartAt: start, Duration: time.Hour, Recurrence: tt.recurrence}
+			require.NoError(t, sr.Validate())
+			assert.Equal(t, tt.expect, seriesRRule(sr, start))
+		})
+	}
+}
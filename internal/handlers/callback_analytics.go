@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// CallbackOnAnalytics handler that receive learning dashboard data from BBB server after a meeting
+// ended. The request is verified using JWT in Authorization header that signed with BBB secret,
+// then the data is saved to the meeting record and analytics-ready event is published to the bus.
+func CallbackOnAnalytics(conf *config.Model, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if err := service.VerifyJWT(token, conf.BBB.Secret, nil); err != nil {
+			c.Status(fiber.StatusUnauthorized)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to verify analytics callback: %s", err),
+			})
+		}
+
+		var data api.AnalyticsCallback
+		if err := json.Unmarshal(c.Body(), &data); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse analytics callback: %s", err),
+			})
+		}
+
+		err := st.SaveAnalytics(data.MeetingId, data)
+		if err == store.ErrNotFound {
+			c.Status(fiber.StatusNotFound)
+			return c.JSON(fiber.Map{
+				"message": "meeting is not found",
+			})
+		}
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to save analytics: %s", err),
+			})
+		}
+
+		bus.Publish(event.Event{
+			Type:              event.AnalyticsReady,
+			MeetingId:         data.MeetingId,
+			InternalMeetingId: data.InternalMeetingId,
+		})
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleAnalyticsCallback = `{
+	"version": "1.0",
+	"meeting_id": "meet01",
+	"internal_meeting_id": "int01",
+	"data": {
+		"metadata": {"analytics_callback_url": "https://lms.example/analytics"},
+		"duration": 3600,
+		"start": "2022-02-22 10:00:00 +0700",
+		"finish": "2022-02-22 11:00:00 +0700",
+		"attendees": [{
+			"ext_user_id": "usr01",
+			"name": "NzK",
+			"moderator": true,
+			"joins": ["2022-02-22 10:00:00 +0700"],
+			"leaves": ["2022-02-22 11:00:00 +0700"],
+			"duration": 3600,
+			"recent_talking_time": "2022-02-22 10:59:00 +0700",
+			"engagement": {"chats": 3, "talks": 5, "raisehand": 1, "emojis": 2, "poll_votes": 1, "talk_time": 600}
+		}],
+		"files": ["default.pdf"],
+		"polls": [{"id": "poll01", "type": "YN", "question": "Ready?", "published": true, "options": ["Yes", "No"], "start": "2022-02-22 10:30:00 +0700", "votes": {"usr01": "Yes"}}]
+	}
+}`
+
+func TestCallbackOnAnalytics(t *testing.T) {
+	conf := &config.Model{}
+	conf.BBB.Secret = "secret"
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", CreateTime: 1000}))
+	bus := event.NewBus()
+	ch, unsub := bus.Subscribe(16, event.ByTypes(event.AnalyticsReady))
+	defer unsub()
+
+	app := fiber.New()
+	app.Post("/callback/analytics", CallbackOnAnalytics(conf, bus, st))
+
+	sign := func(secret string, exp time.Duration) string {
+		token, err := service.SignJWT(map[string]interface{}{"exp": time.Now().Add(exp).Unix()}, secret)
+		require.NoError(t, err)
+		return token
+	}
+
+	testCases := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+	}{
+		{name: "Request w/o token should be rejected", body: sampleAnalyticsCallback, status: fiber.StatusUnauthorized},
+		{name: "Request signed w other secret should be rejected", token: sign("other", time.Hour), body: sampleAnalyticsCallback, status: fiber.StatusUnauthorized},
+		{name: "Request w expired token should be rejected", token: sign("secret", -time.Hour), body: sampleAnalyticsCallback, status: fiber.StatusUnauthorized},
+		{name: "Request w invalid body should be rejected", token: sign("secret", time.Hour), body: "{", status: fiber.StatusBadRequest},
+		{name: "Analytics of unknown meeting should not be found", token: sign("secret", time.Hour), body: `{"meeting_id": "unknown"}`, status: fiber.StatusNotFound},
+		{name: "Verified analytics should be saved", token: sign("secret", time.Hour), body: sampleAnalyticsCallback, status: fiber.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/callback/analytics", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+
+	t.Run("Saved analytics should be parsed and announced", func(t *testing.T) {
+		e := <-ch
+		assert.Equal(t, "meet01", e.MeetingId)
+
+		a, err := st.Analytics(store.MeetingKey("meet01", 1000))
+		require.NoError(t, err)
+		require.Len(t, a.Data.Data.Attendees, 1)
+		assert.Equal(t, int64(600), a.Data.Data.Attendees[0].Engagement.TalkTime)
+		assert.Equal(t, 1, a.Data.Data.Attendees[0].Engagement.RaiseHand)
+		require.Len(t, a.Data.Data.Polls, 1)
+		assert.Equal(t, "Yes", a.Data.Data.Polls[0].Votes["usr01"])
+	})
+}
@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
 )
 
 // DestroyCallbackModel model that provided by lms app to notify that a meeting
@@ -16,13 +21,40 @@ type DestroyCallbackModel struct {
 	MeetingId string `json:"meeting_id"` // Meeting id that determine which meeting was destroyed.
 }
 
+// destroyCallbackUrl return the url BBB server should call when the given meeting ended, which
+// is signed so only BBB server could end the meeting.
+func destroyCallbackUrl(conf *config.Model, meetingId string) string {
+	q := url.Values{}
+	q.Set("meetingID", meetingId)
+	q.Set("signature", service.HMACSHA256(conf.BBB.Secret, destroyCallbackPrefix+meetingId))
+
+	return fmt.Sprintf("%s?%s", conf.CallbackOnDestroyThisApp, q.Encode())
+}
+
+// destroyCallbackPrefix prefix of the signed meeting ID, so the signature could not be used for
+// anything else signed w BBB secret.
+const destroyCallbackPrefix = "end-callback:"
+
 // CallbackOnDestroy handler that will receive GET request from BBB server when a meeting was destroyed
 // or ended, then sent POST request to designated lms endpoint complete with the body request that
 // would determine which meeting was destroyed using meeting_id sent by BBB server's GET request.
-func CallbackOnDestroy(conf *config.Model, htC *http.Client) func(*fiber.Ctx) error {
+// Also publish meeting-ended event to the bus. The request must have the signature of the meeting
+// ID that was given to BBB server when the meeting was created.
+func CallbackOnDestroy(conf *config.Model, htC *http.Client, bus *event.Bus) func(*fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
+		htC := requestClient(c, htC)
+
 		// proses incoming URL from BBB server
 		meetId := c.Query("meetingID")
+		middlewares.SetMeeting(c, meetId)
+		if !service.VerifyHMACSHA256(conf.BBB.Secret, destroyCallbackPrefix+meetId, c.Query("signature")) {
+			c.Status(fiber.StatusUnauthorized)
+			return c.JSON(fiber.Map{
+				"message": "signature of destroy callback is invalid",
+			})
+		}
+		bus.Publish(event.Event{Type: event.MeetingEnded, MeetingId: meetId, RequestId: middlewares.RequestId(c)})
+
 		payload := &DestroyCallbackModel{MeetingId: meetId}
 
 		jsonPayload, err := json.Marshal(&payload)
@@ -32,10 +64,17 @@ func CallbackOnDestroy(conf *config.Model, htC *http.Client) func(*fiber.Ctx) er
 
 		res, err := htC.Post(conf.CallbackOnDestroy, fiber.MIMEApplicationJSON, bytes.NewReader(jsonPayload))
 		if err != nil {
+			metrics.ObserveCallback(metrics.CallbackDestroy, metrics.ResultError)
 			return fmt.Errorf("failed to send request to lms endpoint: %s", err)
 		}
 		defer res.Body.Close()
 
+		if res.StatusCode >= http.StatusBadRequest {
+			metrics.ObserveCallback(metrics.CallbackDestroy, metrics.ResultFailed)
+		} else {
+			metrics.ObserveCallback(metrics.CallbackDestroy, metrics.ResultSuccess)
+		}
+
 		return c.SendStatus(fiber.StatusOK)
 	}
 }
@@ -7,10 +7,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/logger"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,11 +34,11 @@ func TestCallbackOnDestroy(t *testing.T) {
 	require.NoError(t, conf.Sanitization())
 
 	app := fiber.New()
-	app.Get("/callback/destroy", CallbackOnDestroy(conf, fakeCallbackServerHelper().Client()))
+	app.Get("/callback/destroy", CallbackOnDestroy(conf, fakeCallbackServerHelper().Client(), event.NewBus()))
 
 	t.Run("Every GET request to this endpoint should pass", func(t *testing.T) {
 		meetID := "meet01"
-		uri := fmt.Sprintf("/callback/destroy?meetingID=%s", meetID)
+		uri := signedDestroyUri(conf, meetID)
 		req := httptest.NewRequest(fiber.MethodGet, uri, nil)
 		res, err := app.Test(req)
 		require.NoError(t, err)
@@ -40,6 +46,45 @@ func TestCallbackOnDestroy(t *testing.T) {
 	})
 }
 
+// signedDestroyUri return the path of destroy callback that BBB server would call when the given
+// meeting ended.
+func signedDestroyUri(conf *config.Model, meetingId string) string {
+	u, _ := url.Parse(destroyCallbackUrl(conf, meetingId))
+	return "/callback/destroy?" + u.RawQuery
+}
+
+func TestCallbackOnDestroy_Signature(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+
+	bus := event.NewBus()
+	ch, unsubscribe := bus.Subscribe(1, nil)
+	defer unsubscribe()
+
+	app := fiber.New()
+	app.Get("/callback/destroy", CallbackOnDestroy(conf, &http.Client{}, bus))
+
+	valid := signedDestroyUri(conf, "meet01")
+	testCases := []struct {
+		name string
+		uri  string
+	}{
+		{name: "Request w/o signature should be rejected", uri: "/callback/destroy?meetingID=meet01"},
+		{name: "Signature of other meeting should be rejected", uri: strings.Replace(valid, "meet01", "meet02", 1)},
+		{name: "Wrong signature should be rejected", uri: "/callback/destroy?meetingID=meet01&signature=abc"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.uri, nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+			assert.Empty(t, ch, "meeting-ended should not be published")
+		})
+	}
+}
+
 // prepare fake server to mimic lms app.
 var fakeCallbackServerHelper = func(expectPayload string, t *testing.T) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -96,9 +141,9 @@ func TestCallbackOnDestroy_UsingFakeServerToReceivePOSTCallback(t *testing.T) {
 			require.NoError(t, conf.Sanitization())
 
 			app := fiber.New()
-			app.Get("/callback/destroy", CallbackOnDestroy(conf, fakeCallbackServerHelper(tc.expect, t).Client()))
+			app.Get("/callback/destroy", CallbackOnDestroy(conf, fakeCallbackServerHelper(tc.expect, t).Client(), event.NewBus()))
 
-			uri := fmt.Sprintf("/callback/destroy?meetingID=%s", tc.sample)
+			uri := signedDestroyUri(conf, tc.sample)
 			req := httptest.NewRequest(fiber.MethodGet, uri, nil)
 			res, err := app.Test(req)
 			require.NoError(t, err)
@@ -107,6 +152,37 @@ func TestCallbackOnDestroy_UsingFakeServerToReceivePOSTCallback(t *testing.T) {
 	}
 }
 
+func TestCallbackOnDestroy_RequestId(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received <- req.Header.Get(logger.RequestIdHeader)
+	}))
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	conf.CallbackOnDestroy = server.URL
+	require.NoError(t, conf.Sanitization())
+
+	bus := event.NewBus()
+	ch, unsubscribe := bus.Subscribe(1, nil)
+	defer unsubscribe()
+
+	app := fiber.New()
+	app.Use(requestid.New(requestid.Config{ContextKey: middlewares.RequestIdKey}))
+	app.Get("/callback/destroy", CallbackOnDestroy(conf, server.Client(), bus))
+
+	req := httptest.NewRequest(fiber.MethodGet, signedDestroyUri(conf, "meet01"), nil)
+	req.Header.Set(logger.RequestIdHeader, "req-1")
+	res, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	// the callback and the event should carry the ID of the request from BBB server.
+	assert.Equal(t, "req-1", <-received)
+	assert.Equal(t, "req-1", (<-ch).RequestId)
+}
+
 func TestCallbackOnDestroy_ErrorPathToImproveCoverage(t *testing.T) {
 	// prepare fake server just to make this test pass.
 	var fakeCallbackServerHelper = func() *httptest.Server {
@@ -120,10 +196,10 @@ func TestCallbackOnDestroy_ErrorPathToImproveCoverage(t *testing.T) {
 	require.NoError(t, conf.Sanitization())
 
 	app := fiber.New()
-	app.Get("/callback/destroy", CallbackOnDestroy(conf, fakeCallbackServerHelper().Client()))
+	app.Get("/callback/destroy", CallbackOnDestroy(conf, fakeCallbackServerHelper().Client(), event.NewBus()))
 
 	t.Run("Using fake server url should error and return 500 status code", func(t *testing.T) {
-		req := httptest.NewRequest(fiber.MethodGet, "/callback/destroy", nil)
+		req := httptest.NewRequest(fiber.MethodGet, signedDestroyUri(conf, ""), nil)
 		res, err := app.Test(req)
 		require.NoError(t, err)
 		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
@@ -5,18 +5,30 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/api"
 	"github.com/kurvaid/bbb-interface/internal/client"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/logger"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
 	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
 )
 
 // CreateMeeting handler that receive json request and proxy it to BBB API after convert to URL
-// then send back response from BBB API to the requester.
-func CreateMeeting(conf *config.Model, httpClient *http.Client) func(c *fiber.Ctx) error {
+// then send back response from BBB API to the requester. Request that has `template` get every
+// setting it doesn't have from the template. The created meeting is recorded to the store.
+// Meeting ID of client is namespaced, the response has the ID that is used in BBB server. The
+// passwords are left out of the response when users join using role.
+func CreateMeeting(conf *config.Model, httpClient *http.Client, bus *event.Bus, st *store.Store) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
+		httpClient := requestClient(c, httpClient)
+
 		// bind incoming json request to predefined object.
 		var cMeet api.CreateMeeting
 		if err := c.BodyParser(&cMeet); err != nil {
@@ -25,44 +37,193 @@ func CreateMeeting(conf *config.Model, httpClient *http.Client) func(c *fiber.Ct
 				"message": fmt.Errorf("failed to bind request to create meeting object: %s", err),
 			})
 		}
+		if err := applyTemplate(conf, st, c.Body(), &cMeet); err != nil {
+			return sendError(c, err)
+		}
+		clientName := middlewares.Client(c)
+		cMeet.MeetingId = namespacedMeetingId(conf, clientName, cMeet.MeetingId)
+		middlewares.SetMeeting(c, cMeet.MeetingId)
 
-		randNum := service.RandomString{Length: int(conf.RandomLen)}
-		uri, err := cMeet.ParseCreateMeeting(&randNum)
+		jsonResp, err := createMeeting(conf, httpClient, bus, st, cMeet, clientName)
 		if err != nil {
-			c.Status(fiber.StatusInternalServerError)
-			return c.JSON(fiber.Map{
-				"message": fmt.Sprintf("failed to parse create meeting url: %s", err),
-			})
+			return sendError(c, err)
 		}
+		middlewares.SetMeeting(c, jsonResp.MeetingId)
+		if conf.BBB.RoleJoin {
+			jsonResp.AttendeePass, jsonResp.ModeratorPass = "", ""
+		}
+
+		c.Status(fiber.StatusCreated)
+		return c.JSON(jsonResp)
+	}
+}
 
-		// append this app callback endpoint when a meeting destroyed or ended also
-		// the meeting id to the designated endpoint
-		callbackEndPoint := fmt.Sprintf("%s?meetingID=%s", conf.CallbackOnDestroyThisApp, cMeet.MeetingId)
-		// append the callback to create room requests
-		uri += fmt.Sprintf("&meta_endCallbackUrl=%s", url.QueryEscape(callbackEndPoint))
-		// prepare url and calculate their checksum.
-		out := service.SHA1HashUrl(conf.BBB.Secret, uri)
-		uri = fmt.Sprintf("%s%s%s", conf.BBB.Host, api.EndPoint, uri)
+// maxMeetingIdAttempts how many meeting IDs are generated before giving up creating a meeting
+// whose ID isn't given, when the generated ones are already used.
+const maxMeetingIdAttempts = 3
 
-		createMeetApi := client.Instance{Cl: httpClient, Url: uri, Checksum: out}
+// errMeetingIdTaken returned when the meeting ID is already used by other meeting, either in the
+// store or in BBB server.
+var errMeetingIdTaken = fiber.NewError(fiber.StatusConflict, "meeting ID is already used by other meeting")
 
-		resp, err := createMeetApi.DispatchGET()
-		if err != nil {
-			c.Status(fiber.StatusBadGateway)
-			return c.JSON(fiber.Map{
-				"message": fmt.Sprintf("failed sending create meeting request to BBB API: %s", err),
-			})
+// createMeeting send create meeting request to BBB API, then record the created meeting to the
+// store as owned by the given client and publish meeting-created event. Meeting ID that isn't
+// given is generated in `meeting_id_format` config, and generated again if it's already used.
+// Returned error is *fiber.Error with the status code that should be sent to the requester.
+func createMeeting(conf *config.Model, httpClient *http.Client, bus *event.Bus, st *store.Store, cMeet api.CreateMeeting, clientName string) (api.CreateMeetingResponse, error) {
+	generated := cMeet.MeetingId == ""
+	randId := meetingIdGenerator(conf)
+
+	for i := 1; ; i++ {
+		attempt := cMeet
+		if generated {
+			attempt.MeetingId = randId.RandString()
 		}
 
-		var jsonResp api.CreateMeetingResponse
-		if err := xml.Unmarshal(resp, &jsonResp); err != nil {
-			c.Status(fiber.StatusBadGateway)
-			return c.JSON(fiber.Map{
-				"message": fmt.Sprintf("failed binding BBB API response to json response: %s", err),
-			})
+		jsonResp, err := sendCreateMeeting(conf, httpClient, bus, st, attempt, clientName, generated)
+		if err != errMeetingIdTaken || !generated || i >= maxMeetingIdAttempts {
+			return jsonResp, err
 		}
+	}
+}
 
-		c.Status(fiber.StatusCreated)
-		return c.JSON(jsonResp)
+// sendCreateMeeting create the meeting in the request which already has its ID. Would return
+// errMeetingIdTaken if the ID is used by other meeting. Generated ID is used if no meeting has
+// ever used it, otherwise the ID is used if it has not been used by other client.
+func sendCreateMeeting(conf *config.Model, httpClient *http.Client, bus *event.Bus, st *store.Store, cMeet api.CreateMeeting, clientName string, generated bool) (api.CreateMeetingResponse, error) {
+	var jsonResp api.CreateMeetingResponse
+
+	// meeting that is running in BBB server is only the same one if this client recorded it.
+	own := false
+	prev, err := st.Meeting(cMeet.MeetingId)
+	switch {
+	case err == store.ErrNotFound:
+	case err != nil:
+		return jsonResp, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+	case generated:
+		return jsonResp, errMeetingIdTaken
+	case clientName != "" && prev.Client != clientName:
+		return jsonResp, errMeetingIdTaken
+	default:
+		own = !prev.Ended()
+	}
+
+	// keep the request as it was sent because parsing would escape some of its fields.
+	settings := cMeet
+
+	uri, err := cMeet.ParseCreateMeeting(randomString(conf))
+	if err != nil {
+		return jsonResp, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to parse create meeting url: %s", err))
+	}
+
+	// append this app callback endpoint when a meeting destroyed or ended also
+	// the meeting id to the designated endpoint
+	callbackEndPoint := destroyCallbackUrl(conf, cMeet.MeetingId)
+	// append the callback to create room requests
+	uri += fmt.Sprintf("&meta_endCallbackUrl=%s", url.QueryEscape(callbackEndPoint))
+	// ask BBB server to send learning dashboard data after the meeting ended.
+	if conf.CallbackOnAnalyticsThisApp != "" {
+		uri += fmt.Sprintf("&meta_analytics-callback-url=%s", url.QueryEscape(conf.CallbackOnAnalyticsThisApp))
+	}
+	// prepare url and calculate their checksum.
+	out := service.SHA1HashUrl(conf.BBB.Secret, uri)
+	uri = fmt.Sprintf("%s%s%s", conf.BBB.Host, api.EndPoint, uri)
+
+	createMeetApi := client.Instance{Cl: httpClient, Url: uri, Checksum: out}
+
+	resp, err := createMeetApi.DispatchGET()
+	if err != nil {
+		return jsonResp, fiber.NewError(fiber.StatusBadGateway, fmt.Sprintf("failed sending create meeting request to BBB API: %s", err))
+	}
+
+	if err := xml.Unmarshal(resp, &jsonResp); err != nil {
+		return jsonResp, fiber.NewError(fiber.StatusBadGateway, fmt.Sprintf("failed binding BBB API response to json response: %s", err))
+	}
+
+	// BBB server reject the ID that is used with different settings, and return the existing
+	// meeting with duplicateWarning when the settings are the same.
+	switch {
+	case jsonResp.CodeString == "FAILED" && jsonResp.MsgKey == "idNotUnique":
+		return jsonResp, errMeetingIdTaken
+	case jsonResp.CodeString == "FAILED":
+		return jsonResp, fiber.NewError(fiber.StatusBadGateway, fmt.Sprintf("failed to create meeting in BBB API: %s", jsonResp.MsgDetail))
+	case jsonResp.MsgKey == "duplicateWarning" && !own:
+		return jsonResp, errMeetingIdTaken
+	}
+
+	settings.MeetingId, settings.AttendeePass, settings.ModeratorPass = cMeet.MeetingId, cMeet.AttendeePass, cMeet.ModeratorPass
+	createTime, _ := strconv.ParseInt(jsonResp.CreateTime, 10, 64)
+	meet := store.Meeting{
+		MeetingId:         jsonResp.MeetingId,
+		InternalMeetingId: jsonResp.InternalMeetingId,
+		Name:              cMeet.Name,
+		Client:            clientName,
+		AttendeePass:      jsonResp.AttendeePass,
+		ModeratorPass:     jsonResp.ModeratorPass,
+		CreateTime:        createTime,
+		Settings:          settings,
+	}
+	if err := st.SaveMeeting(meet); err != nil {
+		return jsonResp, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to record the created meeting: %s", err))
 	}
+	metrics.MeetingCreated(clientName)
+
+	bus.Publish(event.Event{
+		Type:      event.MeetingCreated,
+		MeetingId: jsonResp.MeetingId,
+		Client:    clientName,
+		RequestId: logger.RequestIdOf(httpClient),
+	})
+
+	return jsonResp, nil
+}
+
+// randomString return generator of passwords, which is secure and configured by `random_len`,
+// `random_alphabet` and `random_entropy`.
+func randomString(conf *config.Model) *service.SecureString {
+	return &service.SecureString{Length: int(conf.RandomLen), Alphabet: conf.RandomAlphabet}
+}
+
+// meetingIdGenerator return generator of meeting IDs in `meeting_id_format` config.
+func meetingIdGenerator(conf *config.Model) service.RandStringInterface {
+	return service.NewIdGenerator(conf.MeetingIdFormat, int(conf.RandomLen), conf.RandomAlphabet)
+}
+
+// namespacedMeetingId return the meeting ID that is given by the client as it's used in BBB
+// server, according to `meeting_id_namespace` config. ID given by the main token isn't changed.
+func namespacedMeetingId(conf *config.Model, clientName, id string) string {
+	if id == "" || clientName == "" {
+		return id
+	}
+
+	switch conf.MeetingIdNamespace {
+	case config.NamespacePrefix:
+		// the ID could be sent back as the client got it, e.g. to recreate the meeting. Client
+		// names have no -, so the prefix could only be of this client.
+		if strings.HasPrefix(id, clientName+"-") {
+			return id
+		}
+		return fmt.Sprintf("%s-%s", clientName, id)
+	case config.NamespaceHash:
+		// the ID could be sent back as the client got it, which must not be hashed again.
+		if len(id) == hashedIdLen+hashCheckLen && id == hashedMeetingId(clientName, id[:hashedIdLen]) {
+			return id
+		}
+		return hashedMeetingId(clientName, service.SHA1Hash(clientName, "/", id)[:hashedIdLen])
+	}
+
+	return id
+}
+
+// Lengths of the parts of meeting ID in hash namespace, which is 40 hex characters like SHA1.
+const (
+	hashedIdLen  = 32 // SHA1 hex of the client name and the given ID.
+	hashCheckLen = 8  // SHA1 hex of the client name and the first part.
+)
+
+// hashedMeetingId return the meeting ID in hash namespace of the client, which is the hashed ID
+// followed by its check. The check only matches for this client, so an ID of other client is
+// hashed again, never taken as it is.
+func hashedMeetingId(clientName, hashed string) string {
+	return hashed + service.SHA1Hash(clientName, "/", hashed)[:hashCheckLen]
 }
@@ -6,11 +6,16 @@ import (
 	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/api"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -62,8 +67,9 @@ func TestCreateMeeting(t *testing.T) {
 	conf.BBB.Host = fakeServerHelper(t).URL
 	require.NoError(t, conf.BBB.Sanitization())
 
+	st := store.New(store.NewMemory())
 	app := fiber.New()
-	app.Post("/meeting", CreateMeeting(conf, fakeServerHelper(t).Client()))
+	app.Post("/meeting", CreateMeeting(conf, fakeServerHelper(t).Client(), event.NewBus(), st))
 
 	t.Run("Success using minimum (required) json request", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleRequestBody[0])
@@ -83,6 +89,33 @@ func TestCreateMeeting(t *testing.T) {
 		assert.Equal(t, "password", rXML.ModeratorPass)
 		assert.Equal(t, "secret", rXML.AttendeePass)
 		assert.Equal(t, "121212", rXML.CreateTime)
+
+		meet, err := st.Meeting("fake-id")
+		require.NoError(t, err)
+		assert.Equal(t, "test-meeting", meet.Name)
+		assert.Equal(t, "password", meet.ModeratorPass)
+		assert.Equal(t, int64(121212), meet.CreateTime)
+	})
+
+	t.Run("Passwords should not be sent back when users join using role", func(t *testing.T) {
+		conf.BBB.RoleJoin = true
+		defer func() { conf.BBB.RoleJoin = false }()
+
+		req := httptest.NewRequest(fiber.MethodPost, "/meeting", bytes.NewBufferString(sampleRequestBody[0]))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		assert.Equal(t, "fake-id", body["meeting_id"])
+		assert.NotContains(t, body, "attendee_pass")
+		assert.NotContains(t, body, "moderator_pass")
+
+		meet, err := st.Meeting("fake-id")
+		require.NoError(t, err)
+		assert.Equal(t, "password", meet.ModeratorPass, "passwords should still be recorded")
 	})
 }
 
@@ -92,7 +125,7 @@ func TestCreateMeeting_FailedUsingFakeBBBHost(t *testing.T) {
 	require.NoError(t, conf.Sanitization())
 
 	app := fiber.New()
-	app.Post("/meeting", CreateMeeting(conf, fakeServerHelper(t).Client()))
+	app.Post("/meeting", CreateMeeting(conf, fakeServerHelper(t).Client(), event.NewBus(), store.New(store.NewMemory())))
 
 	t.Run("Failed when sending using fake host for the BBB server", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleRequestBody[0])
@@ -114,7 +147,7 @@ func TestCreateMeeting_FailedUsingEmptyJSONRequest(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/meeting", CreateMeeting(conf, fakeServerHelper(t).Client()))
+	app.Post("/meeting", CreateMeeting(conf, fakeServerHelper(t).Client(), event.NewBus(), store.New(store.NewMemory())))
 
 	t.Run("Failed when sending using fake host for the BBB server", func(t *testing.T) {
 		buf := bytes.NewBufferString(`empty`)
@@ -165,7 +198,7 @@ func TestCreateMeeting_FailedCausedByBBB(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/meeting", CreateMeeting(conf, fakeFailedServer(t).Client()))
+	app.Post("/meeting", CreateMeeting(conf, fakeFailedServer(t).Client(), event.NewBus(), store.New(store.NewMemory())))
 
 	t.Run("Failed if BBB server send response back using content type other than xml", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleRequestBody[0])
@@ -178,3 +211,291 @@ func TestCreateMeeting_FailedCausedByBBB(t *testing.T) {
 		assert.Equal(t, fiber.StatusBadGateway, res.StatusCode)
 	})
 }
+
+func TestCreateMeeting_AnalyticsCallback(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query = req.URL.RawQuery
+		xm, err := xml.Marshal(&api.CreateMeetingResponse{MeetingId: "fake-id"})
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	testCases := []struct {
+		name     string
+		callback string
+		expect   string
+	}{
+		{
+			name:     "Analytics callback should be registered if configured",
+			callback: "http://localhost/callback/analytics",
+			expect:   "meta_analytics-callback-url=http%3A%2F%2Flocalhost%2Fcallback%2Fanalytics",
+		},
+		{
+			name:   "Analytics callback should not be registered if not configured",
+			expect: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.CallbackOnAnalyticsThisApp = tt.callback
+			app := fiber.New()
+			app.Post("/meeting", CreateMeeting(conf, server.Client(), event.NewBus(), store.New(store.NewMemory())))
+
+			req := httptest.NewRequest(fiber.MethodPost, "/meeting", bytes.NewBufferString(sampleRequestBody[0]))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+			if tt.expect == "" {
+				assert.NotContains(t, query, "meta_analytics-callback-url")
+				return
+			}
+			assert.Contains(t, query, tt.expect)
+		})
+	}
+}
+
+func TestNamespacedMeetingId(t *testing.T) {
+	testCases := []struct {
+		name      string
+		namespace string
+		client    string
+		id        string
+		expect    string
+	}{
+		{
+			name:      "Meeting ID w/o namespace should not be changed",
+			namespace: config.NamespaceNone,
+			client:    "lms",
+			id:        "math101",
+			expect:    "math101",
+		},
+		{
+			name:      "Meeting ID w prefix namespace should be prefixed w the client name",
+			namespace: config.NamespacePrefix,
+			client:    "lms",
+			id:        "math101",
+			expect:    "lms-math101",
+		},
+		{
+			name:      "Meeting ID that is already prefixed should not be prefixed again",
+			namespace: config.NamespacePrefix,
+			client:    "lms",
+			id:        "lms-math101",
+			expect:    "lms-math101",
+		},
+		{
+			name:      "Meeting ID w hash namespace should be SHA1 of the client name and the ID w its check",
+			namespace: config.NamespaceHash,
+			client:    "lms",
+			id:        "math101",
+			expect:    "eafb4dc98ade5052a9c839d18e220276d01e2c4c",
+		},
+		{
+			name:      "Meeting ID that is already hashed should not be hashed again",
+			namespace: config.NamespaceHash,
+			client:    "lms",
+			id:        "eafb4dc98ade5052a9c839d18e220276d01e2c4c",
+			expect:    "eafb4dc98ade5052a9c839d18e220276d01e2c4c",
+		},
+		{
+			name:      "Meeting ID of the main token should not be namespaced",
+			namespace: config.NamespacePrefix,
+			id:        "math101",
+			expect:    "math101",
+		},
+		{
+			name:      "Empty meeting ID should stay empty so it's generated",
+			namespace: config.NamespaceHash,
+			client:    "lms",
+			expect:    "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &config.Model{MeetingIdNamespace: tt.namespace}
+			assert.Equal(t, tt.expect, namespacedMeetingId(conf, tt.client, tt.id))
+		})
+	}
+}
+
+func TestNamespacedMeetingId_RoundTrip(t *testing.T) {
+	for _, namespace := range []string{config.NamespacePrefix, config.NamespaceHash} {
+		t.Run(namespace, func(t *testing.T) {
+			conf := &config.Model{MeetingIdNamespace: namespace}
+			for _, id := range []string{"math101", "lms-math101", "eafb4dc98ade5052a9c839d18e220276423dbba1"} {
+				got := namespacedMeetingId(conf, "lms", id)
+				assert.Equal(t, got, namespacedMeetingId(conf, "lms", got), "ID from /create should be the same when sent back")
+				assert.NotEqual(t, got, namespacedMeetingId(conf, "hr", got), "ID of other client should be namespaced again")
+			}
+		})
+	}
+}
+
+func TestNamespacedMeetingId_Collision(t *testing.T) {
+	clients := []string{"a", "ab", "b", "a_b"}
+	ids := []string{"x", "b-x", "a-b-x", "ab-x", "-x", "b/x", "a/b/x", "b_x"}
+
+	for _, namespace := range []string{config.NamespacePrefix, config.NamespaceHash} {
+		t.Run(namespace, func(t *testing.T) {
+			conf := &config.Model{MeetingIdNamespace: namespace}
+			for _, cl := range clients {
+				conf.Clients = append(conf.Clients, config.Client{Name: cl})
+			}
+			require.NoError(t, conf.Sanitization())
+
+			owners := make(map[string]string)
+			for _, cl := range clients {
+				for _, id := range ids {
+					got := namespacedMeetingId(conf, cl, id)
+					if owner, ok := owners[got]; ok {
+						assert.Equal(t, owner, cl, "meeting ID %s of client %s is the same as one of client %s", got, cl, owner)
+					}
+					owners[got] = cl
+				}
+			}
+		})
+	}
+}
+
+func TestCreateMeeting_MeetingIdCollision(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	conf.MeetingIdNamespace = config.NamespacePrefix
+	require.NoError(t, conf.Sanitization())
+
+	// meetings in BBB server which were not created through this app.
+	var mu sync.Mutex
+	var sent []string
+	rejectFirst := false
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		id := req.URL.Query().Get("meetingID")
+		mu.Lock()
+		sent = append(sent, id)
+		reject := rejectFirst && len(sent) == 1
+		mu.Unlock()
+
+		resp := api.CreateMeetingResponse{
+			StdResponse: api.StdResponse{CodeString: "SUCCESS"},
+			MeetingId:   id,
+			CreateTime:  "121212",
+		}
+		switch {
+		case reject, id == "lms-taken":
+			resp = api.CreateMeetingResponse{StdResponse: api.StdResponse{CodeString: "FAILED", MsgKey: "idNotUnique"}}
+		case id == "lms-outside", id == "lms-own":
+			resp.MsgKey = "duplicateWarning"
+		}
+		xm, err := xml.Marshal(&resp)
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "lms-own", Client: "lms", CreateTime: 121212}))
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "hr-math", Client: "hr", CreateTime: 121212}))
+
+	app := fiber.New()
+	app.Post("/meeting", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	}, CreateMeeting(conf, server.Client(), event.NewBus(), st))
+
+	testCases := []struct {
+		name      string
+		namespace string
+		body      string
+		expect    int
+		expectId  string
+	}{
+		{
+			name:     "Meeting ID should be prefixed w the client name",
+			body:     `{"name":"Math","MeetingId":"math"}`,
+			expect:   fiber.StatusCreated,
+			expectId: "lms-math",
+		},
+		{
+			name:     "Meeting that this client has created should be created again",
+			body:     `{"name":"Math","MeetingId":"own"}`,
+			expect:   fiber.StatusCreated,
+			expectId: "lms-own",
+		},
+		{
+			name:     "Meeting ID of other client should be prefixed w the client name instead of being reused",
+			body:     `{"name":"Math","MeetingId":"hr-math"}`,
+			expect:   fiber.StatusCreated,
+			expectId: "lms-hr-math",
+		},
+		{
+			name:      "Meeting ID of other client w/o namespace should be rejected",
+			namespace: config.NamespaceNone,
+			body:      `{"name":"Math","MeetingId":"hr-math"}`,
+			expect:    fiber.StatusConflict,
+		},
+		{
+			name:   "Meeting ID that BBB server says is not unique should be rejected",
+			body:   `{"name":"Math","MeetingId":"taken"}`,
+			expect: fiber.StatusConflict,
+		},
+		{
+			name:   "Meeting that was created outside this app should be rejected",
+			body:   `{"name":"Math","MeetingId":"outside"}`,
+			expect: fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.MeetingIdNamespace = config.NamespacePrefix
+			if tt.namespace != "" {
+				conf.MeetingIdNamespace = tt.namespace
+			}
+
+			req := httptest.NewRequest(fiber.MethodPost, "/meeting", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, res.StatusCode)
+			if tt.expectId == "" {
+				return
+			}
+
+			var resp api.CreateMeetingResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+			assert.Equal(t, tt.expectId, resp.MeetingId)
+			m, err := st.Meeting(tt.expectId)
+			require.NoError(t, err)
+			assert.Equal(t, "lms", m.Client)
+		})
+	}
+
+	t.Run("Generated meeting ID that is already used should be generated again", func(t *testing.T) {
+		conf.MeetingIdFormat = service.IdULID
+		defer func() { conf.MeetingIdFormat = service.IdLetters }()
+		mu.Lock()
+		sent, rejectFirst = nil, true
+		mu.Unlock()
+
+		res, err := createMeeting(conf, server.Client(), event.NewBus(), st, api.CreateMeeting{Name: "Math"}, "lms")
+		require.NoError(t, err)
+		assert.Regexp(t, `^[0-9A-Z]{26}$`, res.MeetingId)
+		require.Len(t, sent, 2)
+		assert.NotEqual(t, sent[0], sent[1])
+		assert.Equal(t, sent[1], res.MeetingId)
+	})
+}
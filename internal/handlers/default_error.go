@@ -22,3 +22,17 @@ func DefaultError(ctx *fiber.Ctx, err error) error {
 
 	return ctx.Status(fibErr.Code).JSON(data)
 }
+
+// sendError send the given error as json message with its status code if it's
+// *fiber.Error, otherwise as internal server error.
+func sendError(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+	if fibErr, ok := err.(*fiber.Error); ok {
+		code = fibErr.Code
+	}
+
+	c.Status(code)
+	return c.JSON(fiber.Map{
+		"message": err.Error(),
+	})
+}
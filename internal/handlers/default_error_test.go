@@ -53,3 +53,36 @@ func TestDefaultError(t *testing.T) {
 		})
 	}
 }
+
+func TestSendError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{
+			name:         "fiber error should be sent with its status code",
+			err:          fiber.NewError(fiber.StatusConflict, "conflict"),
+			expectedCode: fiber.StatusConflict,
+		},
+		{
+			name:         "Other error should be sent as internal server error",
+			err:          errors.New("failed"),
+			expectedCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return sendError(c, tt.err)
+			})
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			assert.Equal(t, fiber.MIMEApplicationJSON, res.Header.Get("Content-Type"))
+		})
+	}
+}
@@ -10,13 +10,16 @@ import (
 	"github.com/kurvaid/bbb-interface/internal/client"
 	"github.com/kurvaid/bbb-interface/internal/config"
 	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
 )
 
 // EndMeeting handler that receive json request to end a meeting from client and transform it to xml
 // request that match BBB API requirement and transform xml response to json before send it back to
-// the client.
-func EndMeeting(conf *config.Model, hCl *http.Client) func(*fiber.Ctx) error {
+// the client. Clients could only end the meetings they created.
+func EndMeeting(conf *config.Model, hCl *http.Client, st *store.Store) func(*fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
+		hCl := requestClient(c, hCl)
+
 		// bind incoming json request to predefined object.
 		var eMeet api.EndMeeting
 		if err := c.BodyParser(&eMeet); err != nil {
@@ -26,6 +29,10 @@ func EndMeeting(conf *config.Model, hCl *http.Client) func(*fiber.Ctx) error {
 			})
 		}
 
+		if err := checkMeetingOwner(c, st, eMeet.MeetingId); err != nil {
+			return sendError(c, err)
+		}
+
 		uri, err := eMeet.ParseEndMeeting()
 		if err != nil {
 			c.Status(fiber.StatusBadRequest)
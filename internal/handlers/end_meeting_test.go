@@ -12,6 +12,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/api"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
 	"github.com/stretchr/testify/require"
 )
 
@@ -84,7 +86,7 @@ func TestEndMeeting(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/end", EndMeeting(conf, fakeSuccessEndMeetServer(t).Client()))
+	app.Post("/end", EndMeeting(conf, fakeSuccessEndMeetServer(t).Client(), store.New(store.NewMemory())))
 
 	t.Run("Success if all required fields are provided", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleEndRequest[2])
@@ -111,7 +113,7 @@ func TestEndMeeting_VariousErrorPaths(t *testing.T) {
 	require.NoError(t, conf.Sanitization())
 
 	app := fiber.New()
-	app.Post("/end", EndMeeting(conf, fakeEndMeetServer(t).Client()))
+	app.Post("/end", EndMeeting(conf, fakeEndMeetServer(t).Client(), store.New(store.NewMemory())))
 
 	t.Run("Failed if `meeting_id` field is not provided", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleEndRequest[0])
@@ -184,7 +186,7 @@ func TestEndMeeting_ErrorPathCausedByBBB(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/end", EndMeeting(conf, fakeEndMeet(t).Client()))
+	app.Post("/end", EndMeeting(conf, fakeEndMeet(t).Client(), store.New(store.NewMemory())))
 
 	t.Run("Failed if BBB API send back response that has any other than XML content type", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleEndRequest[2])
@@ -206,7 +208,7 @@ func TestEndMeeting_ErrorPathCausedByBBBGiveNonSuccessResponse(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/end", EndMeeting(conf, fakeEndMeetServer(t).Client()))
+	app.Post("/end", EndMeeting(conf, fakeEndMeetServer(t).Client(), store.New(store.NewMemory())))
 
 	t.Run("Failed if BBB API send back non SUCCESS response", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleEndRequest[2])
@@ -226,3 +228,51 @@ func TestEndMeeting_ErrorPathCausedByBBBGiveNonSuccessResponse(t *testing.T) {
 		assert.Contains(t, jsRes.Message, sampleEndMeetStdResponse[0].MsgKey)
 	})
 }
+
+func TestEndMeeting_Owner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		xm, err := xml.Marshal(&sampleEndMeetStdResponse[1])
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Client: "lms", CreateTime: 1000}))
+
+	app := fiber.New()
+	app.Post("/end", EndMeeting(conf, server.Client(), st))
+	app.Post("/:client/end", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, c.Params("client"))
+		return c.Next()
+	}, EndMeeting(conf, server.Client(), st))
+
+	testCases := []struct {
+		name   string
+		uri    string
+		body   string
+		status int
+	}{
+		{name: "Client should end its own meeting", uri: "/lms/end", body: `{"meeting_id": "meet01", "password": "mdr"}`, status: fiber.StatusOK},
+		{name: "Client should not end meeting of other client", uri: "/hr/end", body: `{"meeting_id": "meet01", "password": "mdr"}`, status: fiber.StatusNotFound},
+		{name: "Client should not end meeting that isn't recorded", uri: "/lms/end", body: `{"meeting_id": "meet02", "password": "mdr"}`, status: fiber.StatusNotFound},
+		{name: "Main token should end every meeting", uri: "/end", body: `{"meeting_id": "meet02", "password": "mdr"}`, status: fiber.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, tt.uri, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
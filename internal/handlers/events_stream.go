@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+)
+
+// heartbeatInterval how often a comment is sent to keep idle streams open and to
+// detect disconnected requesters.
+var heartbeatInterval = 15 * time.Second
+
+// EventStream handler that stream meeting events from the bus to the requester as
+// Server-Sent Events. Events could be filtered using `meeting_id` and `client` query.
+// Requester that authenticated as a client would only receive events of its own.
+func EventStream(bus *event.Bus) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var filters []event.Filter
+		if id := c.Query("meeting_id"); id != "" {
+			filters = append(filters, event.ByMeeting(id))
+		}
+
+		client := c.Query("client")
+		if own := middlewares.Client(c); own != "" {
+			client = own
+		}
+		if client != "" {
+			filters = append(filters, event.ByClient(client))
+		}
+
+		ch, unsubscribe := bus.Subscribe(64, event.All(filters...))
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		// prevent Nginx from buffering the stream.
+		c.Set("X-Accel-Buffering", "no")
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer unsubscribe()
+
+			ticker := time.NewTicker(heartbeatInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case e, ok := <-ch:
+					if !ok {
+						return
+					}
+
+					data, err := json.Marshal(&e)
+					if err != nil {
+						continue
+					}
+					fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+				case <-ticker.C:
+					fmt.Fprint(w, ": heartbeat\n\n")
+				}
+
+				// requester has gone if flushing failed.
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		})
+
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamEvents request the given uri then publish the given events after the stream
+// subscribed to the bus. Return the whole stream after the bus was closed.
+func streamEvents(t *testing.T, app *fiber.App, bus *event.Bus, uri string, events ...event.Event) string {
+	go func() {
+		// wait for the stream to subscribe before publishing.
+		time.Sleep(100 * time.Millisecond)
+		for _, e := range events {
+			bus.Publish(e)
+		}
+		bus.Close()
+	}()
+
+	req := httptest.NewRequest(fiber.MethodGet, uri, nil)
+	res, err := app.Test(req, 2000)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestEventStream(t *testing.T) {
+	t.Run("Should only stream events of the given meeting id", func(t *testing.T) {
+		bus := event.NewBus()
+		app := fiber.New()
+		app.Get("/events", EventStream(bus))
+
+		out := streamEvents(t, app, bus, "/events?meeting_id=meet01",
+			event.Event{Type: event.MeetingCreated, MeetingId: "meet02"},
+			event.Event{Type: event.MeetingCreated, MeetingId: "meet01"},
+		)
+		assert.Contains(t, out, "event: meeting-created\ndata: {\"type\":\"meeting-created\",\"meeting_id\":\"meet01\"")
+		assert.NotContains(t, out, "meet02")
+	})
+
+	t.Run("Authenticated client should only receive events of its own", func(t *testing.T) {
+		bus := event.NewBus()
+		app := fiber.New()
+		app.Get("/events",
+			func(c *fiber.Ctx) error {
+				c.Locals(middlewares.ClientKey, "lms")
+				return c.Next()
+			},
+			EventStream(bus),
+		)
+
+		out := streamEvents(t, app, bus, "/events?client=dashboard",
+			event.Event{Type: event.MeetingCreated, MeetingId: "meet01", Client: "dashboard"},
+			event.Event{Type: event.MeetingCreated, MeetingId: "meet02", Client: "lms"},
+		)
+		assert.Contains(t, out, "meet02")
+		assert.NotContains(t, out, "meet01")
+	})
+}
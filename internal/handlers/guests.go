@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// guestIdLen length of the ID of guests, which is the secret in the link the guest wait on.
+const guestIdLen = 32
+
+// guestCookie cookie that bind the link of a guest to the browser that opened it first, so the
+// link could not be used by someone else once the guest is approved.
+const guestCookie = "bbb_guest"
+
+// GuestRequest format to ask to join a meeting as guest.
+type GuestRequest struct {
+	MeetingId string `json:"meeting_id"`
+	Name      string `json:"name"`
+	UserId    string `json:"user_id"`
+	Avatar    string `json:"avatar"`
+}
+
+// GuestResponse a guest along with the link that the guest wait on.
+type GuestResponse struct {
+	store.Guest
+	Url string `json:"url"`
+}
+
+// CreateGuest handler that put a guest in the queue of the meeting in json request. Guests of
+// meetings with ASK_MODERATOR guest policy wait until the staff approve them, the others are
+// approved right away, unless the policy is ALWAYS_DENY.
+func CreateGuest(conf *config.Model, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var req GuestRequest
+		if err := c.BodyParser(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to guest object: %s", err),
+			})
+		}
+
+		switch {
+		case req.MeetingId == "":
+			return sendError(c, fiber.NewError(fiber.StatusBadRequest, "`meeting_id` is required"))
+		case req.Name == "":
+			return sendError(c, fiber.NewError(fiber.StatusBadRequest, "`name` is required"))
+		}
+
+		m, err := clientMeeting(c, st, req.MeetingId)
+		if err != nil {
+			return sendError(c, guestMeetingError(err))
+		}
+
+		randId := service.SecureString{Length: guestIdLen}
+		g := store.Guest{
+			Id:        randId.RandString(),
+			MeetingId: m.MeetingId,
+			Client:    m.Client,
+			Name:      req.Name,
+			UserId:    req.UserId,
+			Avatar:    req.Avatar,
+			Status:    store.GuestApproved,
+			CreatedAt: time.Now(),
+		}
+		switch m.Settings.GuestPolicy {
+		case api.GuestAlwaysDeny:
+			return sendError(c, fiber.NewError(fiber.StatusForbidden, "meeting doesn't accept guests"))
+		case api.GuestAskModerator:
+			g.Status = store.GuestWaiting
+		default:
+			g.DecidedAt = &g.CreatedAt
+		}
+
+		if err := st.SaveGuest(g); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to save guest: %s", err),
+			})
+		}
+		if g.Status == store.GuestWaiting {
+			publishGuest(c, bus, event.GuestWaiting, g)
+		}
+
+		c.Status(fiber.StatusCreated)
+		return c.JSON(newGuestResponse(conf, g))
+	}
+}
+
+// ListGuests handler that send the guests of the meeting with ID in `id` param in the order they
+// asked to join. Only the waiting ones are sent, unless `status` query is given, `all` for every
+// status.
+func ListGuests(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		status := c.Query("status", store.GuestWaiting)
+		switch status {
+		case store.GuestWaiting, store.GuestApproved, store.GuestDenied:
+		case "all":
+			status = ""
+		default:
+			return sendError(c, fiber.NewError(fiber.StatusBadRequest, "`status` must be one of waiting, approved, denied or all"))
+		}
+
+		m, err := clientMeeting(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, guestMeetingError(err))
+		}
+
+		guests, err := st.Guests(m.MeetingId, status)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get guests: %s", err),
+			})
+		}
+
+		resp := make([]GuestResponse, 0, len(guests))
+		for _, g := range guests {
+			resp = append(resp, newGuestResponse(conf, g))
+		}
+
+		return c.JSON(resp)
+	}
+}
+
+// DecideGuest handler that approve or deny, as the given status, the waiting guest with ID in
+// `id` param.
+func DecideGuest(conf *config.Model, bus *event.Bus, st *store.Store, status string) func(*fiber.Ctx) error {
+	typ := event.GuestApproved
+	if status == store.GuestDenied {
+		typ = event.GuestDenied
+	}
+
+	return func(c *fiber.Ctx) error {
+		g, err := st.Guest(c.Params("id"))
+		if own := middlewares.Client(c); err == nil && own != "" && g.Client != own {
+			err = store.ErrNotFound
+		}
+		if err == nil {
+			g, err = st.DecideGuest(g.Id, status, time.Now().Truncate(time.Second))
+		}
+		if err != nil {
+			return sendError(c, guestError(err))
+		}
+
+		middlewares.SetMeeting(c, g.MeetingId)
+		publishGuest(c, bus, typ, g)
+		return c.JSON(newGuestResponse(conf, g))
+	}
+}
+
+// WaitGuest handler that hold the guest with ID in `id` param on a waiting page until the staff
+// approve it, then redirect the browser to BBB join url once the meeting is created. Approved
+// guests join as attendee, so they don't wait again in the guest lobby of BBB. Only the browser
+// that opened the link first could use it.
+func WaitGuest(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		g, err := st.Guest(c.Params("id"))
+		if err != nil {
+			return sendError(c, guestError(err))
+		}
+		middlewares.SetMeeting(c, g.MeetingId)
+
+		if err := claimGuest(c, conf, st, g); err == store.ErrGuestClaimed {
+			c.Status(fiber.StatusForbidden)
+			return sendPage(c, st, g.MeetingId, lobbyData{
+				Title:   "Link is in use",
+				Message: "This link has been opened in another browser. Ask the organizer for a new one.",
+			})
+		} else if err != nil {
+			return sendError(c, guestError(err))
+		}
+
+		switch g.Status {
+		case store.GuestWaiting:
+			return sendPage(c, st, g.MeetingId, lobbyData{
+				Title:   "Waiting for approval",
+				Message: fmt.Sprintf("Hi %s, you will join automatically once you are let in, keep this page open.", g.Name),
+				Refresh: conf.LobbyRefresh,
+			})
+		case store.GuestDenied:
+			c.Status(fiber.StatusForbidden)
+			return sendPage(c, st, g.MeetingId, lobbyData{
+				Title:   "Request denied",
+				Message: fmt.Sprintf("Hi %s, your request to join the meeting has been denied.", g.Name),
+			})
+		}
+
+		m, err := st.Meeting(g.MeetingId)
+		if err != nil && err != store.ErrNotFound {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get meeting: %s", err),
+			})
+		}
+		if err == store.ErrNotFound || m.Ended() || m.CreateTime == 0 {
+			return sendLobby(c, st, api.JoinMeeting{Name: g.Name, MeetingId: g.MeetingId}, conf.LobbyRefresh)
+		}
+
+		jMeet := api.JoinMeeting{
+			Name:       g.Name,
+			MeetingId:  m.MeetingId,
+			CreateTime: strconv.FormatInt(m.CreateTime, 10),
+			UserId:     g.UserId,
+			Avatar:     g.Avatar,
+		}
+		joinWithRole(conf, &jMeet, false, m.ModeratorPass, m.AttendeePass)
+
+		url, err := joinUrl(conf, jMeet)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
+			})
+		}
+
+		return c.Redirect(url, fiber.StatusFound)
+	}
+}
+
+// claimGuest bind the guest to the browser that made the request if it's the first to open the
+// link, by setting a cookie w a random key. Return store.ErrGuestClaimed if the browser doesn't
+// have the key of the guest.
+func claimGuest(c *fiber.Ctx, conf *config.Model, st *store.Store, g store.Guest) error {
+	if g.Browser != "" {
+		if key := c.Cookies(guestCookie); key == "" || service.SHA1Hash(key) != g.Browser {
+			return store.ErrGuestClaimed
+		}
+		return nil
+	}
+
+	randKey := service.SecureString{Length: guestIdLen}
+	key := randKey.RandString()
+	if _, err := st.ClaimGuest(g.Id, service.SHA1Hash(key)); err != nil {
+		return err
+	}
+
+	// guests are forgotten a day after they asked to join.
+	c.Cookie(&fiber.Cookie{
+		Name:     guestCookie,
+		Value:    key,
+		Path:     "/g/" + g.Id,
+		MaxAge:   int((24 * time.Hour).Seconds()),
+		Secure:   strings.HasPrefix(conf.PublicUrl, "https://"),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// newGuestResponse return the guest as response, w/o the browser it's bound to.
+func newGuestResponse(conf *config.Model, g store.Guest) GuestResponse {
+	g.Browser = ""
+	return GuestResponse{Guest: g, Url: fmt.Sprintf("%s/g/%s", conf.PublicUrl, g.Id)}
+}
+
+// publishGuest publish event of the given type about the guest, so the staff know who to screen.
+func publishGuest(c *fiber.Ctx, bus *event.Bus, typ string, g store.Guest) {
+	bus.Publish(event.Event{
+		Type:      typ,
+		MeetingId: g.MeetingId,
+		Client:    g.Client,
+		User:      &event.User{Id: g.UserId, Name: g.Name, IsGuest: true},
+		RequestId: middlewares.RequestId(c),
+	})
+}
+
+// guestMeetingError return *fiber.Error of the error from getting the meeting of guests.
+func guestMeetingError(err error) error {
+	if err == store.ErrNotFound {
+		return fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+	}
+
+	return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+}
+
+// guestError return *fiber.Error of the error from getting or deciding a guest.
+func guestError(err error) error {
+	switch err {
+	case store.ErrNotFound:
+		return fiber.NewError(fiber.StatusNotFound, "guest is not found")
+	case store.ErrGuestDecided:
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+
+	return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get guest: %s", err))
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuests(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+
+	st := store.New(store.NewMemory())
+	for _, m := range []store.Meeting{
+		{MeetingId: "webinar", Name: "Webinar", Client: "lms", AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 121212, Settings: api.CreateMeeting{GuestPolicy: api.GuestAskModerator}},
+		{MeetingId: "open", Client: "lms", CreateTime: 121212},
+		{MeetingId: "class", Client: "lms", CreateTime: 121212},
+		{MeetingId: "closed", Client: "lms", CreateTime: 121212, Settings: api.CreateMeeting{GuestPolicy: api.GuestAlwaysDeny}},
+		{MeetingId: "other", Client: "hr", CreateTime: 121212, Settings: api.CreateMeeting{GuestPolicy: api.GuestAskModerator}},
+	} {
+		require.NoError(t, st.SaveMeeting(m))
+	}
+
+	bus := event.NewBus()
+	events, cancel := bus.Subscribe(10, nil)
+	defer cancel()
+
+	asClient := func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	}
+	app := fiber.New()
+	app.Post("/guests", asClient, CreateGuest(conf, bus, st))
+	app.Post("/guests/:id/approve", asClient, DecideGuest(conf, bus, st, store.GuestApproved))
+	app.Post("/guests/:id/deny", asClient, DecideGuest(conf, bus, st, store.GuestDenied))
+	app.Get("/meetings/:id/guests", asClient, ListGuests(conf, st))
+	app.Get("/g/:id", WaitGuest(conf, st))
+
+	request := func(t *testing.T, method, target, body string, v interface{}) *http.Response {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		if v != nil && res.StatusCode < 300 {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(v))
+		}
+		return res
+	}
+
+	// open the link of the guest in the browser that has the given key, if any.
+	open := func(t *testing.T, id, key string) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, "/g/"+id, nil)
+		if key != "" {
+			req.AddCookie(&http.Cookie{Name: guestCookie, Value: key})
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res
+	}
+
+	var mhs, tamu GuestResponse
+	var browser string
+	t.Run("Guests of ASK_MODERATOR meeting should wait", func(t *testing.T) {
+		res := request(t, fiber.MethodPost, "/guests", `{"meeting_id": "webinar", "name": "Mahasiswa", "user_id": "g01"}`, &mhs)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+		assert.Equal(t, store.GuestWaiting, mhs.Status)
+		assert.Equal(t, conf.PublicUrl+"/g/"+mhs.Id, mhs.Url)
+
+		select {
+		case e := <-events:
+			assert.Equal(t, event.GuestWaiting, e.Type)
+			assert.Equal(t, "lms", e.Client)
+			assert.Equal(t, "Mahasiswa", e.User.Name)
+		case <-time.After(time.Second):
+			t.Fatal("guest-waiting should be published")
+		}
+
+		res = request(t, fiber.MethodPost, "/guests", `{"meeting_id": "webinar", "name": "Tamu"}`, &tamu)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+		<-events
+
+		res = open(t, mhs.Id, "")
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Equal(t, fiber.MIMETextHTMLCharsetUTF8, res.Header.Get(fiber.HeaderContentType))
+
+		cookies := res.Cookies()
+		require.Len(t, cookies, 1, "the browser that opened the link first should get a key")
+		assert.Equal(t, "/g/"+mhs.Id, cookies[0].Path)
+		assert.True(t, cookies[0].HttpOnly)
+		browser = cookies[0].Value
+		assert.Equal(t, fiber.StatusOK, open(t, mhs.Id, browser).StatusCode)
+	})
+
+	t.Run("Waiting guests should be listed in the order they asked", func(t *testing.T) {
+		var guests []GuestResponse
+		res := request(t, fiber.MethodGet, "/meetings/webinar/guests", "", &guests)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+		require.Len(t, guests, 2)
+		assert.Equal(t, mhs.Id, guests[0].Id)
+		assert.Equal(t, tamu.Id, guests[1].Id)
+	})
+
+	t.Run("Approved guest should be redirected to BBB as attendee", func(t *testing.T) {
+		var g GuestResponse
+		res := request(t, fiber.MethodPost, "/guests/"+mhs.Id+"/approve", "", &g)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Equal(t, store.GuestApproved, g.Status)
+		assert.Equal(t, event.GuestApproved, (<-events).Type)
+
+		res = open(t, mhs.Id, browser)
+		require.Equal(t, fiber.StatusFound, res.StatusCode)
+		location := res.Header.Get(fiber.HeaderLocation)
+		assert.Contains(t, location, "password=att")
+		assert.NotContains(t, location, "guest=true")
+
+		res = request(t, fiber.MethodPost, "/guests/"+mhs.Id+"/deny", "", nil)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode, "decided guest should not be decided again")
+	})
+
+	t.Run("Link of approved guest should not be used by another browser", func(t *testing.T) {
+		for _, key := range []string{"", "otherBrowser"} {
+			res := open(t, mhs.Id, key)
+			assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+			assert.Empty(t, res.Header.Get(fiber.HeaderLocation))
+			assert.Empty(t, res.Cookies(), "the key should not be given again")
+		}
+
+		var guests []GuestResponse
+		request(t, fiber.MethodGet, "/meetings/webinar/guests?status=approved", "", &guests)
+		require.Len(t, guests, 1)
+		assert.Equal(t, mhs.Id, guests[0].Id)
+		assert.Empty(t, guests[0].Browser, "the browser should not be sent to the client")
+	})
+
+	t.Run("Denied guest should not join", func(t *testing.T) {
+		res := request(t, fiber.MethodPost, "/guests/"+tamu.Id+"/deny", "", nil)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Equal(t, event.GuestDenied, (<-events).Type)
+
+		res = open(t, tamu.Id, "")
+		assert.Equal(t, fiber.StatusForbidden, res.StatusCode)
+
+		var guests []GuestResponse
+		request(t, fiber.MethodGet, "/meetings/webinar/guests?status=all", "", &guests)
+		assert.Len(t, guests, 2)
+	})
+
+	t.Run("Guests of meeting w/o policy should be approved right away", func(t *testing.T) {
+		var g GuestResponse
+		res := request(t, fiber.MethodPost, "/guests", `{"meeting_id": "open", "name": "Tamu"}`, &g)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+		assert.Equal(t, store.GuestApproved, g.Status)
+	})
+
+	t.Run("Guests who asked within the same second should be listed in order", func(t *testing.T) {
+		var ids []string
+		for i := 0; i < 8; i++ {
+			var g GuestResponse
+			res := request(t, fiber.MethodPost, "/guests", `{"meeting_id": "class", "name": "Tamu"}`, &g)
+			require.Equal(t, fiber.StatusCreated, res.StatusCode)
+			ids = append(ids, g.Id)
+		}
+
+		var guests []GuestResponse
+		request(t, fiber.MethodGet, "/meetings/class/guests?status=all", "", &guests)
+		got := make([]string, 0, len(guests))
+		for _, g := range guests {
+			got = append(got, g.Id)
+		}
+		assert.Equal(t, ids, got)
+	})
+
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		expect int
+	}{
+		{name: "Meeting that denies guests", method: fiber.MethodPost, target: "/guests", body: `{"meeting_id": "closed", "name": "Tamu"}`, expect: fiber.StatusForbidden},
+		{name: "Meeting of other client", method: fiber.MethodPost, target: "/guests", body: `{"meeting_id": "other", "name": "Tamu"}`, expect: fiber.StatusNotFound},
+		{name: "Guests of other client's meeting", method: fiber.MethodGet, target: "/meetings/other/guests", expect: fiber.StatusNotFound},
+		{name: "Guest w/o name", method: fiber.MethodPost, target: "/guests", body: `{"meeting_id": "webinar"}`, expect: fiber.StatusBadRequest},
+		{name: "Unknown status", method: fiber.MethodGet, target: "/meetings/webinar/guests?status=left", expect: fiber.StatusBadRequest},
+		{name: "Unknown guest", method: fiber.MethodPost, target: "/guests/unknown/approve", expect: fiber.StatusNotFound},
+		{name: "Waiting page of unknown guest", method: fiber.MethodGet, target: "/g/unknown", expect: fiber.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res := request(t, tt.method, tt.target, tt.body, nil)
+			assert.Equal(t, tt.expect, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// HistoryEntry a meeting in the history response.
+type HistoryEntry struct {
+	MeetingId         string     `json:"meeting_id"`
+	InternalMeetingId string     `json:"internal_meeting_id,omitempty"`
+	Name              string     `json:"name"`
+	Client            string     `json:"client,omitempty"`
+	SeriesId          string     `json:"series_id,omitempty"`
+	ParentId          string     `json:"parent_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	StartedAt         *time.Time `json:"started_at,omitempty"`
+	EndedAt           *time.Time `json:"ended_at,omitempty"`
+	Running           bool       `json:"running"`
+	Duration          int64      `json:"duration"` // In seconds, since the first user joined.
+	ParticipantPeak   int        `json:"participant_peak"`
+	Recorded          bool       `json:"recorded"`
+	RecordIds         []string   `json:"record_ids,omitempty"`
+}
+
+// HistoryResponse a page of meeting history.
+type HistoryResponse struct {
+	Meetings   []HistoryEntry `json:"meetings"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Empty if this is the last page.
+}
+
+// History handler that search recorded meetings using the query filters, the most recently
+// created first. Requester that authenticated as a client would only get its own meetings.
+func History(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		q, err := parseHistoryQuery(c)
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse history query: %s", err),
+			})
+		}
+
+		meets, next, err := st.History(q)
+		if err == store.ErrInvalidCursor {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse history query: %s", err),
+			})
+		}
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to search meeting history: %s", err),
+			})
+		}
+
+		now := time.Now()
+		resp := HistoryResponse{Meetings: make([]HistoryEntry, 0, len(meets)), NextCursor: next}
+		for _, m := range meets {
+			resp.Meetings = append(resp.Meetings, HistoryEntry{
+				MeetingId:         m.MeetingId,
+				InternalMeetingId: m.InternalMeetingId,
+				Name:              m.Name,
+				Client:            m.Client,
+				SeriesId:          m.SeriesId,
+				ParentId:          m.ParentId,
+				CreatedAt:         m.CreatedAt,
+				StartedAt:         m.StartedAt,
+				EndedAt:           m.EndedAt,
+				Running:           m.Running(),
+				Duration:          int64(m.Duration(now) / time.Second),
+				ParticipantPeak:   m.ParticipantPeak,
+				Recorded:          len(m.RecordIds) > 0,
+				RecordIds:         m.RecordIds,
+			})
+		}
+
+		return c.JSON(resp)
+	}
+}
+
+// parseHistoryQuery bind the query of the request to history query.
+func parseHistoryQuery(c *fiber.Ctx) (q store.HistoryQuery, err error) {
+	q.Client = c.Query("client")
+	if own := middlewares.Client(c); own != "" {
+		q.Client = own
+	}
+	q.SeriesId = c.Query("series")
+	q.ParentId = c.Query("parent")
+	q.Name = c.Query("name")
+	q.Cursor = c.Query("cursor")
+
+	if q.From, err = parseHistoryTime(c.Query("from"), false); err != nil {
+		return q, fmt.Errorf("`from` %s", err)
+	}
+	if q.To, err = parseHistoryTime(c.Query("to"), true); err != nil {
+		return q, fmt.Errorf("`to` %s", err)
+	}
+
+	switch q.Status = c.Query("status"); q.Status {
+	case "", store.StatusRunning, store.StatusEnded:
+	default:
+		return q, fmt.Errorf("`status` must be either running or ended")
+	}
+
+	if recorded := c.Query("recorded"); recorded != "" {
+		rec, err := strconv.ParseBool(recorded)
+		if err != nil {
+			return q, fmt.Errorf("`recorded` must be either true or false")
+		}
+		q.Recorded = &rec
+	}
+
+	q.Limit = defaultHistoryLimit
+	if limit := c.Query("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit < 1 || q.Limit > maxHistoryLimit {
+			return q, fmt.Errorf("`limit` must be between 1 and %d", maxHistoryLimit)
+		}
+	}
+
+	return q, nil
+}
+
+// parseHistoryTime parse time in RFC3339 or date (YYYY-MM-DD) format. Date used as the end of
+// a range include the whole day.
+func parseHistoryTime(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be RFC3339 time or YYYY-MM-DD date")
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, nil
+}
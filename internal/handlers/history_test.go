@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	st := store.New(store.NewMemory())
+	at := func(day int) *time.Time {
+		t := time.Date(2022, 2, day, 10, 0, 0, 0, time.Local)
+		return &t
+	}
+	samples := []store.Meeting{
+		{MeetingId: "meet01", Name: "Math Class", Client: "lms", CreatedAt: *at(1), StartedAt: at(1), EndedAt: at(2), ParticipantPeak: 30, RecordIds: []string{"rec01"}},
+		{MeetingId: "meet02", Name: "Physics Class", Client: "lms", SeriesId: "physics", CreatedAt: *at(3)},
+		{MeetingId: "meet03", Name: "Standup", Client: "hr", CreatedAt: *at(5), StartedAt: at(5)},
+	}
+	for _, m := range samples {
+		require.NoError(t, st.SaveMeeting(m))
+	}
+
+	app := fiber.New()
+	app.Get("/history", History(st))
+	app.Get("/own/history", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "hr")
+		return c.Next()
+	}, History(st))
+
+	request := func(t *testing.T, uri string, status int) (resp HistoryResponse) {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, uri, nil))
+		require.NoError(t, err)
+		require.Equal(t, status, res.StatusCode)
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+		return
+	}
+	ids := func(resp HistoryResponse) (out []string) {
+		for _, m := range resp.Meetings {
+			out = append(out, m.MeetingId)
+		}
+		return
+	}
+
+	testCases := []struct {
+		name   string
+		uri    string
+		expect []string
+	}{
+		{name: "W/o filter should return every meeting, the most recent first", uri: "/history", expect: []string{"meet03", "meet02", "meet01"}},
+		{name: "Filter by client", uri: "/history?client=lms", expect: []string{"meet02", "meet01"}},
+		{name: "Filter by series", uri: "/history?series=physics", expect: []string{"meet02"}},
+		{name: "Filter by name substring is case-insensitive", uri: "/history?name=class", expect: []string{"meet02", "meet01"}},
+		{name: "Filter by date range include the whole end date", uri: "/history?from=2022-02-02&to=2022-02-03", expect: []string{"meet02"}},
+		{name: "Filter by ended status", uri: "/history?status=ended", expect: []string{"meet01"}},
+		{name: "Filter by running status", uri: "/history?status=running", expect: []string{"meet03"}},
+		{name: "Filter by recorded", uri: "/history?recorded=false", expect: []string{"meet03", "meet02"}},
+		{name: "Client should only get its own meetings", uri: "/own/history?client=lms", expect: []string{"meet03"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, ids(request(t, tt.uri, fiber.StatusOK)))
+		})
+	}
+
+	t.Run("Should return duration and participant peak", func(t *testing.T) {
+		resp := request(t, "/history?client=lms&status=ended", fiber.StatusOK)
+		require.Len(t, resp.Meetings, 1)
+		assert.Equal(t, int64(24*60*60), resp.Meetings[0].Duration)
+		assert.Equal(t, 30, resp.Meetings[0].ParticipantPeak)
+		assert.True(t, resp.Meetings[0].Recorded)
+		assert.False(t, resp.Meetings[0].Running)
+	})
+
+	t.Run("Should paginate using cursor", func(t *testing.T) {
+		var got []string
+		uri := "/history?limit=2"
+		for pages := 0; uri != ""; pages++ {
+			require.Less(t, pages, 3)
+			resp := request(t, uri, fiber.StatusOK)
+			got = append(got, ids(resp)...)
+			uri = ""
+			if resp.NextCursor != "" {
+				uri = "/history?limit=2&cursor=" + resp.NextCursor
+			}
+		}
+		assert.Equal(t, []string{"meet03", "meet02", "meet01"}, got)
+	})
+
+	for _, uri := range []string{
+		"/history?status=unknown",
+		"/history?recorded=maybe",
+		"/history?from=yesterday",
+		"/history?limit=1000",
+		"/history?cursor=!!",
+	} {
+		t.Run("Invalid query should be rejected "+uri, func(t *testing.T) {
+			request(t, uri, fiber.StatusBadRequest)
+		})
+	}
+}
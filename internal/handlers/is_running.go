@@ -9,14 +9,19 @@ import (
 	"github.com/kurvaid/bbb-interface/internal/api"
 	"github.com/kurvaid/bbb-interface/internal/client"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
 	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
 )
 
 // IsRunning handler that receive json request to check whether a meeting is running or not from
 // client and transform it to xml request that match BBB API requirement and transform xml
-// response to json before send it back to the client.
-func IsRunning(conf *config.Model, hCl *http.Client) func(*fiber.Ctx) error {
+// response to json before send it back to the client. The running state would also be
+// observed by the bus. Clients could only check the meetings they created.
+func IsRunning(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
+		hCl := requestClient(c, hCl)
+
 		// bind incoming json request to predefined object.
 		var isRun api.IsRunning
 		if err := c.BodyParser(&isRun); err != nil {
@@ -26,48 +31,56 @@ func IsRunning(conf *config.Model, hCl *http.Client) func(*fiber.Ctx) error {
 			})
 		}
 
-		uri, err := isRun.ParseIsRunning()
-		if err != nil {
-			c.Status(fiber.StatusBadRequest)
-			return c.JSON(fiber.Map{
-				"message": fmt.Sprintf("failed to parse is meeting running url: %s", err),
-			})
+		if err := checkMeetingOwner(c, st, isRun.MeetingId); err != nil {
+			return sendError(c, err)
 		}
 
-		// prepare url and calculate their checksum.
-		out := service.SHA1HashUrl(conf.BBB.Secret, uri)
-		uri = fmt.Sprintf("%s%s%s", conf.BBB.Host, api.EndPoint, uri)
-
-		isRunApi := client.Instance{Cl: hCl, Url: uri, Checksum: out}
-
-		resp, err := isRunApi.DispatchGET()
+		res, err := meetingRunning(conf, hCl, isRun)
 		if err != nil {
-			c.Status(fiber.StatusBadGateway)
-			return c.JSON(fiber.Map{
-				"message": fmt.Sprintf("failed sending is meeting running request to BBB API: %s", err),
-			})
-		}
-
-		var res struct {
-			api.StdResponse
-			Status bool `xml:"running" json:"status"`
-		}
-		if err := xml.Unmarshal(resp, &res); err != nil {
-			c.Status(fiber.StatusInternalServerError)
-			return c.JSON(fiber.Map{
-				"message": fmt.Sprintf("failed parsing BBB API response to std response object: %s", err),
-			})
+			return sendError(c, err)
 		}
 
-		// check if BBB API call success
-		if res.CodeString != "SUCCESS" {
-			c.Status(fiber.StatusBadGateway)
-			return c.JSON(fiber.Map{
-				"message": "something was wrong with BBB API",
-			})
-		}
+		bus.Observe(isRun.MeetingId, res.Status)
 
 		c.Status(fiber.StatusOK)
 		return c.JSON(res)
 	}
 }
+
+// isRunningResponse response of BBB API to check whether a meeting is running.
+type isRunningResponse struct {
+	api.StdResponse
+	Status bool `xml:"running" json:"status"`
+}
+
+// meetingRunning ask BBB API whether the meeting is running. Returned error is *fiber.Error.
+func meetingRunning(conf *config.Model, hCl *http.Client, isRun api.IsRunning) (isRunningResponse, error) {
+	var res isRunningResponse
+
+	uri, err := isRun.ParseIsRunning()
+	if err != nil {
+		return res, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("failed to parse is meeting running url: %s", err))
+	}
+
+	// prepare url and calculate their checksum.
+	out := service.SHA1HashUrl(conf.BBB.Secret, uri)
+	uri = fmt.Sprintf("%s%s%s", conf.BBB.Host, api.EndPoint, uri)
+
+	isRunApi := client.Instance{Cl: hCl, Url: uri, Checksum: out}
+
+	resp, err := isRunApi.DispatchGET()
+	if err != nil {
+		return res, fiber.NewError(fiber.StatusBadGateway, fmt.Sprintf("failed sending is meeting running request to BBB API: %s", err))
+	}
+
+	if err := xml.Unmarshal(resp, &res); err != nil {
+		return res, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed parsing BBB API response to std response object: %s", err))
+	}
+
+	// check if BBB API call success
+	if res.CodeString != "SUCCESS" {
+		return res, fiber.NewError(fiber.StatusBadGateway, "something was wrong with BBB API")
+	}
+
+	return res, nil
+}
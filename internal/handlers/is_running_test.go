@@ -11,6 +11,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/api"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,7 +64,7 @@ func TestIsRunning_VariousErrorPaths(t *testing.T) {
 	require.NoError(t, conf.Sanitization())
 
 	app := fiber.New()
-	app.Post("/is_run", IsRunning(conf, fakeIsRunServer(t).Client()))
+	app.Post("/is_run", IsRunning(conf, fakeIsRunServer(t).Client(), event.NewBus(), store.New(store.NewMemory())))
 
 	t.Run("Failed if `meeting_id` field is not provided", func(t *testing.T) {
 		buf := bytes.NewBufferString(`{"key": "value"}`)
@@ -123,7 +126,7 @@ func TestIsRunning_ErrorPathCausedByBBB(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/is_run", IsRunning(conf, fakeIsRun(t).Client()))
+	app.Post("/is_run", IsRunning(conf, fakeIsRun(t).Client(), event.NewBus(), store.New(store.NewMemory())))
 
 	t.Run("Failed if BBB API send back response that has any other than XML content type", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleIsRunRequest)
@@ -145,7 +148,7 @@ func TestIsRunning_ErrorPathCausedByBBBGiveNonSuccessResponse(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/is_run", IsRunning(conf, fakeIsRunServer(t).Client()))
+	app.Post("/is_run", IsRunning(conf, fakeIsRunServer(t).Client(), event.NewBus(), store.New(store.NewMemory())))
 
 	t.Run("Failed if BBB API send back non SUCCESS response", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleIsRunRequest)
@@ -191,7 +194,7 @@ func TestIsRunning(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/is_run", IsRunning(conf, fakeSuccessIsRunServer(t).Client()))
+	app.Post("/is_run", IsRunning(conf, fakeSuccessIsRunServer(t).Client(), event.NewBus(), store.New(store.NewMemory())))
 
 	t.Run("Success if all required fields are provided", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleIsRunRequest)
@@ -211,3 +214,47 @@ func TestIsRunning(t *testing.T) {
 		assert.Equal(t, true, jsRes.Status)
 	})
 }
+
+func TestIsRunning_Owner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		xm, err := xml.Marshal(&sampleIsRunStdResponse[0])
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Client: "lms", CreateTime: 1000}))
+
+	app := fiber.New()
+	app.Post("/:client/is_run", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, c.Params("client"))
+		return c.Next()
+	}, IsRunning(conf, server.Client(), event.NewBus(), st))
+
+	testCases := []struct {
+		name   string
+		client string
+		status int
+	}{
+		{name: "Client should check its own meeting", client: "lms", status: fiber.StatusOK},
+		{name: "Client should not check meeting of other client", client: "hr", status: fiber.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/"+tt.client+"/is_run", bytes.NewBufferString(sampleIsRunRequest))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// JoinLinkRequest format to request a redirecting join link.
+type JoinLinkRequest struct {
+	api.JoinMeeting
+	ExpiresIn uint32 `json:"expires_in"` // How long (in seconds) the link is valid, up to `join_link_max_ttl` config. Default to `join_link_ttl` config.
+}
+
+// joinLinkClaims claims of the signed token in join link. The token could be read by anyone who
+// has the link, so the password is not in it but resolved from the recorded meeting when the
+// link is opened.
+type joinLinkClaims struct {
+	MeetingId string `json:"meeting_id"`
+	Role      string `json:"role"`
+	Name      string `json:"name"`
+	UserId    string `json:"user_id,omitempty"`
+	Exp       int64  `json:"exp"`
+}
+
+// joinMeeting return join request of the claims, which is filled by roleJoin.
+func (cl joinLinkClaims) joinMeeting() api.JoinMeeting {
+	return api.JoinMeeting{Name: cl.Name, MeetingId: cl.MeetingId, UserId: cl.UserId, Role: cl.Role}
+}
+
+// JoinLink handler that receive the same json request as JoinMeeting and send back a short-lived
+// link of this app that redirect the browser to BBB, so the link could be put in emails and pages
+// as is. The link only carry the meeting ID, role and name of the user, other fields are ignored.
+func JoinLink(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var req JoinLinkRequest
+		if err := c.BodyParser(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to join link object: %s", err),
+			})
+		}
+
+		ttl := req.ExpiresIn
+		if ttl == 0 {
+			ttl = conf.JoinLinkTTL
+		}
+		if ttl > conf.JoinLinkMaxTTL {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("`expires_in` must not be more than %d", conf.JoinLinkMaxTTL),
+			})
+		}
+
+		role, err := joinLinkRole(c, st, req.JoinMeeting)
+		if err != nil {
+			return sendError(c, err)
+		}
+		claims := joinLinkClaims{MeetingId: req.MeetingId, Role: role, Name: req.Name, UserId: req.UserId}
+
+		// check a copy since the password is only filled when the link is opened.
+		jMeet := claims.joinMeeting()
+		if err := roleJoin(c, conf, st, &jMeet); err != nil {
+			return sendError(c, err)
+		}
+		if _, err := jMeet.ParseJoinMeeting(); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
+			})
+		}
+
+		expiresAt := time.Now().Add(time.Duration(ttl) * time.Second).Truncate(time.Second)
+		claims.Exp = expiresAt.Unix()
+
+		token, err := service.SignJWT(claims, conf.JoinLinkSecret)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to sign join link: %s", err),
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"url":        fmt.Sprintf("%s/j/%s", conf.PublicUrl, token),
+			"lobby_url":  fmt.Sprintf("%s/lobby/%s", conf.PublicUrl, token),
+			"expires_at": expiresAt,
+		})
+	}
+}
+
+// joinLinkRole return the role of the join link request, which is either given or the one of the
+// password in the recorded meeting. Returned error is *fiber.Error.
+func joinLinkRole(c *fiber.Ctx, st *store.Store, jMeet api.JoinMeeting) (string, error) {
+	if jMeet.Password == "" {
+		if jMeet.Role != api.RoleModerator && jMeet.Role != api.RoleViewer {
+			return "", fiber.NewError(fiber.StatusBadRequest, "either `password` or `role` of moderator or viewer is required")
+		}
+		return jMeet.Role, nil
+	}
+
+	m, err := clientMeeting(c, st, jMeet.MeetingId)
+	switch {
+	case err == store.ErrNotFound:
+		return "", fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+	case err != nil:
+		return "", fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+	case jMeet.Password == m.ModeratorPass:
+		return api.RoleModerator, nil
+	case jMeet.Password == m.AttendeePass:
+		return api.RoleViewer, nil
+	}
+
+	return "", fiber.NewError(fiber.StatusForbidden, "password doesn't match the meeting")
+}
+
+// RedirectJoin handler that validate the token in `token` param from JoinLink then redirect the
+// browser to BBB join url.
+func RedirectJoin(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var claims joinLinkClaims
+		if err := service.VerifyJWT(c.Params("token"), conf.JoinLinkSecret, &claims); err != nil {
+			return sendLinkInvalid(c)
+		}
+
+		jMeet := claims.joinMeeting()
+		if err := roleJoin(c, conf, st, &jMeet); err != nil {
+			return sendError(c, err)
+		}
+
+		url, err := joinUrl(conf, jMeet)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
+			})
+		}
+
+		return c.Redirect(url, fiber.StatusFound)
+	}
+}
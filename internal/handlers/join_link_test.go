@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinLink(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	conf.Token = "superSecret"
+	conf.JoinLinkSecret = "linkSecret"
+	conf.JoinLinkMaxTTL = 3600
+	require.NoError(t, conf.Sanitization())
+	conf.PublicUrl = "https://meet.example"
+	conf.BBB.Host = "https://bbb.example"
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", AttendeePass: "att", ModeratorPass: "secret", CreateTime: 121212}))
+
+	app := fiber.New()
+	app.Post("/join/link", JoinLink(conf, st))
+	app.Get("/j/:token", RedirectJoin(conf, st))
+
+	mint := func(t *testing.T, body string, status int) string {
+		req := httptest.NewRequest(fiber.MethodPost, "/join/link", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, status, res.StatusCode)
+		if status != fiber.StatusOK {
+			return ""
+		}
+
+		var link struct {
+			Url       string    `json:"url"`
+			ExpiresAt time.Time `json:"expires_at"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&link))
+		require.True(t, strings.HasPrefix(link.Url, "https://meet.example/j/"))
+		assert.WithinDuration(t, time.Now().Add(5*time.Minute), link.ExpiresAt, 2*time.Second)
+
+		u, err := url.Parse(link.Url)
+		require.NoError(t, err)
+		return u.Path
+	}
+
+	t.Run("Link should redirect to BBB join url", func(t *testing.T) {
+		path := mint(t, `{"name": "NzK", "meeting_id": "meet01", "password": "secret", "user_id": "usr 01"}`, fiber.StatusOK)
+
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusFound, res.StatusCode)
+
+		expect, err := joinUrl(conf, api.JoinMeeting{Name: "NzK", MeetingId: "meet01", Password: "secret", CreateTime: "121212", UserId: "usr 01"})
+		require.NoError(t, err)
+		assert.Equal(t, expect, res.Header.Get(fiber.HeaderLocation))
+	})
+
+	t.Run("Link should carry the role instead of the password", func(t *testing.T) {
+		path := mint(t, `{"name": "NzK", "meeting_id": "meet01", "password": "att", "avatar": "https://avatar.example"}`, fiber.StatusOK)
+
+		var claims map[string]interface{}
+		require.NoError(t, service.VerifyJWT(strings.TrimPrefix(path, "/j/"), conf.JoinLinkSecret, &claims))
+		delete(claims, "exp")
+		assert.Equal(t, map[string]interface{}{"meeting_id": "meet01", "role": api.RoleViewer, "name": "NzK"}, claims)
+	})
+
+	t.Run("Link should not be issued for incomplete request", func(t *testing.T) {
+		mint(t, sampleJoinRequestBody[1], fiber.StatusBadRequest)
+	})
+
+	t.Run("Link should not be issued for wrong password", func(t *testing.T) {
+		mint(t, `{"name": "NzK", "meeting_id": "meet01", "password": "guess"}`, fiber.StatusForbidden)
+	})
+
+	t.Run("Link should not be issued for longer than the max", func(t *testing.T) {
+		mint(t, `{"name": "NzK", "meeting_id": "meet01", "role": "viewer", "expires_in": 3601}`, fiber.StatusBadRequest)
+	})
+
+	testCases := []struct {
+		name   string
+		secret string
+		exp    time.Duration
+	}{
+		{name: "Expired link should be rejected", secret: conf.JoinLinkSecret, exp: -time.Minute},
+		{name: "Link signed using other secret should be rejected", secret: "otherSecret", exp: time.Minute},
+		{name: "Link signed using the main token should be rejected", secret: conf.Token, exp: time.Minute},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := joinLinkClaims{MeetingId: "meet01", Role: api.RoleModerator, Name: "NzK", Exp: time.Now().Add(tt.exp).Unix()}
+			token, err := service.SignJWT(claims, tt.secret)
+			require.NoError(t, err)
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/j/"+token, nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// joinLinkIdLen length of the ID of join links, which is the secret in the link.
+const joinLinkIdLen = 32
+
+// JoinLinksRequest format to issue a join link for a user.
+type JoinLinksRequest struct {
+	MeetingId string `json:"meeting_id"`
+	Name      string `json:"name"`
+	UserId    string `json:"user_id"`
+	Avatar    string `json:"avatar"`
+	Role      string `json:"role"`       // Either attendee (default), viewer which is the same as attendee, or moderator.
+	MaxUses   int    `json:"max_uses"`   // How many times the link could be used. Default to 1.
+	ExpiresIn uint32 `json:"expires_in"` // How long (in seconds) the link is valid, up to `join_link_max_ttl` config. Default to `join_link_ttl` config.
+}
+
+// JoinLinkResponse a join link along with its url.
+type JoinLinkResponse struct {
+	store.JoinLink
+	Url string `json:"url"`
+}
+
+// CreateJoinLink handler that issue an opaque join link that is bound to a meeting, a user and
+// a role. The link could only be used `max_uses` times before it expires, and the passwords of
+// the meeting never leave this app.
+func CreateJoinLink(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var req JoinLinksRequest
+		if err := c.BodyParser(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to join link object: %s", err),
+			})
+		}
+
+		l, err := newJoinLink(conf, req, middlewares.Client(c))
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("join link is invalid: %s", err),
+			})
+		}
+
+		// the meeting could be created later, e.g. by a schedule, but not by other client.
+		m, err := st.Meeting(l.MeetingId)
+		if err != nil && err != store.ErrNotFound {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get meeting: %s", err),
+			})
+		}
+		if err == nil && l.Client != "" && m.Client != l.Client {
+			c.Status(fiber.StatusNotFound)
+			return c.JSON(fiber.Map{
+				"message": "meeting is not found",
+			})
+		}
+
+		if err := st.SaveJoinLink(l); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to save join link: %s", err),
+			})
+		}
+
+		c.Status(fiber.StatusCreated)
+		return c.JSON(newJoinLinkResponse(conf, l))
+	}
+}
+
+// newJoinLink return join link of the request that is issued by the given client.
+func newJoinLink(conf *config.Model, req JoinLinksRequest, clientName string) (store.JoinLink, error) {
+	switch {
+	case req.MeetingId == "":
+		return store.JoinLink{}, fmt.Errorf("`meeting_id` is required")
+	case req.Name == "":
+		return store.JoinLink{}, fmt.Errorf("`name` is required")
+	case req.MaxUses < 0:
+		return store.JoinLink{}, fmt.Errorf("`max_uses` must be positive")
+	}
+
+	var moderator bool
+	switch req.Role {
+	case "", roleAttendee, api.RoleViewer:
+	case roleModerator:
+		moderator = true
+	default:
+		return store.JoinLink{}, fmt.Errorf("`role` must be either attendee (viewer) or moderator")
+	}
+
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	ttl := req.ExpiresIn
+	if ttl == 0 {
+		ttl = conf.JoinLinkTTL
+	}
+	if ttl > conf.JoinLinkMaxTTL {
+		return store.JoinLink{}, fmt.Errorf("`expires_in` must not be more than %d", conf.JoinLinkMaxTTL)
+	}
+
+	randId := service.SecureString{Length: joinLinkIdLen}
+	return store.JoinLink{
+		Id:        randId.RandString(),
+		MeetingId: namespacedMeetingId(conf, clientName, req.MeetingId),
+		Client:    clientName,
+		Name:      req.Name,
+		UserId:    req.UserId,
+		Avatar:    req.Avatar,
+		Moderator: moderator,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: time.Now().Add(time.Duration(ttl) * time.Second).Truncate(time.Second),
+	}, nil
+}
+
+// GetJoinLink handler that send the join link with ID in `id` param, to see how many times it
+// has been used.
+func GetJoinLink(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		l, err := clientJoinLink(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		return c.JSON(newJoinLinkResponse(conf, l))
+	}
+}
+
+// DeleteJoinLink handler that revoke the join link with ID in `id` param.
+func DeleteJoinLink(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		l, err := clientJoinLink(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		if err := st.DeleteJoinLink(l.Id); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to delete join link: %s", err),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// RedeemJoinLink handler that count a use of the join link with ID in `id` param then redirect
+// the browser to BBB join url of the meeting that is currently running.
+func RedeemJoinLink(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		l, err := st.JoinLink(c.Params("id"))
+		if err != nil {
+			return sendError(c, joinLinkError(err))
+		}
+
+		// check the meeting first so the link is not used up when it's not running.
+		m, err := st.Meeting(l.MeetingId)
+		if err != nil && err != store.ErrNotFound {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get meeting: %s", err),
+			})
+		}
+		// the meeting could have been created by other client after the link was issued.
+		if err == nil && l.Client != "" && m.Client != l.Client {
+			c.Status(fiber.StatusNotFound)
+			return c.JSON(fiber.Map{
+				"message": "meeting is not found",
+			})
+		}
+		if err == store.ErrNotFound || m.Ended() || m.CreateTime == 0 {
+			c.Status(fiber.StatusConflict)
+			return c.JSON(fiber.Map{
+				"message": "meeting is not running",
+			})
+		}
+		if !l.Moderator && !m.Assigned(l.UserId) {
+			c.Status(fiber.StatusForbidden)
+			return c.JSON(fiber.Map{
+				"message": "user is not assigned to this breakout room",
+			})
+		}
+
+		if l, err = st.UseJoinLink(l.Id, time.Now()); err != nil {
+			return sendError(c, joinLinkError(err))
+		}
+
+		jMeet := api.JoinMeeting{
+			Name:       l.Name,
+			MeetingId:  m.MeetingId,
+			CreateTime: strconv.FormatInt(m.CreateTime, 10),
+			UserId:     l.UserId,
+			Avatar:     l.Avatar,
+		}
+		joinWithRole(conf, &jMeet, l.Moderator, m.ModeratorPass, m.AttendeePass)
+
+		url, err := joinUrl(conf, jMeet)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
+			})
+		}
+
+		return c.Redirect(url, fiber.StatusFound)
+	}
+}
+
+// newJoinLinkResponse return the join link as response.
+func newJoinLinkResponse(conf *config.Model, l store.JoinLink) JoinLinkResponse {
+	return JoinLinkResponse{JoinLink: l, Url: fmt.Sprintf("%s/l/%s", conf.PublicUrl, l.Id)}
+}
+
+// clientJoinLink return the join link with the given ID if it was issued by the client that
+// made the request. Returned error is *fiber.Error.
+func clientJoinLink(c *fiber.Ctx, st *store.Store, id string) (store.JoinLink, error) {
+	l, err := st.JoinLink(id)
+	if own := middlewares.Client(c); err == nil && own != "" && l.Client != own {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		return store.JoinLink{}, joinLinkError(err)
+	}
+
+	return l, nil
+}
+
+// joinLinkError return *fiber.Error of the error from getting or using a join link.
+func joinLinkError(err error) error {
+	switch err {
+	case store.ErrNotFound:
+		return fiber.NewError(fiber.StatusNotFound, "join link is not found")
+	case store.ErrJoinLinkExpired, store.ErrJoinLinkUsedUp:
+		return fiber.NewError(fiber.StatusGone, err.Error())
+	}
+
+	return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get join link: %s", err))
+}
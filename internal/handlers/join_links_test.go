@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinLinks(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.PublicUrl = "https://meet.example"
+	conf.BBB.Host = "https://bbb.example"
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Client: "lms", AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 121212}))
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet02", Client: "hr", AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 121212}))
+
+	app := fiber.New()
+	own := app.Group("/own", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	})
+	own.Post("/join-links", CreateJoinLink(conf, st))
+	own.Get("/join-links/:id", GetJoinLink(conf, st))
+	own.Delete("/join-links/:id", DeleteJoinLink(st))
+	app.Get("/l/:id", RedeemJoinLink(conf, st))
+
+	request := func(t *testing.T, method, uri, body string) *http.Response {
+		req := httptest.NewRequest(method, uri, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res
+	}
+	create := func(t *testing.T, body string) JoinLinkResponse {
+		res := request(t, fiber.MethodPost, "/own/join-links", body)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+		var l JoinLinkResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&l))
+		require.True(t, strings.HasPrefix(l.Url, "https://meet.example/l/"))
+		return l
+	}
+	redeem := func(t *testing.T, l JoinLinkResponse) *http.Response {
+		return request(t, fiber.MethodGet, strings.TrimPrefix(l.Url, conf.PublicUrl), "")
+	}
+
+	t.Run("Link should join as its role using password of the meeting", func(t *testing.T) {
+		for _, role := range []string{roleAttendee, roleModerator} {
+			l := create(t, `{"meeting_id": "meet01", "name": "NzK", "user_id": "usr01", "role": "`+role+`"}`)
+
+			res := redeem(t, l)
+			require.Equal(t, fiber.StatusFound, res.StatusCode)
+
+			jMeet := api.JoinMeeting{Name: "NzK", MeetingId: "meet01", Password: "att", CreateTime: "121212", UserId: "usr01"}
+			if role == roleModerator {
+				jMeet.Password = "mdr"
+			}
+			expect, err := joinUrl(conf, jMeet)
+			require.NoError(t, err)
+			assert.Equal(t, expect, res.Header.Get(fiber.HeaderLocation))
+		}
+	})
+
+	t.Run("Link should only be used max uses times", func(t *testing.T) {
+		l := create(t, `{"meeting_id": "meet01", "name": "NzK", "max_uses": 2}`)
+		assert.Equal(t, fiber.StatusFound, redeem(t, l).StatusCode)
+		assert.Equal(t, fiber.StatusFound, redeem(t, l).StatusCode)
+		assert.Equal(t, fiber.StatusGone, redeem(t, l).StatusCode)
+
+		res := request(t, fiber.MethodGet, "/own/join-links/"+l.Id, "")
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+		var got JoinLinkResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		assert.Equal(t, 2, got.Uses)
+	})
+
+	t.Run("Expired link should be rejected", func(t *testing.T) {
+		l := create(t, `{"meeting_id": "meet01", "name": "NzK"}`)
+		require.NoError(t, st.SaveJoinLink(store.JoinLink{Id: l.Id, MeetingId: "meet01", Name: "NzK", MaxUses: 1, ExpiresAt: time.Now().Add(-time.Second)}))
+		assert.Equal(t, fiber.StatusGone, redeem(t, l).StatusCode)
+	})
+
+	t.Run("Revoked link should not be found", func(t *testing.T) {
+		l := create(t, `{"meeting_id": "meet01", "name": "NzK"}`)
+		assert.Equal(t, fiber.StatusNoContent, request(t, fiber.MethodDelete, "/own/join-links/"+l.Id, "").StatusCode)
+		assert.Equal(t, fiber.StatusNotFound, redeem(t, l).StatusCode)
+	})
+
+	t.Run("Link should not be used up when the meeting is not running", func(t *testing.T) {
+		l := create(t, `{"meeting_id": "meet03", "name": "NzK"}`)
+		assert.Equal(t, fiber.StatusConflict, redeem(t, l).StatusCode)
+
+		require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet03", Client: "lms", AttendeePass: "att", CreateTime: 121212}))
+		assert.Equal(t, fiber.StatusFound, redeem(t, l).StatusCode)
+	})
+
+	t.Run("Link should not join meeting that other client created after it was issued", func(t *testing.T) {
+		l := create(t, `{"meeting_id": "meet04", "name": "NzK", "role": "moderator"}`)
+		require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet04", Client: "hr", ModeratorPass: "mdr", CreateTime: 121212}))
+		assert.Equal(t, fiber.StatusNotFound, redeem(t, l).StatusCode)
+
+		got, err := st.JoinLink(l.Id)
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.Uses, "link should not be used up")
+	})
+
+	t.Run("Meeting ID of the link should be namespaced", func(t *testing.T) {
+		conf.MeetingIdNamespace = config.NamespacePrefix
+		defer func() { conf.MeetingIdNamespace = config.NamespaceNone }()
+
+		for _, id := range []string{"math", "lms-math"} {
+			l := create(t, `{"meeting_id": "`+id+`", "name": "NzK"}`)
+			assert.Equal(t, "lms-math", l.MeetingId)
+		}
+	})
+
+	t.Run("Attendee link should only join breakout room the user is assigned to", func(t *testing.T) {
+		require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01-breakout-1", ParentId: "meet01", Client: "lms", AssignedUsers: []string{"usr01"}, AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 121212}))
+
+		testCases := []struct {
+			body   string
+			expect int
+		}{
+			{body: `{"meeting_id": "meet01-breakout-1", "name": "NzK", "user_id": "usr01"}`, expect: fiber.StatusFound},
+			{body: `{"meeting_id": "meet01-breakout-1", "name": "NzK", "user_id": "usr02"}`, expect: fiber.StatusForbidden},
+			{body: `{"meeting_id": "meet01-breakout-1", "name": "NzK", "role": "moderator"}`, expect: fiber.StatusFound},
+		}
+		for _, tt := range testCases {
+			assert.Equal(t, tt.expect, redeem(t, create(t, tt.body)).StatusCode, tt.body)
+		}
+	})
+
+	testCases := []struct {
+		name   string
+		body   string
+		expect int
+	}{
+		{name: "Link w/o meeting ID should be rejected", body: `{"name": "NzK"}`, expect: fiber.StatusBadRequest},
+		{name: "Link w/o name should be rejected", body: `{"meeting_id": "meet01"}`, expect: fiber.StatusBadRequest},
+		{name: "Link that is valid for longer than the max should be rejected", body: `{"meeting_id": "meet01", "name": "NzK", "expires_in": 86401}`, expect: fiber.StatusBadRequest},
+		{name: "Link w unknown role should be rejected", body: `{"meeting_id": "meet01", "name": "NzK", "role": "admin"}`, expect: fiber.StatusBadRequest},
+		{name: "Link to meeting of other client should be rejected", body: `{"meeting_id": "meet02", "name": "NzK"}`, expect: fiber.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, request(t, fiber.MethodPost, "/own/join-links", tt.body).StatusCode)
+		})
+	}
+}
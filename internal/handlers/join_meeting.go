@@ -2,16 +2,20 @@ package handlers
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/api"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
 	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
 )
 
 // JoinMeeting handler that receive json request and proxy it to BBB API for joining meeting
-// after convert to URL then send back response from API to the requester.
-func JoinMeeting(conf *config.Model) func(c *fiber.Ctx) error {
+// after convert to URL then send back response from API to the requester. Request that has
+// `role` instead of `password` join using what was recorded when the meeting was created.
+func JoinMeeting(conf *config.Model, st *store.Store) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		// bind incoming json request to predefined object.
 		var jMeet api.JoinMeeting
@@ -22,19 +26,87 @@ func JoinMeeting(conf *config.Model) func(c *fiber.Ctx) error {
 			})
 		}
 
-		url, err := jMeet.ParseJoinMeeting()
+		if err := roleJoin(c, conf, st, &jMeet); err != nil {
+			return sendError(c, err)
+		}
+
+		url, err := joinUrl(conf, jMeet)
 		if err != nil {
 			c.Status(fiber.StatusInternalServerError)
 			return c.JSON(fiber.Map{
 				"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
 			})
 		}
-		// prepare url and calculate their checksum.
-		out := service.SHA1HashUrl(conf.BBB.Secret, url)
-		url = fmt.Sprintf("%s%s%s", conf.BBB.Host, api.EndPoint, url)
 
 		return c.JSON(fiber.Map{
-			"url": fmt.Sprintf("%s&checksum=%s", url, out),
+			"url": url,
 		})
 	}
 }
+
+// joinUrl return BBB API url that would join the user to the meeting along with its checksum.
+func joinUrl(conf *config.Model, jMeet api.JoinMeeting) (string, error) {
+	url, err := jMeet.ParseJoinMeeting()
+	if err != nil {
+		return "", err
+	}
+	// prepare url and calculate their checksum.
+	out := service.SHA1HashUrl(conf.BBB.Secret, url)
+	url = fmt.Sprintf("%s%s%s", conf.BBB.Host, api.EndPoint, url)
+	metrics.JoinUrlIssued(metrics.Host(conf.BBB.Host))
+
+	return fmt.Sprintf("%s&checksum=%s", url, out), nil
+}
+
+// roleJoin fill the join request that has `role` instead of `password` using the meeting that
+// was recorded when it was created. Returned error is *fiber.Error.
+func roleJoin(c *fiber.Ctx, conf *config.Model, st *store.Store, jMeet *api.JoinMeeting) error {
+	if jMeet.Role == "" {
+		return nil
+	}
+	// otherwise the role would be sent along w the password, skipping the checks below.
+	if jMeet.Password != "" {
+		return fiber.NewError(fiber.StatusBadRequest, "either `role` or `password` must be given, not both")
+	}
+	if jMeet.Role != api.RoleModerator && jMeet.Role != api.RoleViewer {
+		return fiber.NewError(fiber.StatusBadRequest, "`role` must be either moderator or viewer")
+	}
+
+	m, err := clientMeeting(c, st, jMeet.MeetingId)
+	switch {
+	case err == store.ErrNotFound:
+		return fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+	case err != nil:
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+	case m.Ended():
+		return fiber.NewError(fiber.StatusConflict, "meeting has ended")
+	case jMeet.Role == api.RoleViewer && !m.Assigned(jMeet.UserId):
+		return fiber.NewError(fiber.StatusForbidden, "user is not assigned to this breakout room")
+	}
+
+	if jMeet.CreateTime == "" && m.CreateTime != 0 {
+		jMeet.CreateTime = strconv.FormatInt(m.CreateTime, 10)
+	}
+	joinWithRole(conf, jMeet, jMeet.Role == api.RoleModerator, m.ModeratorPass, m.AttendeePass)
+
+	return nil
+}
+
+// joinWithRole set the join request to join as moderator or viewer. Role is sent instead of
+// password if BBB server supports it, so the password never leaves this app.
+func joinWithRole(conf *config.Model, jMeet *api.JoinMeeting, moderator bool, moderatorPass, attendeePass string) {
+	if conf.BBB.RoleJoin {
+		jMeet.Password = ""
+		jMeet.Role = api.RoleViewer
+		if moderator {
+			jMeet.Role = api.RoleModerator
+		}
+		return
+	}
+
+	jMeet.Role = ""
+	jMeet.Password = attendeePass
+	if moderator {
+		jMeet.Password = moderatorPass
+	}
+}
@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -47,7 +50,7 @@ func TestJoinMeeting(t *testing.T) {
 	require.NoError(t, conf.BBB.Sanitization())
 
 	app := fiber.New()
-	app.Post("/meeting", JoinMeeting(conf))
+	app.Post("/meeting", JoinMeeting(conf, store.New(store.NewMemory())))
 
 	t.Run("Success using minimum (required) json request", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleJoinRequestBody[0])
@@ -78,7 +81,7 @@ func TestJoinMeeting_VariousFailedCases(t *testing.T) {
 	require.NoError(t, conf.Sanitization())
 
 	app := fiber.New()
-	app.Post("/meeting", JoinMeeting(conf))
+	app.Post("/meeting", JoinMeeting(conf, store.New(store.NewMemory())))
 
 	t.Run("Failed when sending wrong content type that should be json", func(t *testing.T) {
 		buf := bytes.NewBufferString(sampleRequestBody[0])
@@ -91,3 +94,90 @@ func TestJoinMeeting_VariousFailedCases(t *testing.T) {
 		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
 	})
 }
+
+func TestJoinMeeting_Role(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = "https://bbb.example"
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Client: "lms", AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 121212}))
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet02", Client: "hr", AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 121212}))
+
+	app := fiber.New()
+	app.Post("/join", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	}, JoinMeeting(conf, st))
+
+	testCases := []struct {
+		name     string
+		roleJoin bool
+		body     string
+		expect   int
+		contains string
+		excludes string
+	}{
+		{
+			name:     "Moderator should join using the stored password",
+			body:     `{"name": "NzK", "meeting_id": "meet01", "role": "moderator"}`,
+			expect:   fiber.StatusOK,
+			contains: "meetingID=meet01&password=mdr&fullName=NzK&createTime=121212",
+			excludes: "role=",
+		},
+		{
+			name:     "Viewer should join using the stored password",
+			body:     `{"name": "NzK", "meeting_id": "meet01", "role": "viewer"}`,
+			expect:   fiber.StatusOK,
+			contains: "password=att&",
+		},
+		{
+			name:     "Role should be sent instead of password if BBB server supports it",
+			roleJoin: true,
+			body:     `{"name": "NzK", "meeting_id": "meet01", "role": "moderator"}`,
+			expect:   fiber.StatusOK,
+			contains: "meetingID=meet01&fullName=NzK&createTime=121212&role=MODERATOR",
+			excludes: "password=",
+		},
+		{
+			name:   "Unknown role should be rejected",
+			body:   `{"name": "NzK", "meeting_id": "meet01", "role": "admin"}`,
+			expect: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Meeting of other client should not be found",
+			body:   `{"name": "NzK", "meeting_id": "meet02", "role": "viewer"}`,
+			expect: fiber.StatusNotFound,
+		},
+		{
+			name:   "Role along w password should be rejected",
+			body:   `{"name": "NzK", "meeting_id": "meet02", "role": "MODERATOR", "password": "x"}`,
+			expect: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.BBB.RoleJoin = tt.roleJoin
+			req := httptest.NewRequest(fiber.MethodPost, "/join", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, res.StatusCode)
+			if tt.expect != fiber.StatusOK {
+				return
+			}
+
+			var body struct {
+				Url string `json:"url"`
+			}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+			assert.Contains(t, body.Url, tt.contains)
+			if tt.excludes != "" {
+				assert.NotContains(t, body.Url, tt.excludes)
+			}
+		})
+	}
+}
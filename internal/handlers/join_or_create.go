@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/client"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// meetingLocks serialize creating meetings with the same meeting ID, so concurrent joiners don't
+// create the same meeting more than once.
+var meetingLocks service.KeyedMutex
+
+// JoinOrCreateRequest format to join a meeting, creating it if it's not running.
+type JoinOrCreateRequest struct {
+	MeetingId string          `json:"meeting_id"`
+	Name      string          `json:"name"`
+	UserId    string          `json:"user_id"`
+	Avatar    string          `json:"avatar"`
+	IsGuest   bool            `json:"is_guest"`
+	Role      string          `json:"role"`   // Either moderator or viewer (default).
+	Create    json.RawMessage `json:"create"` // Create meeting request. Default to the settings the meeting was created with last time.
+}
+
+// JoinOrCreateResponse join url along with the meeting that would be joined.
+type JoinOrCreateResponse struct {
+	Url        string `json:"url"`
+	MeetingId  string `json:"meeting_id"`
+	CreateTime string `json:"create_time"`
+	Created    bool   `json:"created"` // Whether the meeting was created by this request.
+}
+
+// JoinOrCreate handler that return join url of the meeting in json request, creating the meeting
+// first if it's not running. Concurrent requests of the same meeting ID wait for each other, so
+// the meeting is only created once. Only moderators could create the meeting. Meeting ID of
+// client is namespaced like in CreateMeeting.
+func JoinOrCreate(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		hCl := requestClient(c, hCl)
+
+		var req JoinOrCreateRequest
+		if err := c.BodyParser(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to join or create object: %s", err),
+			})
+		}
+
+		switch {
+		case req.MeetingId == "":
+			return sendError(c, fiber.NewError(fiber.StatusBadRequest, "`meeting_id` is required"))
+		case req.Name == "":
+			return sendError(c, fiber.NewError(fiber.StatusBadRequest, "`name` is required"))
+		case req.Role == "":
+			req.Role = api.RoleViewer
+		case req.Role != api.RoleModerator && req.Role != api.RoleViewer:
+			return sendError(c, fiber.NewError(fiber.StatusBadRequest, "`role` must be either moderator or viewer"))
+		}
+
+		req.MeetingId = namespacedMeetingId(conf, middlewares.Client(c), req.MeetingId)
+		middlewares.SetMeeting(c, req.MeetingId)
+
+		unlock := meetingLocks.Lock(req.MeetingId)
+		m, created, err := runningMeeting(c, conf, hCl, bus, st, req)
+		unlock()
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		jMeet := api.JoinMeeting{
+			Name:       req.Name,
+			MeetingId:  m.MeetingId,
+			CreateTime: strconv.FormatInt(m.CreateTime, 10),
+			UserId:     req.UserId,
+			Avatar:     req.Avatar,
+			IsGuest:    req.IsGuest,
+		}
+		joinWithRole(conf, &jMeet, req.Role == api.RoleModerator, m.ModeratorPass, m.AttendeePass)
+
+		url, err := joinUrl(conf, jMeet)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
+			})
+		}
+
+		return c.JSON(JoinOrCreateResponse{
+			Url:        url,
+			MeetingId:  m.MeetingId,
+			CreateTime: jMeet.CreateTime,
+			Created:    created,
+		})
+	}
+}
+
+// runningMeeting return the record of the meeting in the request if BBB server says it's running.
+// Otherwise the meeting is created using the settings in the request or the ones it was created
+// with last time. Must be called while holding the lock of the meeting ID. Returned error is
+// *fiber.Error.
+func runningMeeting(c *fiber.Ctx, conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store, req JoinOrCreateRequest) (m store.Meeting, created bool, err error) {
+	// meeting ID of other client must not be joined nor created again.
+	own := middlewares.Client(c)
+	m, err = st.Meeting(req.MeetingId)
+	switch {
+	case err == store.ErrNotFound:
+	case err != nil:
+		return m, false, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+	case own != "" && m.Client != own:
+		return m, false, fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+	}
+	recorded := err == nil && !m.Ended() && m.CreateTime != 0
+
+	res, err := meetingRunning(conf, hCl, api.IsRunning{MeetingId: req.MeetingId})
+	if err != nil {
+		return m, false, err
+	}
+	bus.Observe(req.MeetingId, res.Status)
+	if recorded && res.Status {
+		return m, false, nil
+	}
+
+	// a meeting that was created but nobody has joined is not running yet, it only needs to
+	// be created again if BBB server has removed it.
+	if recorded {
+		mi := api.MeetingInfo{MeetingId: req.MeetingId}
+		uri, err := mi.ParseMeetingInfo()
+		if err != nil {
+			return m, false, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to parse meeting info url: %s", err))
+		}
+		var info api.MeetingInfoResponse
+		err = client.Call(hCl, conf.BBB, uri, &info)
+		if err == nil {
+			return m, false, nil
+		}
+		if !client.IsNotFound(err) {
+			return m, false, fiber.NewError(fiber.StatusBadGateway, fmt.Sprintf("failed to get meeting info from BBB API: %s", err))
+		}
+	}
+
+	if req.Role != api.RoleModerator {
+		return m, false, fiber.NewError(fiber.StatusConflict, "meeting has not been started by a moderator yet")
+	}
+
+	settings := m.Settings
+	if len(req.Create) > 0 {
+		settings = api.CreateMeeting{}
+		if err := json.Unmarshal(req.Create, &settings); err != nil {
+			return m, false, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("failed to bind `create` to create meeting object: %s", err))
+		}
+		if err := applyTemplate(conf, st, req.Create, &settings); err != nil {
+			return m, false, err
+		}
+	} else if m.MeetingId == "" {
+		return m, false, fiber.NewError(fiber.StatusNotFound, "meeting is not found, `create` is required to create it")
+	}
+
+	settings.MeetingId = req.MeetingId
+	if recorded {
+		// keep the passwords of the meeting, BBB server would reject different ones.
+		settings.ModeratorPass, settings.AttendeePass = m.ModeratorPass, m.AttendeePass
+	}
+
+	clientName := own
+	if clientName == "" {
+		clientName = m.Client
+	}
+	if _, err := createMeeting(conf, hCl, bus, st, settings, clientName); err != nil {
+		return m, false, err
+	}
+
+	m, err = st.Meeting(req.MeetingId)
+	if err != nil {
+		return m, false, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get the created meeting: %s", err))
+	}
+
+	return m, true, nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMeetingServer prepare fake server to mimic BBB Server that remember the created meetings,
+// none of them is running.
+func fakeMeetingServer(t *testing.T, created *int32) *httptest.Server {
+	var mu sync.Mutex
+	meetings := make(map[string]bool)
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		var resp interface{}
+		switch {
+		case strings.HasSuffix(req.URL.Path, api.Create):
+			// creating takes a while so concurrent requests would overlap.
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(created, 1)
+			mu.Lock()
+			meetings[q.Get("meetingID")] = true
+			mu.Unlock()
+			resp = api.CreateMeetingResponse{
+				StdResponse:   api.StdResponse{CodeString: "SUCCESS"},
+				MeetingId:     q.Get("meetingID"),
+				AttendeePass:  q.Get("attendeePW"),
+				ModeratorPass: q.Get("moderatorPW"),
+				CreateTime:    "121212",
+			}
+		case strings.HasSuffix(req.URL.Path, api.IsMeetingRun):
+			resp = isRunStdResponse{Status: "false", StdResponse: api.StdResponse{CodeString: "SUCCESS"}}
+		case strings.HasSuffix(req.URL.Path, api.GetMeetingDetail):
+			mu.Lock()
+			exists := meetings[q.Get("meetingID")]
+			mu.Unlock()
+			resp = api.StdResponse{CodeString: "SUCCESS"}
+			if !exists {
+				resp = api.StdResponse{CodeString: "FAILED", MsgKey: "notFound"}
+			}
+		}
+
+		xm, err := xml.Marshal(resp)
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+}
+
+func TestJoinOrCreate(t *testing.T) {
+	var created int32
+	server := fakeMeetingServer(t, &created)
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "other", Client: "hr", CreateTime: 121212}))
+
+	app := fiber.New()
+	app.Post("/join-or-create", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	}, JoinOrCreate(conf, server.Client(), event.NewBus(), st))
+
+	request := func(t *testing.T, body string) (int, JoinOrCreateResponse) {
+		req := httptest.NewRequest(fiber.MethodPost, "/join-or-create", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+
+		var resp JoinOrCreateResponse
+		if res.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+		}
+		return res.StatusCode, resp
+	}
+
+	t.Run("Concurrent moderators should create the meeting once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var createdBy int32
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				status, resp := request(t, `{"meeting_id": "meet01", "name": "Dosen", "role": "moderator", "create": {"name": "Math"}}`)
+				assert.Equal(t, fiber.StatusOK, status)
+				assert.Equal(t, "121212", resp.CreateTime)
+				if resp.Created {
+					atomic.AddInt32(&createdBy, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+		assert.Equal(t, int32(1), createdBy)
+
+		m, err := st.Meeting("meet01")
+		require.NoError(t, err)
+		assert.Equal(t, "lms", m.Client)
+		assert.Equal(t, "Math", m.Name)
+	})
+
+	t.Run("Viewer should join the created meeting using its password", func(t *testing.T) {
+		m, err := st.Meeting("meet01")
+		require.NoError(t, err)
+
+		status, resp := request(t, `{"meeting_id": "meet01", "name": "Mahasiswa"}`)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.False(t, resp.Created)
+		assert.Contains(t, resp.Url, "password="+m.AttendeePass)
+	})
+
+	t.Run("Meeting removed by BBB server should be created again using its settings", func(t *testing.T) {
+		atomic.StoreInt32(&created, 0)
+		status, resp := request(t, `{"meeting_id": "meet02", "name": "Dosen", "role": "moderator", "create": {"name": "Physics"}}`)
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, resp.Created)
+
+		// the fake server doesn't know meeting that was recorded before it was started.
+		require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet03", Name: "Biology", Client: "lms", AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 1, Settings: api.CreateMeeting{Name: "Biology"}}))
+		status, resp = request(t, `{"meeting_id": "meet03", "name": "Dosen", "role": "moderator"}`)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.True(t, resp.Created)
+		assert.Contains(t, resp.Url, "password=mdr", "password should be kept")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&created))
+	})
+
+	testCases := []struct {
+		name   string
+		body   string
+		expect int
+	}{
+		{name: "Viewer should not create the meeting", body: `{"meeting_id": "meet04", "name": "Mahasiswa", "create": {"name": "Math"}}`, expect: fiber.StatusConflict},
+		{name: "Unknown meeting w/o create request should not be found", body: `{"meeting_id": "meet04", "name": "Dosen", "role": "moderator"}`, expect: fiber.StatusNotFound},
+		{name: "Meeting of other client should not be found", body: `{"meeting_id": "other", "name": "Dosen", "role": "moderator", "create": {"name": "Math"}}`, expect: fiber.StatusNotFound},
+		{name: "Unknown role should be rejected", body: `{"meeting_id": "meet01", "name": "Dosen", "role": "admin"}`, expect: fiber.StatusBadRequest},
+		{name: "Request w/o name should be rejected", body: `{"meeting_id": "meet01"}`, expect: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := request(t, tt.body)
+			assert.Equal(t, tt.expect, status)
+		})
+	}
+}
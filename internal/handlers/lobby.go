@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// lobbyPage waiting page that reload itself until the user could join. Refresh 0 doesn't reload.
+var lobbyPage = template.Must(template.New("lobby").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">{{end}}
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; display: flex; align-items: center; justify-content: center; min-height: 90vh; margin: 0; color: #333; }
+main { text-align: center; max-width: 32rem; padding: 1rem; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+{{if .Meeting}}<p><strong>{{.Meeting}}</strong></p>{{end}}
+<p>{{.Message}}</p>
+</main>
+</body>
+</html>
+`))
+
+// lobbyData what is shown on the waiting page.
+type lobbyData struct {
+	Title   string
+	Meeting string // Name of the meeting if known.
+	Message string
+	Refresh uint16 // Seconds until the page reload itself.
+}
+
+// Lobby handler that hold the user of the signed join link in `token` param from JoinLink on a
+// waiting page until a moderator has started the meeting, then redirect the browser to BBB join
+// url. Moderators are redirected right away.
+func Lobby(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
+	refresh := time.Duration(conf.LobbyRefresh) * time.Second
+	// every waiting browser reload the page, so BBB API is asked at most once per refresh.
+	running := newRunningCache(refresh)
+
+	return func(c *fiber.Ctx) error {
+		hCl := requestClient(c, hCl)
+
+		var claims joinLinkClaims
+		if err := service.VerifyJWT(c.Params("token"), conf.JoinLinkSecret, &claims); err != nil {
+			return sendLinkInvalid(c)
+		}
+
+		jMeet := claims.joinMeeting()
+		if jMeet.Role != api.RoleModerator {
+			ok, err := running.get(claims.MeetingId, func() (bool, error) {
+				res, err := meetingRunning(conf, hCl, api.IsRunning{MeetingId: claims.MeetingId})
+				if err != nil {
+					return false, err
+				}
+				bus.Observe(claims.MeetingId, res.Status)
+				return res.Status, nil
+			})
+			if err != nil {
+				return sendError(c, err)
+			}
+			if !ok {
+				return sendLobby(c, st, jMeet, conf.LobbyRefresh)
+			}
+		}
+
+		if err := roleJoin(c, conf, st, &jMeet); err != nil {
+			return sendError(c, err)
+		}
+
+		url, err := joinUrl(conf, jMeet)
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
+			})
+		}
+
+		return c.Redirect(url, fiber.StatusFound)
+	}
+}
+
+// sendLobby send the waiting page for the user who is joining the meeting.
+func sendLobby(c *fiber.Ctx, st *store.Store, jMeet api.JoinMeeting, refresh uint16) error {
+	return sendPage(c, st, jMeet.MeetingId, lobbyData{
+		Title:   "Waiting for host",
+		Message: fmt.Sprintf("Hi %s, the meeting hasn't been started by a moderator yet. You will join automatically once it starts, keep this page open.", jMeet.Name),
+		Refresh: refresh,
+	})
+}
+
+// sendLinkInvalid send the page that tell the user who opened a join link that has expired or
+// is invalid to ask for a new one, since it's opened by the browser.
+func sendLinkInvalid(c *fiber.Ctx) error {
+	c.Status(fiber.StatusUnauthorized)
+	return sendPage(c, nil, "", lobbyData{
+		Title:   "Link is no longer valid",
+		Message: "This join link has expired or is invalid. Ask the organizer for a new one.",
+	})
+}
+
+// sendPage render the waiting page with the name of the given meeting if it's recorded. The store
+// could be nil if there is no meeting to show.
+func sendPage(c *fiber.Ctx, st *store.Store, meetingId string, data lobbyData) error {
+	if st != nil {
+		if m, err := st.Meeting(meetingId); err == nil {
+			data.Meeting = m.Name
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := lobbyPage.Execute(&buf, data); err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return c.JSON(fiber.Map{
+			"message": fmt.Sprintf("failed to render waiting page: %s", err),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(buf.Bytes())
+}
+
+// runningCache remember the meetings that are not running for a while.
+type runningCache struct {
+	ttl       time.Duration
+	locks     service.KeyedMutex
+	mu        sync.Mutex
+	checkedAt map[string]time.Time // When the meeting was found not running.
+}
+
+// newRunningCache return cache that remember for the given duration.
+func newRunningCache(ttl time.Duration) *runningCache {
+	return &runningCache{ttl: ttl, checkedAt: make(map[string]time.Time)}
+}
+
+// get return whether the meeting is running, using check if it's not remembered. Meetings that
+// are running are not remembered, since users need to wait no longer. Checks of the same meeting
+// are serialized so browsers that reload at the same time don't ask BBB API more than once, while
+// checks of other meetings go on.
+func (r *runningCache) get(meetingId string, check func() (bool, error)) (bool, error) {
+	if r.notRunning(meetingId) {
+		return false, nil
+	}
+
+	unlock := r.locks.Lock(meetingId)
+	defer unlock()
+	// the meeting could have been checked while waiting for the lock.
+	if r.notRunning(meetingId) {
+		return false, nil
+	}
+
+	running, err := check()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// forget the stale ones so the cache doesn't keep growing.
+	now := time.Now()
+	for id, at := range r.checkedAt {
+		if now.Sub(at) >= r.ttl {
+			delete(r.checkedAt, id)
+		}
+	}
+	if !running {
+		r.checkedAt[meetingId] = now
+	}
+
+	return running, nil
+}
+
+// notRunning return whether the meeting was found not running within the ttl.
+func (r *runningCache) notRunning(meetingId string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	at, ok := r.checkedAt[meetingId]
+	return ok && time.Since(at) < r.ttl
+}
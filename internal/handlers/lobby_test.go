@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLobby(t *testing.T) {
+	var running, checks int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&checks, 1)
+		status := "false"
+		if atomic.LoadInt32(&running) == 1 {
+			status = "true"
+		}
+		xm, err := xml.Marshal(&isRunStdResponse{Status: status, StdResponse: api.StdResponse{CodeString: "SUCCESS"}})
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	conf.Token = "superSecret"
+	conf.LobbyRefresh = 60
+	require.NoError(t, conf.Sanitization())
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Name: "Math <Class>", AttendeePass: "att", ModeratorPass: "mdr", CreateTime: 121212}))
+
+	app := fiber.New()
+	app.Get("/lobby/:token", Lobby(conf, server.Client(), event.NewBus(), st))
+
+	lobby := func(t *testing.T, claims joinLinkClaims) *http.Response {
+		claims.Exp = time.Now().Add(time.Hour).Unix()
+		token, err := service.SignJWT(claims, conf.JoinLinkSecret)
+		require.NoError(t, err)
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/lobby/"+token, nil))
+		require.NoError(t, err)
+		return res
+	}
+	viewer := joinLinkClaims{Name: "NzK", MeetingId: "meet01", Role: api.RoleViewer}
+
+	t.Run("Viewer should wait until the meeting is running", func(t *testing.T) {
+		res := lobby(t, viewer)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+		assert.Equal(t, fiber.MIMETextHTMLCharsetUTF8, res.Header.Get(fiber.HeaderContentType))
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `<meta http-equiv="refresh" content="60">`)
+		assert.Contains(t, string(body), "Math &lt;Class&gt;", "meeting name should be escaped")
+	})
+
+	t.Run("Waiting browsers should not ask BBB API on every reload", func(t *testing.T) {
+		before := atomic.LoadInt32(&checks)
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, fiber.StatusOK, lobby(t, viewer).StatusCode)
+		}
+		assert.Equal(t, before, atomic.LoadInt32(&checks))
+	})
+
+	t.Run("Expired link should get a page instead of an error", func(t *testing.T) {
+		claims := viewer
+		claims.Exp = time.Now().Add(-time.Minute).Unix()
+		token, err := service.SignJWT(claims, conf.JoinLinkSecret)
+		require.NoError(t, err)
+
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/lobby/"+token, nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, fiber.MIMETextHTMLCharsetUTF8, res.Header.Get(fiber.HeaderContentType))
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "expired")
+		assert.NotContains(t, string(body), "http-equiv=\"refresh\"", "expired page should not reload itself")
+	})
+
+	t.Run("Moderator should not wait", func(t *testing.T) {
+		res := lobby(t, joinLinkClaims{Name: "NzK", MeetingId: "meet01", Role: api.RoleModerator})
+		require.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Contains(t, res.Header.Get(fiber.HeaderLocation), "password=mdr")
+	})
+
+	t.Run("Viewer should be redirected once the meeting is running", func(t *testing.T) {
+		atomic.StoreInt32(&running, 1)
+		app := fiber.New()
+		app.Get("/lobby/:token", Lobby(conf, server.Client(), event.NewBus(), st))
+		viewer.Exp = time.Now().Add(time.Hour).Unix()
+		token, err := service.SignJWT(viewer, conf.JoinLinkSecret)
+		require.NoError(t, err)
+
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/lobby/"+token, nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusFound, res.StatusCode)
+		assert.Contains(t, res.Header.Get(fiber.HeaderLocation), "password=att")
+	})
+}
+
+func TestRunningCache_Get(t *testing.T) {
+	t.Run("Slow check should not hold checks of other meetings", func(t *testing.T) {
+		r := newRunningCache(time.Minute)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go func() {
+			_, _ = r.get("slow", func() (bool, error) {
+				close(started)
+				<-release
+				return false, nil
+			})
+		}()
+		<-started
+		defer close(release)
+
+		done := make(chan bool)
+		go func() {
+			running, err := r.get("fast", func() (bool, error) { return true, nil })
+			assert.NoError(t, err)
+			done <- running
+		}()
+
+		select {
+		case running := <-done:
+			assert.True(t, running)
+		case <-time.After(time.Second):
+			t.Fatal("check of other meeting waited for the slow one")
+		}
+	})
+
+	t.Run("Concurrent checks of the same meeting should ask once", func(t *testing.T) {
+		r := newRunningCache(time.Minute)
+		var checks int32
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				running, err := r.get("meet01", func() (bool, error) {
+					atomic.AddInt32(&checks, 1)
+					time.Sleep(10 * time.Millisecond)
+					return false, nil
+				})
+				assert.NoError(t, err)
+				assert.False(t, running)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&checks))
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+)
+
+// Metrics handler that serve every metric of this app in Prometheus text format. When
+// `metrics_token` is configured, requests must have it as bearer token, which Prometheus sends
+// with `authorization` scrape config.
+func Metrics(conf *config.Model) func(*fiber.Ctx) error {
+	serve := metrics.Handler()
+
+	return func(c *fiber.Ctx) error {
+		if conf.MetricsToken != "" {
+			want := "Bearer " + conf.MetricsToken
+			if subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte(want)) != 1 {
+				return sendError(c, fiber.NewError(fiber.StatusUnauthorized, "metrics token doesn't match"))
+			}
+		}
+
+		serve(c.Context())
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	testCases := []struct {
+		name   string
+		token  string
+		header string
+		expect int
+	}{
+		{
+			name:   "Metrics w/o token should be open",
+			expect: fiber.StatusOK,
+		},
+		{
+			name:   "Metrics w token should be served to request that has it",
+			token:  "scrape",
+			header: "Bearer scrape",
+			expect: fiber.StatusOK,
+		},
+		{
+			name:   "Metrics w token should reject request w wrong token",
+			token:  "scrape",
+			header: "Bearer wrong",
+			expect: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/metrics", Metrics(&config.Model{MetricsToken: tt.token}))
+
+			req := httptest.NewRequest(fiber.MethodGet, "/metrics", nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, res.StatusCode)
+			if tt.expect != fiber.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "go_goroutines")
+		})
+	}
+}
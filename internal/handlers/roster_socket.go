@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/roster"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/valyala/fasthttp"
+)
+
+// rosterEventTypes event types that would be sent through roster socket.
+var rosterEventTypes = []string{
+	event.MeetingStarted,
+	event.MeetingEnded,
+	event.UserJoined,
+	event.UserLeft,
+	event.PresenterAssigned,
+	event.PresenterRemoved,
+	event.UserMuted,
+	event.UserUnmuted,
+}
+
+// RosterSnapshot message that sent first after a requester subscribed to roster socket.
+type RosterSnapshot struct {
+	Type      string       `json:"type"`       // Always `roster`.
+	MeetingId string       `json:"meeting_id"` // The meeting ID that was subscribed to.
+	Attendees []event.User `json:"attendees"`  // Users that are currently in the meeting.
+}
+
+// upgrader to upgrade incoming request to websocket. Requester is already
+// authenticated by token, so any origin is allowed.
+var upgrader = websocket.FastHTTPUpgrader{
+	CheckOrigin: func(*fasthttp.RequestCtx) bool { return true },
+}
+
+// RosterSocket handler that upgrade the request to websocket then subscribe it to the roster
+// of the meeting in `id` param. The current roster would be sent first followed by every
+// join, leave, presenter and mute change as event. The meeting would be polled using the
+// poller as long as the socket is open. Requester that authenticated as a client would only
+// receive events of its own meetings, and could only subscribe to the meetings it created.
+func RosterSocket(bus *event.Bus, poller *roster.Poller, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if !websocket.FastHTTPIsWebSocketUpgrade(c.Context()) {
+			c.Status(fiber.StatusUpgradeRequired)
+			return c.JSON(fiber.Map{
+				"message": "this endpoint only accept websocket request",
+			})
+		}
+
+		if err := checkMeetingOwner(c, st, c.Params("id")); err != nil {
+			return sendError(c, err)
+		}
+
+		// copy the params because fiber's ctx would be released before the socket is closed.
+		meetId := utils.CopyString(c.Params("id"))
+		filters := []event.Filter{event.ByMeeting(meetId), event.ByTypes(rosterEventTypes...)}
+		if client := middlewares.Client(c); client != "" {
+			filters = append(filters, event.ByClient(client))
+		}
+
+		return upgrader.Upgrade(c.Context(), func(conn *websocket.Conn) {
+			defer conn.Close()
+
+			// subscribe before sending the snapshot so no change would be missed.
+			ch, unsubscribe := bus.Subscribe(64, event.All(filters...))
+			defer unsubscribe()
+
+			unwatch := poller.Watch(meetId)
+			defer unwatch()
+
+			// read until the requester closed the socket.
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+
+			snapshot := RosterSnapshot{Type: "roster", MeetingId: meetId, Attendees: bus.Roster(meetId)}
+			if err := conn.WriteJSON(&snapshot); err != nil {
+				return
+			}
+
+			for {
+				select {
+				case e, ok := <-ch:
+					if !ok {
+						return
+					}
+					if err := conn.WriteJSON(&e); err != nil {
+						return
+					}
+				case <-closed:
+					return
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/roster"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRosterSocket(t *testing.T) {
+	bus := event.NewBus()
+	bus.Publish(event.Event{Type: event.UserJoined, MeetingId: "meet01", User: &event.User{Id: "usr01", Name: "NzK"}})
+	poller := roster.NewPoller(nil, api.Config{}, bus, time.Hour)
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet01", Client: "lms", CreateTime: 121212}))
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet03", Client: "hr", CreateTime: 121212}))
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/meetings/:id/roster", func(c *fiber.Ctx) error {
+		if cl := c.Query("client"); cl != "" {
+			c.Locals(middlewares.ClientKey, cl)
+		}
+		return c.Next()
+	}, RosterSocket(bus, poller, st))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln)
+	t.Cleanup(func() {
+		bus.Close()
+		app.Shutdown()
+	})
+
+	t.Run("Should reject request that is not websocket", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodGet, "/meetings/meet01/roster", nil)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUpgradeRequired, res.StatusCode)
+	})
+
+	t.Run("Should send the roster first then every change", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/meetings/meet01/roster", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var snapshot RosterSnapshot
+		require.NoError(t, conn.ReadJSON(&snapshot))
+		assert.Equal(t, "roster", snapshot.Type)
+		require.Len(t, snapshot.Attendees, 1)
+		assert.Equal(t, "usr01", snapshot.Attendees[0].Id)
+
+		bus.Publish(event.Event{Type: event.ChatMessage, MeetingId: "meet01"})
+		bus.Publish(event.Event{Type: event.UserJoined, MeetingId: "meet02", User: &event.User{Id: "usr02"}})
+		bus.Publish(event.Event{Type: event.UserLeft, MeetingId: "meet01", User: &event.User{Id: "usr01"}})
+
+		var e event.Event
+		require.NoError(t, conn.ReadJSON(&e))
+		assert.Equal(t, event.UserLeft, e.Type)
+		assert.Equal(t, "usr01", e.User.Id)
+	})
+
+	testCases := []struct {
+		name   string
+		target string
+		expect int
+	}{
+		{name: "Client should subscribe to its own meeting", target: "/meetings/meet01/roster?client=lms", expect: fiber.StatusSwitchingProtocols},
+		{name: "Client should not subscribe to meeting of other client", target: "/meetings/meet03/roster?client=lms", expect: fiber.StatusNotFound},
+		{name: "Client should not subscribe to unknown meeting", target: "/meetings/made-up/roster?client=lms", expect: fiber.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, res, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+tt.target, nil)
+			if conn != nil {
+				conn.Close()
+			}
+			if tt.expect == fiber.StatusSwitchingProtocols {
+				require.NoError(t, err)
+			}
+			require.NotNil(t, res)
+			assert.Equal(t, tt.expect, res.StatusCode)
+		})
+	}
+}
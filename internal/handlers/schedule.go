@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// scheduleKeyLen length of the secret in join links of a schedule.
+const scheduleKeyLen = 32
+
+// ScheduleRequest format that needed to schedule a meeting. Every field of create meeting
+// request could be given as the settings of the meeting.
+type ScheduleRequest struct {
+	api.CreateMeeting
+	MeetingId string    `json:"meeting_id"` // Meeting ID that would be used when creating the meeting. Optional.
+	StartAt   time.Time `json:"start_at"`   // Users could join since this time. Required.
+	EndAt     time.Time `json:"end_at"`     // Users could join until this time. Required.
+}
+
+// ScheduleResponse a scheduled meeting along with its join links.
+type ScheduleResponse struct {
+	Id           string    `json:"id"`
+	MeetingId    string    `json:"meeting_id"`
+	Name         string    `json:"name"`
+	Client       string    `json:"client,omitempty"`
+	StartAt      time.Time `json:"start_at"`
+	EndAt        time.Time `json:"end_at"`
+	ModeratorUrl string    `json:"moderator_url"` // Link to join as moderator. `name` query should be appended.
+	AttendeeUrl  string    `json:"attendee_url"`  // Link to join as attendee. `name` query should be appended.
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// newScheduleResponse return the given schedule as response with its join links.
+func newScheduleResponse(conf *config.Model, sc store.Schedule) ScheduleResponse {
+	return ScheduleResponse{
+		Id:           sc.Id,
+		MeetingId:    sc.MeetingId,
+		Name:         sc.Settings.Name,
+		Client:       sc.Client,
+		StartAt:      sc.StartAt,
+		EndAt:        sc.EndAt,
+		ModeratorUrl: scheduleLink(conf, sc, true),
+		AttendeeUrl:  scheduleLink(conf, sc, false),
+		CreatedAt:    sc.CreatedAt,
+	}
+}
+
+// scheduleLink return link to join the scheduled meeting as moderator or attendee.
+func scheduleLink(conf *config.Model, sc store.Schedule, moderator bool) string {
+	key := sc.AttendeeKey
+	if moderator {
+		key = sc.ModeratorKey
+	}
+
+	return fmt.Sprintf("%s/schedules/%s/join?key=%s", conf.PublicUrl, url.PathEscape(sc.Id), key)
+}
+
+// CreateSchedule handler that receive json request to schedule a meeting ahead of time and
+// save it to the store, then send back the schedule along with its join links.
+func CreateSchedule(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var req ScheduleRequest
+		if err := c.BodyParser(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to schedule object: %s", err),
+			})
+		}
+		if err := applyTemplate(conf, st, c.Body(), &req.CreateMeeting); err != nil {
+			return sendError(c, err)
+		}
+
+		sc, err := newSchedule(conf, req, middlewares.Client(c))
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to schedule meeting: %s", err),
+			})
+		}
+
+		if err := st.SaveSchedule(sc); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to save schedule: %s", err),
+			})
+		}
+
+		c.Status(fiber.StatusCreated)
+		return c.JSON(newScheduleResponse(conf, sc))
+	}
+}
+
+// newSchedule validate the request then return it as schedule that owned by the given client.
+// Meeting ID and passwords that are not given would be generated, so they're stable until the
+// meeting is created. Given meeting ID is namespaced like in CreateMeeting.
+func newSchedule(conf *config.Model, req ScheduleRequest, clientName string) (store.Schedule, error) {
+	switch {
+	case req.Name == "":
+		return store.Schedule{}, fmt.Errorf("`name` field is required")
+	case req.StartAt.IsZero() || req.EndAt.IsZero():
+		return store.Schedule{}, fmt.Errorf("`start_at` and `end_at` fields are required")
+	case !req.EndAt.After(req.StartAt):
+		return store.Schedule{}, fmt.Errorf("`end_at` must be after `start_at`")
+	case !req.EndAt.After(time.Now()):
+		return store.Schedule{}, fmt.Errorf("`end_at` must be in the future")
+	}
+
+	randId := randomString(conf)
+	randKey := service.SecureString{Length: scheduleKeyLen}
+
+	settings := req.CreateMeeting
+	settings.MeetingId = namespacedMeetingId(conf, clientName, req.MeetingId)
+	if settings.MeetingId == "" {
+		settings.MeetingId = meetingIdGenerator(conf).RandString()
+	}
+	if settings.ModeratorPass == "" {
+		settings.ModeratorPass = randId.RandString()
+	}
+	if settings.AttendeePass == "" {
+		settings.AttendeePass = randId.RandString()
+	}
+
+	return store.Schedule{
+		Id:           randId.RandString(),
+		MeetingId:    settings.MeetingId,
+		Client:       clientName,
+		StartAt:      req.StartAt,
+		EndAt:        req.EndAt,
+		Settings:     settings,
+		ModeratorKey: randKey.RandString(),
+		AttendeeKey:  randKey.RandString(),
+	}, nil
+}
+
+// GetSchedule handler that send the schedule with ID in `id` param along with its join links.
+func GetSchedule(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		sc, err := clientSchedule(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		return c.JSON(newScheduleResponse(conf, sc))
+	}
+}
+
+// ListSchedules handler that send every schedule that hasn't closed, ordered by their start
+// time. Requester that authenticated as a client would only get its own schedules.
+func ListSchedules(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		own := middlewares.Client(c)
+		now := time.Now()
+
+		schedules := make([]ScheduleResponse, 0)
+		err := st.EachSchedule(func(sc store.Schedule) error {
+			if (own != "" && sc.Client != own) || !sc.EndAt.After(now) {
+				return nil
+			}
+			schedules = append(schedules, newScheduleResponse(conf, sc))
+			return nil
+		})
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to read schedules: %s", err),
+			})
+		}
+
+		sort.Slice(schedules, func(i, j int) bool {
+			return schedules[i].StartAt.Before(schedules[j].StartAt)
+		})
+
+		return c.JSON(fiber.Map{
+			"schedules": schedules,
+		})
+	}
+}
+
+// DeleteSchedule handler that cancel the schedule with ID in `id` param. Meeting that has
+// been created from it is not ended. Cancelled occurrence of a series is added to the
+// exceptions of the series so it would not be scheduled again.
+func DeleteSchedule(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		sc, err := clientSchedule(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		if sc.SeriesId != "" {
+			err := st.UpdateSeries(sc.SeriesId, func(sr *store.Series) error {
+				if !sr.Excepted(sc.Date) {
+					sr.Exceptions = append(sr.Exceptions, sc.Date)
+				}
+				return nil
+			})
+			if err != nil && err != store.ErrNotFound {
+				c.Status(fiber.StatusInternalServerError)
+				return c.JSON(fiber.Map{
+					"message": fmt.Sprintf("failed to add exception to series: %s", err),
+				})
+			}
+		}
+
+		if err := st.DeleteSchedule(sc.Id); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to delete schedule: %s", err),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// JoinSchedule handler that redirect the requester to join the scheduled meeting with ID in
+// `id` param as moderator or attendee depending on the `key` query. The meeting is created
+// when the first moderator joins within the window. Joining outside the window, or as attendee
+// before the meeting is created, is rejected.
+func JoinSchedule(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		hCl := requestClient(c, hCl)
+
+		sc, err := st.Schedule(c.Params("id"))
+		if err != nil {
+			return sendError(c, scheduleError(err))
+		}
+
+		return joinSchedule(c, conf, hCl, bus, st, sc)
+	}
+}
+
+// joinSchedule redirect the requester to join the meeting of the schedule as moderator or
+// attendee depending on the `key` query.
+func joinSchedule(c *fiber.Ctx, conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store, sc store.Schedule) error {
+	moderator, err := joinRole(c.Query("key"), sc.ModeratorKey, sc.AttendeeKey)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	name := c.Query("name")
+	if name == "" {
+		c.Status(fiber.StatusBadRequest)
+		return c.JSON(fiber.Map{
+			"message": "`name` query is required",
+		})
+	}
+
+	now := time.Now()
+	if open, reason := sc.Window(now); !open {
+		status := fiber.StatusGone
+		if now.Before(sc.StartAt) {
+			status = fiber.StatusTooEarly
+		}
+		c.Status(status)
+		return c.JSON(fiber.Map{
+			"message": reason,
+		})
+	}
+
+	createTime, err := scheduledMeeting(conf, hCl, bus, st, sc, moderator)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	jMeet := api.JoinMeeting{
+		Name:       name,
+		MeetingId:  sc.MeetingId,
+		CreateTime: createTime,
+		UserId:     c.Query("user_id"),
+	}
+	joinWithRole(conf, &jMeet, moderator, sc.Settings.ModeratorPass, sc.Settings.AttendeePass)
+	url, err := joinUrl(conf, jMeet)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError)
+		return c.JSON(fiber.Map{
+			"message": fmt.Sprintf("failed to parse join meeting url: %s", err),
+		})
+	}
+
+	return c.Redirect(url, fiber.StatusFound)
+}
+
+// joinRole return whether the key in join link is the moderator's, or *fiber.Error if it's
+// neither the moderator's nor the attendee's.
+func joinRole(key, moderatorKey, attendeeKey string) (moderator bool, err error) {
+	if subtle.ConstantTimeCompare([]byte(key), []byte(moderatorKey)) == 1 {
+		return true, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(key), []byte(attendeeKey)) == 1 {
+		return false, nil
+	}
+
+	return false, fiber.NewError(fiber.StatusForbidden, "join key is invalid")
+}
+
+// scheduledMeeting return create time of the meeting of the schedule if it's running. Otherwise
+// the meeting is created if a moderator is joining. Meeting w the same ID that belongs to other
+// client is rejected.
+func scheduledMeeting(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store, sc store.Schedule, moderator bool) (string, error) {
+	// moderators who join at the same time must not create the meeting more than once.
+	unlock := meetingLocks.Lock(sc.MeetingId)
+	defer unlock()
+
+	m, err := st.Meeting(sc.MeetingId)
+	if err != nil && err != store.ErrNotFound {
+		return "", fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get meeting: %s", err))
+	}
+	// meeting of other client must be neither joined nor created again w the schedule.
+	if err == nil && m.Client != sc.Client {
+		return "", errMeetingIdTaken
+	}
+	if err == nil && !m.Ended() && m.CreateTime != 0 {
+		return strconv.FormatInt(m.CreateTime, 10), nil
+	}
+
+	if !moderator {
+		return "", fiber.NewError(fiber.StatusConflict, "meeting has not been started by a moderator yet")
+	}
+
+	resp, err := createMeeting(conf, hCl, bus, st, sc.Settings, sc.Client)
+	if err != nil {
+		return "", err
+	}
+
+	// group the meeting of an occurrence with the other occurrences of its series.
+	if sc.SeriesId != "" {
+		err := st.UpdateMeeting(sc.MeetingId, func(m *store.Meeting) error {
+			m.SeriesId = sc.SeriesId
+			return nil
+		})
+		if err != nil {
+			return "", fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to record series of the meeting: %s", err))
+		}
+	}
+
+	return resp.CreateTime, nil
+}
+
+// clientSchedule return the schedule with the given ID. Schedule of other client is not found
+// if the requester authenticated as a client. Returned error is *fiber.Error.
+func clientSchedule(c *fiber.Ctx, st *store.Store, id string) (store.Schedule, error) {
+	sc, err := st.Schedule(id)
+	if own := middlewares.Client(c); err == nil && own != "" && sc.Client != own {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		return store.Schedule{}, scheduleError(err)
+	}
+
+	return sc, nil
+}
+
+// scheduleError return *fiber.Error of the error from getting a schedule.
+func scheduleError(err error) error {
+	if err == store.ErrNotFound {
+		return fiber.NewError(fiber.StatusNotFound, "schedule is not found")
+	}
+
+	return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get schedule: %s", err))
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCreateServer prepare fake server to mimic BBB Server that create meeting with the
+// requested meeting ID, and count how many meetings were created.
+var fakeCreateServer = func(t *testing.T, created *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(created, 1)
+		q := req.URL.Query()
+		xm, err := xml.Marshal(&api.CreateMeetingResponse{
+			StdResponse:   api.StdResponse{CodeString: "SUCCESS"},
+			MeetingId:     q.Get("meetingID"),
+			AttendeePass:  q.Get("attendeePW"),
+			ModeratorPass: q.Get("moderatorPW"),
+			CreateTime:    "121212",
+		})
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+}
+
+// scheduleTestApp return app that serve schedule endpoints, with `/own` prefix to
+// request as `lms` client.
+func scheduleTestApp(t *testing.T, created *int32) (*fiber.App, *store.Store) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.PublicUrl = "https://meet.example"
+	server := fakeCreateServer(t, created)
+	t.Cleanup(server.Close)
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	bus := event.NewBus()
+
+	app := fiber.New()
+	own := app.Group("/own", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	})
+	for _, r := range []fiber.Router{app, own} {
+		r.Post("/schedules", CreateSchedule(conf, st))
+		r.Get("/schedules", ListSchedules(conf, st))
+		r.Get("/schedules/:id", GetSchedule(conf, st))
+		r.Delete("/schedules/:id", DeleteSchedule(st))
+	}
+	app.Get("/schedules/:id/join", JoinSchedule(conf, server.Client(), bus, st))
+
+	return app, st
+}
+
+func TestCreateSchedule(t *testing.T) {
+	var created int32
+	app, st := scheduleTestApp(t, &created)
+	now := time.Now()
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "Schedule w/o name should be rejected",
+			body:   fmt.Sprintf(`{"start_at": %q, "end_at": %q}`, now.Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339)),
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Schedule w/o time should be rejected",
+			body:   `{"name": "Class"}`,
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Schedule that end before start should be rejected",
+			body:   fmt.Sprintf(`{"name": "Class", "start_at": %q, "end_at": %q}`, now.Add(time.Hour).Format(time.RFC3339), now.Format(time.RFC3339)),
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Schedule that already ended should be rejected",
+			body:   fmt.Sprintf(`{"name": "Class", "start_at": %q, "end_at": %q}`, now.Add(-2*time.Hour).Format(time.RFC3339), now.Add(-time.Hour).Format(time.RFC3339)),
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Valid schedule should be created",
+			body:   fmt.Sprintf(`{"name": "Class", "meeting_id": "meet01", "start_at": %q, "end_at": %q}`, now.Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339)),
+			status: fiber.StatusCreated,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/own/schedules", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.status != fiber.StatusCreated {
+				return
+			}
+
+			var resp ScheduleResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+			assert.Equal(t, "meet01", resp.MeetingId)
+			assert.Equal(t, "lms", resp.Client)
+			assert.True(t, strings.HasPrefix(resp.ModeratorUrl, "https://meet.example/schedules/"+resp.Id+"/join?key="))
+			assert.NotEqual(t, resp.ModeratorUrl, resp.AttendeeUrl)
+
+			sc, err := st.Schedule(resp.Id)
+			require.NoError(t, err)
+			assert.NotEmpty(t, sc.Settings.ModeratorPass)
+			assert.NotEmpty(t, sc.Settings.AttendeePass)
+		})
+	}
+
+	assert.Zero(t, atomic.LoadInt32(&created), "meeting should not be created when scheduled")
+}
+
+func TestSchedules(t *testing.T) {
+	var created int32
+	app, st := scheduleTestApp(t, &created)
+	now := time.Now()
+	for _, sc := range []store.Schedule{
+		{Id: "sch01", Client: "lms", StartAt: now.Add(2 * time.Hour), EndAt: now.Add(3 * time.Hour)},
+		{Id: "sch02", Client: "lms", StartAt: now.Add(time.Hour), EndAt: now.Add(2 * time.Hour)},
+		{Id: "sch03", Client: "hr", StartAt: now, EndAt: now.Add(time.Hour)},
+		{Id: "sch04", Client: "lms", StartAt: now.Add(-2 * time.Hour), EndAt: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, st.SaveSchedule(sc))
+	}
+
+	list := func(t *testing.T, uri string) (ids []string) {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, uri, nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+		var resp struct {
+			Schedules []ScheduleResponse `json:"schedules"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+		for _, sc := range resp.Schedules {
+			ids = append(ids, sc.Id)
+		}
+		return
+	}
+
+	t.Run("List should return schedules that haven't closed ordered by start time", func(t *testing.T) {
+		assert.Equal(t, []string{"sch03", "sch02", "sch01"}, list(t, "/schedules"))
+	})
+
+	t.Run("Client should only list its own schedules", func(t *testing.T) {
+		assert.Equal(t, []string{"sch02", "sch01"}, list(t, "/own/schedules"))
+	})
+
+	testCases := []struct {
+		name   string
+		method string
+		uri    string
+		status int
+	}{
+		{name: "Get schedule of other client should not be found", method: fiber.MethodGet, uri: "/own/schedules/sch03", status: fiber.StatusNotFound},
+		{name: "Get unknown schedule should not be found", method: fiber.MethodGet, uri: "/schedules/unknown", status: fiber.StatusNotFound},
+		{name: "Get own schedule should be found", method: fiber.MethodGet, uri: "/own/schedules/sch01", status: fiber.StatusOK},
+		{name: "Delete schedule of other client should not be found", method: fiber.MethodDelete, uri: "/own/schedules/sch03", status: fiber.StatusNotFound},
+		{name: "Delete own schedule should cancel it", method: fiber.MethodDelete, uri: "/own/schedules/sch01", status: fiber.StatusNoContent},
+		{name: "Deleted schedule should not be found", method: fiber.MethodGet, uri: "/own/schedules/sch01", status: fiber.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest(tt.method, tt.uri, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
+
+func TestJoinSchedule(t *testing.T) {
+	var created int32
+	app, st := scheduleTestApp(t, &created)
+	now := time.Now()
+	settings := api.CreateMeeting{Name: "Class", MeetingId: "meet01", ModeratorPass: "mdrpw", AttendeePass: "attpw"}
+	for _, sc := range []store.Schedule{
+		{Id: "open", MeetingId: "meet01", StartAt: now.Add(-time.Minute), EndAt: now.Add(time.Hour), Settings: settings, ModeratorKey: "mdr", AttendeeKey: "att"},
+		{Id: "early", MeetingId: "meet02", StartAt: now.Add(time.Hour), EndAt: now.Add(2 * time.Hour), ModeratorKey: "mdr", AttendeeKey: "att"},
+		{Id: "late", MeetingId: "meet03", StartAt: now.Add(-2 * time.Hour), EndAt: now.Add(-time.Hour), ModeratorKey: "mdr", AttendeeKey: "att"},
+		{Id: "taken", MeetingId: "meet04", Client: "lms", StartAt: now.Add(-time.Minute), EndAt: now.Add(time.Hour), ModeratorKey: "mdr", AttendeeKey: "att"},
+	} {
+		require.NoError(t, st.SaveSchedule(sc))
+	}
+	// running meeting of other client w the same ID as the schedule.
+	require.NoError(t, st.SaveMeeting(store.Meeting{MeetingId: "meet04", Client: "hr", CreateTime: 1000, ModeratorPass: "hrmdr", AttendeePass: "hratt"}))
+
+	testCases := []struct {
+		name     string
+		uri      string
+		status   int
+		password string
+	}{
+		{name: "Unknown schedule should not be found", uri: "/schedules/unknown/join?key=mdr&name=NzK", status: fiber.StatusNotFound},
+		{name: "Invalid key should be rejected", uri: "/schedules/open/join?key=wrong&name=NzK", status: fiber.StatusForbidden},
+		{name: "Join w/o name should be rejected", uri: "/schedules/open/join?key=mdr", status: fiber.StatusBadRequest},
+		{name: "Join before the window should be rejected", uri: "/schedules/early/join?key=mdr&name=NzK", status: fiber.StatusTooEarly},
+		{name: "Join after the window should be rejected", uri: "/schedules/late/join?key=mdr&name=NzK", status: fiber.StatusGone},
+		{name: "Attendee join before moderator should be rejected", uri: "/schedules/open/join?key=att&name=Mhs", status: fiber.StatusConflict},
+		{name: "Attendee join to meeting of other client should be rejected", uri: "/schedules/taken/join?key=att&name=Mhs", status: fiber.StatusConflict},
+		{name: "Moderator join to meeting of other client should be rejected", uri: "/schedules/taken/join?key=mdr&name=NzK", status: fiber.StatusConflict},
+		{name: "Moderator join should create the meeting", uri: "/schedules/open/join?key=mdr&name=NzK&user_id=usr01", status: fiber.StatusFound, password: "mdrpw"},
+		{name: "Attendee join after moderator should join the meeting", uri: "/schedules/open/join?key=att&name=Mhs", status: fiber.StatusFound, password: "attpw"},
+		{name: "Next moderator join should not create the meeting again", uri: "/schedules/open/join?key=mdr&name=Dsn", status: fiber.StatusFound, password: "mdrpw"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.uri, nil))
+			require.NoError(t, err)
+			require.Equal(t, tt.status, res.StatusCode)
+			if tt.status != fiber.StatusFound {
+				return
+			}
+
+			loc, err := url.Parse(res.Header.Get("Location"))
+			require.NoError(t, err)
+			assert.Equal(t, "meet01", loc.Query().Get("meetingID"))
+			assert.Equal(t, tt.password, loc.Query().Get("password"))
+			assert.Equal(t, "121212", loc.Query().Get("createTime"))
+		})
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+}
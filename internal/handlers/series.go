@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// seriesIdPattern series ID that is safe to be a part of meeting ID and URL.
+var seriesIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// SeriesRequest format that needed to create a meeting series. Every field of create meeting
+// request could be given as the settings of every occurrence.
+type SeriesRequest struct {
+	api.CreateMeeting
+	SeriesId   string           `json:"series_id"`  // ID of the series, a part of meeting ID of every occurrence. Optional.
+	StartAt    time.Time        `json:"start_at"`   // Start of the first occurrence. Required.
+	Timezone   string           `json:"timezone"`   // IANA time zone of the occurrences, such as Asia/Jakarta. Optional.
+	Duration   int              `json:"duration"`   // How long every occurrence could be joined, in minutes. Required.
+	Recurrence store.Recurrence `json:"recurrence"` // Required.
+	Exceptions []string         `json:"exceptions"` // Dates (YYYYMMDD) that are skipped. Optional.
+}
+
+// OccurrenceResponse an occurrence of a series.
+type OccurrenceResponse struct {
+	MeetingId string    `json:"meeting_id"`
+	Date      string    `json:"date"`
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+}
+
+// SeriesResponse a meeting series along with its upcoming occurrences and join links.
+type SeriesResponse struct {
+	Id           string               `json:"id"`
+	Name         string               `json:"name"`
+	Client       string               `json:"client,omitempty"`
+	StartAt      time.Time            `json:"start_at"`
+	Timezone     string               `json:"timezone,omitempty"`
+	Duration     int                  `json:"duration"` // In minutes.
+	Recurrence   store.Recurrence     `json:"recurrence"`
+	Exceptions   []string             `json:"exceptions,omitempty"`
+	Upcoming     []OccurrenceResponse `json:"upcoming"`      // Occurrences within the schedule horizon.
+	ModeratorUrl string               `json:"moderator_url"` // Link to join the current occurrence as moderator. `name` query should be appended.
+	AttendeeUrl  string               `json:"attendee_url"`  // Link to join the current occurrence as attendee. `name` query should be appended.
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+// newSeriesResponse return the given series as response with its upcoming occurrences and
+// join links.
+func newSeriesResponse(conf *config.Model, sr store.Series) SeriesResponse {
+	now := time.Now()
+	upcoming := make([]OccurrenceResponse, 0)
+	for _, o := range sr.Occurrences(now, seriesHorizon(conf, now)) {
+		upcoming = append(upcoming, OccurrenceResponse{
+			MeetingId: sr.MeetingId(o),
+			Date:      o.Date,
+			StartAt:   o.StartAt,
+			EndAt:     o.EndAt,
+		})
+	}
+
+	return SeriesResponse{
+		Id:           sr.Id,
+		Name:         sr.Settings.Name,
+		Client:       sr.Client,
+		StartAt:      sr.StartAt,
+		Timezone:     sr.Timezone,
+		Duration:     int(sr.Duration / time.Minute),
+		Recurrence:   sr.Recurrence,
+		Exceptions:   sr.Exceptions,
+		Upcoming:     upcoming,
+		ModeratorUrl: seriesLink(conf, sr, true),
+		AttendeeUrl:  seriesLink(conf, sr, false),
+		CreatedAt:    sr.CreatedAt,
+	}
+}
+
+// seriesLink return link to join the current occurrence of the series as moderator or attendee.
+func seriesLink(conf *config.Model, sr store.Series, moderator bool) string {
+	key := sr.AttendeeKey
+	if moderator {
+		key = sr.ModeratorKey
+	}
+
+	return fmt.Sprintf("%s/series/%s/join?key=%s", conf.PublicUrl, url.PathEscape(sr.Id), key)
+}
+
+// seriesHorizon return the time until which occurrences are scheduled ahead.
+func seriesHorizon(conf *config.Model, now time.Time) time.Time {
+	return now.AddDate(0, 0, int(conf.SeriesHorizon))
+}
+
+// CreateSeries handler that receive json request to create a meeting series and save it to the
+// store along with the schedule of its upcoming occurrences, then send back the series with
+// its join links.
+func CreateSeries(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var req SeriesRequest
+		if err := c.BodyParser(&req); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to series object: %s", err),
+			})
+		}
+		if err := applyTemplate(conf, st, c.Body(), &req.CreateMeeting); err != nil {
+			return sendError(c, err)
+		}
+
+		sr, err := newSeries(conf, req, middlewares.Client(c))
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to create series: %s", err),
+			})
+		}
+
+		_, err = st.Series(sr.Id)
+		if err == nil {
+			c.Status(fiber.StatusConflict)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("series `%s` already exists", sr.Id),
+			})
+		}
+		if err != store.ErrNotFound {
+			return sendError(c, seriesError(err))
+		}
+
+		if err := st.SaveSeries(sr); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to save series: %s", err),
+			})
+		}
+
+		now := time.Now()
+		if err := st.ScheduleSeries(sr, now, seriesHorizon(conf, now)); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to schedule occurrences: %s", err),
+			})
+		}
+
+		c.Status(fiber.StatusCreated)
+		return c.JSON(newSeriesResponse(conf, sr))
+	}
+}
+
+// newSeries validate the request then return it as series that owned by the given client.
+// Series ID and passwords that are not given would be generated, so they're the same for
+// every occurrence.
+func newSeries(conf *config.Model, req SeriesRequest, clientName string) (store.Series, error) {
+	if req.Name == "" {
+		return store.Series{}, fmt.Errorf("`name` field is required")
+	}
+
+	randPass := randomString(conf)
+	randKey := service.SecureString{Length: scheduleKeyLen}
+
+	if req.SeriesId != "" && !seriesIdPattern.MatchString(req.SeriesId) {
+		return store.Series{}, fmt.Errorf("`series_id` must be at most 64 letters, digits, `_` or `-`")
+	}
+	// series ID is the prefix of its meeting IDs, so it's namespaced as them.
+	id := namespacedMeetingId(conf, clientName, req.SeriesId)
+	if id == "" {
+		// configured alphabet may have characters that series ID must not have.
+		id = (&service.SecureString{Length: int(conf.RandomLen)}).RandString()
+	}
+	if !seriesIdPattern.MatchString(id) {
+		return store.Series{}, fmt.Errorf("namespaced `series_id` %s must be at most 64 letters, digits, `_` or `-`", id)
+	}
+
+	settings := req.CreateMeeting
+	if settings.ModeratorPass == "" {
+		settings.ModeratorPass = randPass.RandString()
+	}
+	if settings.AttendeePass == "" {
+		settings.AttendeePass = randPass.RandString()
+	}
+
+	sr := store.Series{
+		Id:           id,
+		Client:       clientName,
+		StartAt:      req.StartAt,
+		Timezone:     req.Timezone,
+		Duration:     time.Duration(req.Duration) * time.Minute,
+		Recurrence:   req.Recurrence,
+		Exceptions:   req.Exceptions,
+		Settings:     settings,
+		ModeratorKey: randKey.RandString(),
+		AttendeeKey:  randKey.RandString(),
+	}
+	if err := sr.Validate(); err != nil {
+		return store.Series{}, err
+	}
+
+	return sr, nil
+}
+
+// GetSeries handler that send the series with ID in `id` param along with its upcoming
+// occurrences and join links.
+func GetSeries(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		sr, err := clientSeries(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		return c.JSON(newSeriesResponse(conf, sr))
+	}
+}
+
+// ListSeries handler that send every series ordered by their ID. Requester that authenticated
+// as a client would only get its own series.
+func ListSeries(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		own := middlewares.Client(c)
+
+		series := make([]SeriesResponse, 0)
+		err := st.EachSeries(func(sr store.Series) error {
+			if own != "" && sr.Client != own {
+				return nil
+			}
+			series = append(series, newSeriesResponse(conf, sr))
+			return nil
+		})
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to read series: %s", err),
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"series": series,
+		})
+	}
+}
+
+// DeleteSeries handler that remove the series with ID in `id` param along with the schedule of
+// its occurrences. Meetings that have been held are kept in the registry.
+func DeleteSeries(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		sr, err := clientSeries(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		var ids []string
+		err = st.EachSchedule(func(sc store.Schedule) error {
+			if sc.SeriesId == sr.Id {
+				ids = append(ids, sc.Id)
+			}
+			return nil
+		})
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to read schedules: %s", err),
+			})
+		}
+
+		for _, id := range ids {
+			if err := st.DeleteSchedule(id); err != nil {
+				c.Status(fiber.StatusInternalServerError)
+				return c.JSON(fiber.Map{
+					"message": fmt.Sprintf("failed to delete schedule: %s", err),
+				})
+			}
+		}
+
+		if err := st.DeleteSeries(sr.Id); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to delete series: %s", err),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// JoinSeries handler that redirect the requester to join the current occurrence of the series
+// with ID in `id` param, the same way as joining a scheduled meeting. Joining between
+// occurrences is rejected until the next one opens.
+func JoinSeries(conf *config.Model, hCl *http.Client, bus *event.Bus, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		hCl := requestClient(c, hCl)
+
+		sr, err := st.Series(c.Params("id"))
+		if err != nil {
+			return sendError(c, seriesError(err))
+		}
+
+		if _, err := joinRole(c.Query("key"), sr.ModeratorKey, sr.AttendeeKey); err != nil {
+			return sendError(c, err)
+		}
+
+		now := time.Now()
+		next := sr.Occurrences(now, now.AddDate(1, 0, 0))
+		if len(next) == 0 {
+			c.Status(fiber.StatusGone)
+			return c.JSON(fiber.Map{
+				"message": "series has no upcoming occurrence",
+			})
+		}
+
+		// prefer the schedule that has been saved, so the occurrence would be the same
+		// as the one listed in schedules.
+		sc, err := st.Schedule(sr.MeetingId(next[0]))
+		if err == store.ErrNotFound {
+			sc, err = sr.Schedule(next[0]), nil
+		}
+		if err != nil {
+			return sendError(c, scheduleError(err))
+		}
+
+		return joinSchedule(c, conf, hCl, bus, st, sc)
+	}
+}
+
+// SeriesAttendanceEntry attendance of a user across every occurrence of a series.
+type SeriesAttendanceEntry struct {
+	UserId    string   `json:"user_id"`
+	Name      string   `json:"name"`
+	Role      string   `json:"role"`
+	Attended  int      `json:"attended"`   // Number of occurrences the user joined.
+	TotalTime int64    `json:"total_time"` // In seconds, across every occurrence.
+	Meetings  []string `json:"meetings"`   // Meeting ID of the occurrences the user joined.
+}
+
+// SeriesAttendanceReport attendance of every user in the meetings of a series.
+type SeriesAttendanceReport struct {
+	SeriesId  string                  `json:"series_id"`
+	Name      string                  `json:"name"`
+	Meetings  int                     `json:"meetings"` // Number of occurrences that were held.
+	Attendees []SeriesAttendanceEntry `json:"attendees"`
+}
+
+// SeriesAttendance handler that send attendance of every user across the meetings that were
+// held as occurrences of the series with ID in `id` param, ordered by user ID.
+func SeriesAttendance(st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		sr, err := clientSeries(c, st, c.Params("id"))
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		var meets []store.Meeting
+		err = st.EachMeeting(func(m store.Meeting) error {
+			if m.SeriesId == sr.Id {
+				meets = append(meets, m)
+			}
+			return nil
+		})
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to read meetings: %s", err),
+			})
+		}
+
+		now := time.Now()
+		users := make(map[string]*SeriesAttendanceEntry)
+		for _, m := range meets {
+			atts, err := st.Attendances(m.Key)
+			if err != nil {
+				c.Status(fiber.StatusInternalServerError)
+				return c.JSON(fiber.Map{
+					"message": fmt.Sprintf("failed to get attendances: %s", err),
+				})
+			}
+
+			end := now
+			if m.EndedAt != nil {
+				end = *m.EndedAt
+			}
+			for _, a := range atts {
+				u, ok := users[a.UserId]
+				if !ok {
+					u = &SeriesAttendanceEntry{UserId: a.UserId}
+					users[a.UserId] = u
+				}
+				u.Name, u.Role = a.Name, a.Role
+				u.Attended++
+				u.TotalTime += int64(a.TotalTime(end) / time.Second)
+				u.Meetings = append(u.Meetings, m.MeetingId)
+			}
+		}
+
+		report := SeriesAttendanceReport{
+			SeriesId:  sr.Id,
+			Name:      sr.Settings.Name,
+			Meetings:  len(meets),
+			Attendees: make([]SeriesAttendanceEntry, 0, len(users)),
+		}
+		for _, u := range users {
+			report.Attendees = append(report.Attendees, *u)
+		}
+		sort.Slice(report.Attendees, func(i, j int) bool {
+			return report.Attendees[i].UserId < report.Attendees[j].UserId
+		})
+
+		return c.JSON(report)
+	}
+}
+
+// clientSeries return the series with the given ID. Series of other client is not found if the
+// requester authenticated as a client. Returned error is *fiber.Error.
+func clientSeries(c *fiber.Ctx, st *store.Store, id string) (store.Series, error) {
+	sr, err := st.Series(id)
+	if own := middlewares.Client(c); err == nil && own != "" && sr.Client != own {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		return store.Series{}, seriesError(err)
+	}
+
+	return sr, nil
+}
+
+// seriesError return *fiber.Error of the error from getting a series.
+func seriesError(err error) error {
+	if err == store.ErrNotFound {
+		return fiber.NewError(fiber.StatusNotFound, "series is not found")
+	}
+
+	return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get series: %s", err))
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesTestApp return app that serve series endpoints, with `/own` prefix to request
+// as `lms` client.
+func seriesTestApp(t *testing.T, created *int32) (*fiber.App, *store.Store) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.PublicUrl = "https://meet.example"
+	server := fakeCreateServer(t, created)
+	t.Cleanup(server.Close)
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	bus := event.NewBus()
+
+	app := fiber.New()
+	own := app.Group("/own", func(c *fiber.Ctx) error {
+		c.Locals(middlewares.ClientKey, "lms")
+		return c.Next()
+	})
+	for _, r := range []fiber.Router{app, own} {
+		r.Post("/series", CreateSeries(conf, st))
+		r.Get("/series", ListSeries(conf, st))
+		r.Get("/series/:id", GetSeries(conf, st))
+		r.Delete("/series/:id", DeleteSeries(st))
+		r.Get("/series/:id/attendance", SeriesAttendance(st))
+		r.Delete("/schedules/:id", DeleteSchedule(st))
+	}
+	app.Get("/series/:id/join", JoinSeries(conf, server.Client(), bus, st))
+
+	return app, st
+}
+
+func TestCreateSeries(t *testing.T) {
+	var created int32
+	app, st := seriesTestApp(t, &created)
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name:   "Series w/o name should be rejected",
+			body:   fmt.Sprintf(`{"start_at": %q, "duration": 60, "recurrence": {"freq": "daily"}}`, start.Format(time.RFC3339)),
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Series w invalid ID should be rejected",
+			body:   fmt.Sprintf(`{"name": "Math", "series_id": "math/101", "start_at": %q, "duration": 60, "recurrence": {"freq": "daily"}}`, start.Format(time.RFC3339)),
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Series w invalid recurrence should be rejected",
+			body:   fmt.Sprintf(`{"name": "Math", "start_at": %q, "duration": 60, "recurrence": {"freq": "yearly"}}`, start.Format(time.RFC3339)),
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Valid series should be created",
+			body:   fmt.Sprintf(`{"name": "Math", "series_id": "math101", "start_at": %q, "duration": 60, "recurrence": {"freq": "weekly"}}`, start.Format(time.RFC3339)),
+			status: fiber.StatusCreated,
+		},
+		{
+			name:   "Series w existing ID should conflict",
+			body:   fmt.Sprintf(`{"name": "Math", "series_id": "math101", "start_at": %q, "duration": 60, "recurrence": {"freq": "weekly"}}`, start.Format(time.RFC3339)),
+			status: fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/own/series", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+			if tt.status != fiber.StatusCreated {
+				return
+			}
+
+			var resp SeriesResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+			assert.Equal(t, "lms", resp.Client)
+			assert.Equal(t, 60, resp.Duration)
+			assert.True(t, strings.HasPrefix(resp.ModeratorUrl, "https://meet.example/series/math101/join?key="))
+			// default horizon is 14 days ahead.
+			require.Len(t, resp.Upcoming, 2)
+			first := "math101-" + start.Format("20060102")
+			assert.Equal(t, first, resp.Upcoming[0].MeetingId)
+
+			sc, err := st.Schedule(first)
+			require.NoError(t, err, "upcoming occurrence should be scheduled")
+			assert.Equal(t, "math101", sc.SeriesId)
+			assert.Equal(t, "lms", sc.Client)
+		})
+	}
+
+	assert.Zero(t, atomic.LoadInt32(&created), "meeting should not be created when scheduled")
+}
+
+func TestJoinSeries(t *testing.T) {
+	var created int32
+	app, st := seriesTestApp(t, &created)
+	now := time.Now()
+
+	save := func(t *testing.T, sr store.Series) {
+		sr.Duration = time.Hour
+		sr.ModeratorKey, sr.AttendeeKey = "mdr", "att"
+		sr.Settings.Name, sr.Settings.ModeratorPass, sr.Settings.AttendeePass = "Class", "mdrpw", "attpw"
+		require.NoError(t, sr.Validate())
+		require.NoError(t, st.SaveSeries(sr))
+	}
+	save(t, store.Series{Id: "open", StartAt: now.Add(-time.Minute), Recurrence: store.Recurrence{Freq: store.Daily}})
+	save(t, store.Series{Id: "early", StartAt: now.Add(time.Hour), Recurrence: store.Recurrence{Freq: store.Daily}})
+	save(t, store.Series{Id: "over", StartAt: now.AddDate(0, 0, -3), Recurrence: store.Recurrence{Freq: store.Daily, Count: 2}})
+
+	testCases := []struct {
+		name   string
+		uri    string
+		status int
+	}{
+		{name: "Unknown series should not be found", uri: "/series/unknown/join?key=mdr&name=NzK", status: fiber.StatusNotFound},
+		{name: "Invalid key should be rejected", uri: "/series/over/join?key=wrong&name=NzK", status: fiber.StatusForbidden},
+		{name: "Series w/o upcoming occurrence should be gone", uri: "/series/over/join?key=mdr&name=NzK", status: fiber.StatusGone},
+		{name: "Join before the next occurrence should be rejected", uri: "/series/early/join?key=mdr&name=NzK", status: fiber.StatusTooEarly},
+		{name: "Moderator join should create the current occurrence", uri: "/series/open/join?key=mdr&name=NzK", status: fiber.StatusFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.uri, nil))
+			require.NoError(t, err)
+			require.Equal(t, tt.status, res.StatusCode)
+			if tt.status != fiber.StatusFound {
+				return
+			}
+
+			loc, err := url.Parse(res.Header.Get("Location"))
+			require.NoError(t, err)
+			meetingId := "open-" + now.Add(-time.Minute).Format("20060102")
+			assert.Equal(t, meetingId, loc.Query().Get("meetingID"))
+			assert.Equal(t, "mdrpw", loc.Query().Get("password"))
+
+			m, err := st.Meeting(meetingId)
+			require.NoError(t, err)
+			assert.Equal(t, "open", m.SeriesId, "meeting should be grouped to its series")
+		})
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+}
+
+func TestSeriesOccurrences(t *testing.T) {
+	var created int32
+	app, st := seriesTestApp(t, &created)
+	start := time.Now().Add(time.Hour)
+	sr := store.Series{Id: "math", Client: "lms", StartAt: start, Duration: time.Hour, Recurrence: store.Recurrence{Freq: store.Daily}}
+	require.NoError(t, sr.Validate())
+	require.NoError(t, st.SaveSeries(sr))
+	require.NoError(t, st.ScheduleSeries(sr, time.Now(), start.AddDate(0, 0, 3)))
+
+	first := sr.MeetingId(sr.Occurrences(time.Now(), start.Add(time.Minute))[0])
+	at := func(min int) *time.Time {
+		t := start.Add(time.Duration(min) * time.Minute)
+		return &t
+	}
+	for _, m := range []store.Meeting{
+		{MeetingId: "math-20220307", SeriesId: "math", Client: "lms", CreateTime: 1, StartedAt: at(0), EndedAt: at(60)},
+		{MeetingId: "math-20220308", SeriesId: "math", Client: "lms", CreateTime: 2, StartedAt: at(0), EndedAt: at(60)},
+		{MeetingId: "other", Client: "lms", CreateTime: 3, StartedAt: at(0), EndedAt: at(60)},
+	} {
+		require.NoError(t, st.SaveMeeting(m))
+		// usr01 attend both occurrences for 30 minutes each, usr02 only the second.
+		require.NoError(t, st.Record(event.Event{Type: event.UserJoined, MeetingId: m.MeetingId, Timestamp: *at(0), User: &event.User{Id: "usr01", Name: "Mhs"}}))
+		require.NoError(t, st.Record(event.Event{Type: event.UserLeft, MeetingId: m.MeetingId, Timestamp: *at(30), User: &event.User{Id: "usr01"}}))
+	}
+	require.NoError(t, st.Record(event.Event{Type: event.UserJoined, MeetingId: "math-20220308", Timestamp: *at(0), User: &event.User{Id: "usr02", Name: "Dsn"}}))
+	require.NoError(t, st.Record(event.Event{Type: event.UserLeft, MeetingId: "math-20220308", Timestamp: *at(10), User: &event.User{Id: "usr02"}}))
+
+	t.Run("Attendance should be grouped across occurrences", func(t *testing.T) {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/own/series/math/attendance", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var report SeriesAttendanceReport
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+		assert.Equal(t, 2, report.Meetings)
+		require.Len(t, report.Attendees, 2)
+		assert.Equal(t, "usr01", report.Attendees[0].UserId)
+		assert.Equal(t, 2, report.Attendees[0].Attended)
+		assert.Equal(t, int64(60*60), report.Attendees[0].TotalTime)
+		assert.Equal(t, []string{"math-20220308"}, report.Attendees[1].Meetings)
+	})
+
+	t.Run("Cancelled occurrence should be added to exceptions", func(t *testing.T) {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/own/schedules/"+first, nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusNoContent, res.StatusCode)
+
+		got, err := st.Series("math")
+		require.NoError(t, err)
+		assert.Equal(t, []string{strings.TrimPrefix(first, "math-")}, got.Exceptions)
+
+		require.NoError(t, st.ScheduleSeries(got, time.Now(), start.AddDate(0, 0, 3)))
+		_, err = st.Schedule(first)
+		assert.Equal(t, store.ErrNotFound, err, "cancelled occurrence should not be scheduled again")
+	})
+
+	t.Run("Series of other client should not be found", func(t *testing.T) {
+		other := sr
+		other.Id, other.Client = "hr", "hr"
+		require.NoError(t, st.SaveSeries(other))
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/own/series/hr", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Deleted series should remove its schedules", func(t *testing.T) {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/own/series/math", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusNoContent, res.StatusCode)
+
+		require.NoError(t, st.EachSchedule(func(sc store.Schedule) error {
+			assert.NotEqual(t, "math", sc.SeriesId)
+			return nil
+		}))
+		_, err = st.Meeting("math-20220307")
+		assert.NoError(t, err, "held meetings should be kept")
+	})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/preset"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+// Sources of templates.
+const (
+	templateFromConfig = "config"
+	templateFromApi    = "api"
+)
+
+// TemplateResponse a template along with where it was defined.
+type TemplateResponse struct {
+	Name      string          `json:"name"`
+	Source    string          `json:"source"` // Either config or api. Template from config could not be changed through the API.
+	Settings  preset.Settings `json:"settings"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
+}
+
+// ListTemplates handler that send every template, from config and from the API, ordered by
+// their name.
+func ListTemplates(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		templates := make([]TemplateResponse, 0, len(conf.Templates))
+		for name, settings := range conf.Templates {
+			templates = append(templates, TemplateResponse{Name: name, Source: templateFromConfig, Settings: settings})
+		}
+
+		err := st.EachTemplate(func(t store.Template) error {
+			// template from config take precedence.
+			if _, ok := conf.Templates[t.Name]; !ok {
+				templates = append(templates, newTemplateResponse(t))
+			}
+			return nil
+		})
+		if err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to read templates: %s", err),
+			})
+		}
+
+		sort.Slice(templates, func(i, j int) bool {
+			return templates[i].Name < templates[j].Name
+		})
+
+		return c.JSON(fiber.Map{
+			"templates": templates,
+		})
+	}
+}
+
+// GetTemplate handler that send the template with name in `name` param.
+func GetTemplate(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		name := c.Params("name")
+		if settings, ok := conf.Templates[name]; ok {
+			return c.JSON(TemplateResponse{Name: name, Source: templateFromConfig, Settings: settings})
+		}
+
+		t, err := st.Template(name)
+		if err != nil {
+			return sendError(c, templateError(err))
+		}
+
+		return c.JSON(newTemplateResponse(t))
+	}
+}
+
+// PutTemplate handler that create or replace the template with name in `name` param using the
+// settings in json request. Template from config could not be replaced.
+func PutTemplate(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		// the name is kept by the store, so it must not refer to the reused request buffer.
+		name := utils.CopyString(c.Params("name"))
+		if _, ok := conf.Templates[name]; ok {
+			c.Status(fiber.StatusConflict)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("template `%s` is defined in config", name),
+			})
+		}
+
+		var settings preset.Settings
+		if err := json.Unmarshal(c.Body(), &settings); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to template settings: %s", err),
+			})
+		}
+		if err := settings.Validate(); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("template is invalid: %s", err),
+			})
+		}
+
+		t := store.Template{Name: name, Settings: settings}
+		if err := st.SaveTemplate(t); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to save template: %s", err),
+			})
+		}
+
+		t, err := st.Template(name)
+		if err != nil {
+			return sendError(c, templateError(err))
+		}
+
+		return c.JSON(newTemplateResponse(t))
+	}
+}
+
+// DeleteTemplate handler that remove the template with name in `name` param. Template from
+// config could not be removed.
+func DeleteTemplate(conf *config.Model, st *store.Store) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		name := c.Params("name")
+		if _, ok := conf.Templates[name]; ok {
+			c.Status(fiber.StatusConflict)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("template `%s` is defined in config", name),
+			})
+		}
+
+		if _, err := st.Template(name); err != nil {
+			return sendError(c, templateError(err))
+		}
+
+		if err := st.DeleteTemplate(name); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to delete template: %s", err),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// newTemplateResponse return the template that was defined through the API as response.
+func newTemplateResponse(t store.Template) TemplateResponse {
+	updatedAt := t.UpdatedAt
+	return TemplateResponse{Name: t.Name, Source: templateFromApi, Settings: t.Settings, UpdatedAt: &updatedAt}
+}
+
+// applyTemplate replace the create meeting request with its json body merged onto its template,
+// if it asks for one. Returned error is *fiber.Error.
+func applyTemplate(conf *config.Model, st *store.Store, body []byte, cMeet *api.CreateMeeting) error {
+	if cMeet.Template == "" {
+		return nil
+	}
+
+	settings, ok := conf.Templates[cMeet.Template]
+	if !ok {
+		t, err := st.Template(cMeet.Template)
+		if err == store.ErrNotFound {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("template `%s` is not found", cMeet.Template))
+		}
+		if err != nil {
+			return templateError(err)
+		}
+		settings = t.Settings
+	}
+
+	merged, err := settings.Apply(body)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("failed to apply template: %s", err))
+	}
+	*cMeet = merged
+
+	return nil
+}
+
+// templateError return *fiber.Error of the error from getting a template.
+func templateError(err error) error {
+	if err == store.ErrNotFound {
+		return fiber.NewError(fiber.StatusNotFound, "template is not found")
+	}
+
+	return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("failed to get template: %s", err))
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/preset"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	conf.Templates = map[string]preset.Settings{
+		"exam": {"mute_on_start": true, "lock_settings": map[string]interface{}{"disable_cam": true}},
+	}
+	require.NoError(t, conf.Sanitization())
+
+	st := store.New(store.NewMemory())
+	app := fiber.New()
+	app.Get("/templates", ListTemplates(conf, st))
+	app.Get("/templates/:name", GetTemplate(conf, st))
+	app.Put("/templates/:name", PutTemplate(conf, st))
+	app.Delete("/templates/:name", DeleteTemplate(conf, st))
+
+	request := func(t *testing.T, method, uri, body string) *http.Response {
+		req := httptest.NewRequest(method, uri, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		return res
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		uri    string
+		body   string
+		expect int
+	}{
+		{name: "Put template", method: fiber.MethodPut, uri: "/templates/lecture", body: `{"is_recording": true, "max_participant": 100}`, expect: fiber.StatusOK},
+		{name: "Replace template", method: fiber.MethodPut, uri: "/templates/lecture", body: `{"is_recording": true, "max_participant": 200}`, expect: fiber.StatusOK},
+		{name: "Put template w unknown field", method: fiber.MethodPut, uri: "/templates/other", body: `{"unknown": true}`, expect: fiber.StatusBadRequest},
+		{name: "Put template w meeting ID", method: fiber.MethodPut, uri: "/templates/other", body: `{"meeting_id": "meet01"}`, expect: fiber.StatusBadRequest},
+		{name: "Put template that is in config", method: fiber.MethodPut, uri: "/templates/exam", body: `{}`, expect: fiber.StatusConflict},
+		{name: "Get template from config", method: fiber.MethodGet, uri: "/templates/exam", expect: fiber.StatusOK},
+		{name: "Get unknown template", method: fiber.MethodGet, uri: "/templates/other", expect: fiber.StatusNotFound},
+		{name: "Delete template that is in config", method: fiber.MethodDelete, uri: "/templates/exam", expect: fiber.StatusConflict},
+		{name: "Delete unknown template", method: fiber.MethodDelete, uri: "/templates/other", expect: fiber.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			res := request(t, tt.method, tt.uri, tt.body)
+			assert.Equal(t, tt.expect, res.StatusCode)
+		})
+	}
+
+	t.Run("List should have templates from config and API", func(t *testing.T) {
+		res := request(t, fiber.MethodGet, "/templates", "")
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var body struct {
+			Templates []TemplateResponse `json:"templates"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		require.Len(t, body.Templates, 2)
+		assert.Equal(t, "exam", body.Templates[0].Name)
+		assert.Equal(t, templateFromConfig, body.Templates[0].Source)
+		assert.Equal(t, "lecture", body.Templates[1].Name)
+		assert.Equal(t, templateFromApi, body.Templates[1].Source)
+		assert.EqualValues(t, 200, body.Templates[1].Settings["max_participant"])
+		assert.NotNil(t, body.Templates[1].UpdatedAt)
+	})
+
+	t.Run("Delete template from API", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusNoContent, request(t, fiber.MethodDelete, "/templates/lecture", "").StatusCode)
+		assert.Equal(t, fiber.StatusNotFound, request(t, fiber.MethodGet, "/templates/lecture", "").StatusCode)
+	})
+}
+
+func TestCreateMeeting_Template(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	conf.Templates = map[string]preset.Settings{
+		"exam": {
+			"mute_on_start":   true,
+			"max_participant": 30,
+			"lock_settings":   map[string]interface{}{"disable_cam": true, "disable_private_chat": true},
+		},
+	}
+	require.NoError(t, conf.Sanitization())
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query = req.URL.RawQuery
+		xm, err := xml.Marshal(&api.CreateMeetingResponse{MeetingId: "fake-id"})
+		require.NoError(t, err)
+		_, err = rw.Write(xm)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+	conf.BBB.Host = server.URL
+	require.NoError(t, conf.BBB.Sanitization())
+
+	st := store.New(store.NewMemory())
+	require.NoError(t, st.SaveTemplate(store.Template{Name: "lecture", Settings: preset.Settings{"is_recording": true}}))
+
+	app := fiber.New()
+	app.Post("/meeting", CreateMeeting(conf, server.Client(), event.NewBus(), st))
+
+	testCases := []struct {
+		name     string
+		body     string
+		expect   int
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "Template from config should be applied",
+			body:     `{"name": "Final", "template": "exam"}`,
+			expect:   fiber.StatusCreated,
+			contains: []string{"muteOnStart=true", "maxParticipants=30", "lockSettingsDisableCam=true", "lockSettingsDisablePrivateChat=true"},
+		},
+		{
+			name:     "Request should override template field by field",
+			body:     `{"name": "Final", "template": "exam", "max_participant": 50, "lock_settings": {"disable_cam": false}}`,
+			expect:   fiber.StatusCreated,
+			contains: []string{"muteOnStart=true", "maxParticipants=50", "lockSettingsDisablePrivateChat=true"},
+			excludes: []string{"lockSettingsDisableCam"},
+		},
+		{
+			name:     "Template from API should be applied",
+			body:     `{"name": "Lecture", "template": "lecture"}`,
+			expect:   fiber.StatusCreated,
+			contains: []string{"record=true"},
+		},
+		{
+			name:   "Unknown template should be rejected",
+			body:   `{"name": "Final", "template": "unknown"}`,
+			expect: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			query = ""
+			req := httptest.NewRequest(fiber.MethodPost, "/meeting", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, res.StatusCode)
+			for _, s := range tt.contains {
+				assert.Contains(t, query, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, query, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+)
+
+// BBBWebhook handler that receive POST request from bbb-webhooks, validate the checksum
+// using the registered callback url then publish every event to the bus after normalized.
+func BBBWebhook(conf *config.Model, bus *event.Bus) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var payload api.WebhookPayload
+		if err := c.BodyParser(&payload); err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to bind request to webhook payload object: %s", err),
+			})
+		}
+
+		sum, err := payload.Checksum(conf.CallbackOnWebhookThisApp, conf.BBB.Secret)
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to calculate webhook checksum: %s", err),
+			})
+		}
+
+		if subtle.ConstantTimeCompare([]byte(sum), []byte(c.Query("checksum"))) != 1 {
+			c.Status(fiber.StatusUnauthorized)
+			return c.JSON(fiber.Map{
+				"message": "checksum doesn't match",
+			})
+		}
+
+		events, err := payload.ParseEvents()
+		if err != nil {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to parse webhook events: %s", err),
+			})
+		}
+
+		for _, e := range events {
+			bus.Publish(event.FromWebhook(e))
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
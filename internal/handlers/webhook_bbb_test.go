@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleWebhookEvents = `[{"data":{"type":"event","id":"user-joined","attributes":{"meeting":{"internal-meeting-id":"int01","external-meeting-id":"meet01"},"user":{"internal-user-id":"w_1","external-user-id":"mhs 01","name":"NzK","role":"VIEWER","presenter":false}},"event":{"ts":1502810164922}}}]`
+
+func TestBBBWebhook(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+	conf.CallbackOnWebhookThisApp = "http://localhost/webhooks/bbb"
+
+	bus := event.NewBus()
+	ch, unsub := bus.Subscribe(4, event.ByTypes(event.UserJoined))
+	defer unsub()
+
+	app := fiber.New()
+	app.Post("/webhooks/bbb", BBBWebhook(conf, bus))
+
+	payload := api.WebhookPayload{Event: sampleWebhookEvents, Timestamp: "1502810164922", Domain: "bbb.test"}
+	sum, err := payload.Checksum(conf.CallbackOnWebhookThisApp, conf.BBB.Secret)
+	require.NoError(t, err)
+
+	empty := api.WebhookPayload{Timestamp: "1"}
+	emptySum, err := empty.Checksum(conf.CallbackOnWebhookThisApp, conf.BBB.Secret)
+	require.NoError(t, err)
+
+	form := url.Values{}
+	form.Set("event", payload.Event)
+	form.Set("timestamp", payload.Timestamp)
+	form.Set("domain", payload.Domain)
+
+	testCases := []struct {
+		name     string
+		checksum string
+		body     string
+		expect   int
+	}{
+		{
+			name:     "Error if checksum doesn't match",
+			checksum: "wrong",
+			body:     form.Encode(),
+			expect:   fiber.StatusUnauthorized,
+		},
+		{
+			name:     "Error if there is no event in payload",
+			checksum: emptySum,
+			body:     "timestamp=1",
+			expect:   fiber.StatusBadRequest,
+		},
+		{
+			name:     "Success if checksum does match",
+			checksum: sum,
+			body:     form.Encode(),
+			expect:   fiber.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/webhooks/bbb?checksum="+tc.checksum, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", fiber.MIMEApplicationForm)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, res.StatusCode)
+		})
+	}
+
+	t.Run("Event should be published to the bus after normalized", func(t *testing.T) {
+		require.Len(t, ch, 1)
+		e := <-ch
+		assert.Equal(t, event.UserJoined, e.Type)
+		assert.Equal(t, "meet01", e.MeetingId)
+		assert.Equal(t, "int01", e.InternalMeetingId)
+		require.NotNil(t, e.User)
+		assert.Equal(t, "mhs 01", e.User.Id)
+		assert.Equal(t, "VIEWER", e.User.Role)
+	})
+}
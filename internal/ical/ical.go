@@ -0,0 +1,292 @@
+// Package ical write events as iCalendar (RFC 5545) so they could be imported to or
+// subscribed by calendar apps.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	prodId      = "-//kurvaid//bbb-interface//EN"
+	localFormat = "20060102T150405"
+	utcFormat   = "20060102T150405Z"
+	// lineLen maximum octets of a line before it's folded.
+	lineLen = 75
+	// recurYears how many years before and after now the time zone of recurring events is described.
+	recurYears = 5
+)
+
+// Event a VEVENT of the calendar. Times are written in the location of Start, so
+// recurrence follows the local time of that location across daylight saving changes.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	RRule       string      // Recurrence rule w/o `RRULE:` prefix, such as FREQ=WEEKLY;BYDAY=MO. Optional.
+	ExDates     []time.Time // Start of the occurrences that are skipped.
+	Created     time.Time
+}
+
+// Calendar a VCALENDAR of events.
+type Calendar struct {
+	Name   string    // Name that calendar apps show for a subscribed calendar.
+	Stamp  time.Time // When the calendar was written. Default to now.
+	Events []Event
+}
+
+// Bytes return the calendar as iCalendar object.
+func (cal *Calendar) Bytes() []byte {
+	stamp := cal.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + prodId)
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME:" + escape(cal.Name))
+	}
+
+	// describe every time zone that is referred by the events.
+	zones := make(map[string]*zoneRange)
+	var names []string
+	for _, e := range cal.Events {
+		id, ok := tzid(e.Start.Location())
+		if !ok {
+			continue
+		}
+		z, ok := zones[id]
+		if !ok {
+			z = &zoneRange{loc: e.Start.Location(), years: make(map[int]bool)}
+			zones[id] = z
+			names = append(names, id)
+		}
+
+		z.add(e.Start.Year(), minInt(e.End.Year(), e.Start.Year()+recurYears))
+		z.add(e.End.Year(), e.End.Year())
+		// occurrences of recurring events are only described around now, so events that
+		// started long ago don't make the time zone as long.
+		if e.RRule != "" {
+			now := maxInt(stamp.Year(), e.Start.Year())
+			z.add(maxInt(now-recurYears, e.Start.Year()), now+recurYears)
+		}
+	}
+	sort.Strings(names)
+	for _, id := range names {
+		w.timezone(id, zones[id])
+	}
+
+	for _, e := range cal.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + e.UID)
+		w.line("DTSTAMP:" + stamp.UTC().Format(utcFormat))
+		if !e.Created.IsZero() {
+			w.line("CREATED:" + e.Created.UTC().Format(utcFormat))
+		}
+		w.line("DTSTART" + timeValue(e.Start, e.Start.Location()))
+		w.line("DTEND" + timeValue(e.End, e.Start.Location()))
+		if e.RRule != "" {
+			w.line("RRULE:" + e.RRule)
+		}
+		for _, ex := range e.ExDates {
+			w.line("EXDATE" + timeValue(ex, e.Start.Location()))
+		}
+		w.line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			w.line("DESCRIPTION:" + escape(e.Description))
+		}
+		if e.URL != "" {
+			w.line("URL:" + e.URL)
+		}
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+
+	return w.buf.Bytes()
+}
+
+// zoneRange years that a time zone should be described for.
+type zoneRange struct {
+	loc   *time.Location
+	years map[int]bool
+}
+
+// add the years from first to last, both included.
+func (z *zoneRange) add(first, last int) {
+	for y := first; y <= last; y++ {
+		z.years[y] = true
+	}
+}
+
+// tzid return ID of the given location to be referred by TZID. Times in location without ID,
+// such as UTC and the system's local time, are written in UTC instead.
+func tzid(loc *time.Location) (string, bool) {
+	switch name := loc.String(); name {
+	case "UTC", "Local":
+		return "", false
+	case "":
+		_, offset := time.Date(2000, 1, 1, 0, 0, 0, 0, loc).Zone()
+		if offset == 0 {
+			return "", false
+		}
+		return "UTC" + formatOffset(offset), true
+	default:
+		return name, true
+	}
+}
+
+// timeValue return parameters and value of a time property, in local time of the location
+// if it has ID, otherwise in UTC.
+func timeValue(t time.Time, loc *time.Location) string {
+	id, ok := tzid(loc)
+	if !ok {
+		return ":" + t.UTC().Format(utcFormat)
+	}
+
+	return fmt.Sprintf(";TZID=%s:%s", id, t.In(loc).Format(localFormat))
+}
+
+// writer write content lines of iCalendar object.
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line write content line ended with CRLF. Line that is longer than 75 octets is folded
+// without splitting a character.
+func (w *writer) line(s string) {
+	n := 0
+	for len(s) > 0 {
+		_, size := utf8.DecodeRuneInString(s)
+		if n+size > lineLen {
+			w.buf.WriteString("\r\n ")
+			// the space of folded line is counted as well.
+			n = 1
+		}
+		w.buf.WriteString(s[:size])
+		n += size
+		s = s[size:]
+	}
+	w.buf.WriteString("\r\n")
+}
+
+// timezone write VTIMEZONE with every offset change of the location within the years of the
+// range. The offset in effect at the beginning of a year that follows the years that are not
+// described is written as well.
+func (w *writer) timezone(id string, z *zoneRange) {
+	years := make([]int, 0, len(z.years))
+	for y := range z.years {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + id)
+
+	var offset int
+	for i, y := range years {
+		from := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(y+1, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// the offset that is already in effect at the beginning of the range.
+		_, first := from.In(z.loc).Zone()
+		switch {
+		case i == 0:
+			offset = first
+			w.observance(from.In(z.loc), offset)
+		case years[i-1] != y-1 && first != offset:
+			w.observance(from.In(z.loc), offset)
+			offset = first
+		}
+
+		for t := from; t.Before(to); t = t.Add(time.Hour) {
+			next := t.Add(time.Hour)
+			_, nextOffset := next.In(z.loc).Zone()
+			if nextOffset == offset {
+				continue
+			}
+
+			// find the exact second the offset changed.
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.In(z.loc).Zone(); o == offset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			w.observance(hi.In(z.loc), offset)
+			offset = nextOffset
+		}
+	}
+
+	w.line("END:VTIMEZONE")
+}
+
+// observance write STANDARD or DAYLIGHT component that begin at the given time, changing
+// from the given offset.
+func (w *writer) observance(t time.Time, fromOffset int) {
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	abbr, offset := t.Zone()
+
+	w.line("BEGIN:" + kind)
+	// start of observance is written in the local time before it begins.
+	w.line("DTSTART:" + t.UTC().Add(time.Duration(fromOffset)*time.Second).Format(localFormat))
+	w.line("TZOFFSETFROM:" + formatOffset(fromOffset))
+	w.line("TZOFFSETTO:" + formatOffset(offset))
+	if abbr != "" {
+		w.line("TZNAME:" + escape(abbr))
+	}
+	w.line("END:" + kind)
+}
+
+// formatOffset return UTC offset in seconds as +HHMM.
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset%3600/60)
+}
+
+// escape escape text value so it's read literally.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,133 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unfold return content lines of iCalendar object after unfolding them.
+func unfold(t *testing.T, b []byte) []string {
+	s := string(b)
+	require.True(t, strings.HasSuffix(s, "\r\n"), "every line should end with CRLF")
+	for _, l := range strings.Split(s, "\r\n") {
+		assert.LessOrEqual(t, len(l), lineLen, "line should be folded")
+	}
+
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n ", ""), "\r\n"), "\r\n")
+}
+
+func TestCalendar_Bytes(t *testing.T) {
+	stamp := time.Date(2022, 2, 20, 8, 0, 0, 0, time.UTC)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		event    Event
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "Event in UTC should be written in UTC w/o time zone",
+			event: Event{
+				UID:   "sch01@meet.example",
+				Start: time.Date(2022, 2, 22, 3, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 2, 22, 4, 30, 0, 0, time.UTC),
+			},
+			contains: []string{"DTSTART:20220222T030000Z", "DTEND:20220222T043000Z", "DTSTAMP:20220220T080000Z"},
+			excludes: []string{"BEGIN:VTIMEZONE"},
+		},
+		{
+			name: "Event in fixed offset should refer its time zone",
+			event: Event{
+				UID:   "sch01@meet.example",
+				Start: time.Date(2022, 2, 22, 10, 0, 0, 0, time.FixedZone("", 7*60*60)),
+				End:   time.Date(2022, 2, 22, 11, 30, 0, 0, time.FixedZone("", 7*60*60)),
+			},
+			contains: []string{"TZID:UTC+0700", "TZOFFSETTO:+0700", "DTSTART;TZID=UTC+0700:20220222T100000"},
+			excludes: []string{"BEGIN:DAYLIGHT"},
+		},
+		{
+			name: "Recurring event should describe daylight saving of its time zone",
+			event: Event{
+				UID:     "series-math@meet.example",
+				Start:   time.Date(2022, 3, 7, 10, 0, 0, 0, newYork),
+				End:     time.Date(2022, 3, 7, 11, 0, 0, 0, newYork),
+				RRule:   "FREQ=WEEKLY;BYDAY=MO;WKST=MO",
+				ExDates: []time.Time{time.Date(2022, 3, 14, 10, 0, 0, 0, newYork)},
+			},
+			contains: []string{
+				"TZID:America/New_York",
+				// daylight saving begins at 02:00 EST on 13 March 2022.
+				"BEGIN:DAYLIGHT", "DTSTART:20220313T020000", "TZOFFSETFROM:-0500", "TZOFFSETTO:-0400",
+				"DTSTART;TZID=America/New_York:20220307T100000",
+				"RRULE:FREQ=WEEKLY;BYDAY=MO;WKST=MO",
+				"EXDATE;TZID=America/New_York:20220314T100000",
+			},
+		},
+		{
+			name: "Text should be escaped and long line should be folded",
+			event: Event{
+				UID:         "sch01@meet.example",
+				Summary:     "Math, Physics; and Chemistry",
+				Description: "Join the meeting:\nhttps://meet.example/schedules/sch01/join?key=someRandomAttendeeKeyThatIsQuiteLong",
+				Start:       time.Date(2022, 2, 22, 3, 0, 0, 0, time.UTC),
+				End:         time.Date(2022, 2, 22, 4, 0, 0, 0, time.UTC),
+			},
+			contains: []string{
+				`SUMMARY:Math\, Physics\; and Chemistry`,
+				`DESCRIPTION:Join the meeting:\nhttps://meet.example/schedules/sch01/join?key=someRandomAttendeeKeyThatIsQuiteLong`,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := Calendar{Name: "lms", Stamp: stamp, Events: []Event{tt.event}}
+			lines := unfold(t, cal.Bytes())
+
+			assert.Equal(t, "BEGIN:VCALENDAR", lines[0])
+			assert.Equal(t, "END:VCALENDAR", lines[len(lines)-1])
+			for _, s := range tt.contains {
+				assert.Contains(t, lines, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, lines, s)
+			}
+		})
+	}
+}
+
+func TestCalendar_BytesRecurringLongAgo(t *testing.T) {
+	stamp := time.Date(2022, 2, 20, 8, 0, 0, 0, time.UTC)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	cal := Calendar{Stamp: stamp, Events: []Event{{
+		UID:   "series-old@meet.example",
+		Start: time.Date(1, 3, 7, 10, 0, 0, 0, newYork),
+		End:   time.Date(1, 3, 7, 11, 0, 0, 0, newYork),
+		RRule: "FREQ=DAILY",
+	}}}
+
+	begin := time.Now()
+	lines := unfold(t, cal.Bytes())
+	assert.Less(t, time.Since(begin), time.Second)
+
+	var starts []string
+	for _, l := range lines {
+		if strings.HasPrefix(l, "DTSTART:") {
+			starts = append(starts, l)
+		}
+	}
+	// the offset at the first occurrence, the one in effect when the described years begin,
+	// then the daylight saving changes of 2017 to 2027.
+	require.Len(t, starts, 2+2*11)
+	assert.Equal(t, "DTSTART:20170312T020000", starts[2])
+	assert.Equal(t, "DTSTART:20271107T020000", starts[len(starts)-1])
+}
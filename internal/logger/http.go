@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RequestIdHeader header that carry the ID of the request to this app, along the calls to BBB
+// API and the callbacks to clients that are made while handling it.
+const RequestIdHeader = "X-Request-ID"
+
+// Redacted value that replace secrets in the logs.
+const Redacted = "REDACTED"
+
+// secretParams query parameters of BBB API and this app that hold secrets, in lower case.
+var secretParams = map[string]bool{
+	"checksum":     true,
+	"password":     true,
+	"moderatorpw":  true,
+	"attendeepw":   true,
+	"sessiontoken": true,
+	"token":        true,
+	"secret":       true,
+	"key":          true,
+	"signature":    true,
+}
+
+// secretInText match secret query parameters that are embedded in text, such as the errors of
+// http.Client which have the url of the request.
+var secretInText = regexp.MustCompile(`(?i)\b(checksum|password|moderatorPW|attendeePW|sessionToken|token|secret|key|signature)=[^&\s"']*`)
+
+// RedactText return the given text with the values of secret query parameters in it replaced.
+func RedactText(s string) string {
+	return secretInText.ReplaceAllString(s, "${1}="+Redacted)
+}
+
+// RedactQuery return the given query string with the values of checksum, passwords, tokens,
+// keys and secrets replaced, names are matched case-insensitively. Query that could not be parsed
+// is redacted entirely.
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Redacted
+	}
+	for k := range q {
+		if secretParams[strings.ToLower(k)] {
+			q[k] = []string{Redacted}
+		}
+	}
+
+	// url.Values.Encode would escape the placeholder, which is safe as it is.
+	return strings.ReplaceAll(q.Encode(), url.QueryEscape(Redacted), Redacted)
+}
+
+// RedactUrl return the given url with its secret query parameters redacted, see RedactQuery.
+func RedactUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return Redacted
+	}
+	u.User = nil
+	u.RawQuery = RedactQuery(u.RawQuery)
+
+	return u.String()
+}
+
+// transport send the request ID along every request and log them, w/ their url redacted.
+type transport struct {
+	base      http.RoundTripper
+	requestId string
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// RoundTrip must not modify the given request.
+	r = r.Clone(r.Context())
+	r.Header.Set(RequestIdHeader, t.requestId)
+
+	start := time.Now()
+	res, err := t.base.RoundTrip(r)
+
+	f := Fields{
+		"request_id": t.requestId,
+		"method":     r.Method,
+		"url":        RedactUrl(r.URL.String()),
+		"latency_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		f["error"] = err
+		Warn("outgoing request failed", f)
+		return res, err
+	}
+	f["status"] = res.StatusCode
+	Debug("outgoing request", f)
+
+	return res, nil
+}
+
+// HTTPClient return copy of the given client that send the given request ID in RequestIdHeader
+// of every request and log them. The given client is returned as it is if the ID is empty.
+func HTTPClient(hCl *http.Client, requestId string) *http.Client {
+	if requestId == "" {
+		return hCl
+	}
+
+	base := hCl.Transport
+	if t, ok := base.(*transport); ok {
+		base = t.base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	c := *hCl
+	c.Transport = &transport{base: base, requestId: requestId}
+
+	return &c
+}
+
+// RequestIdOf return the request ID that the given client send, which was made by HTTPClient.
+func RequestIdOf(hCl *http.Client) string {
+	if t, ok := hCl.Transport.(*transport); ok {
+		return t.requestId
+	}
+
+	return ""
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Levels of log records, from the least severe.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+var severity = map[string]int{LevelDebug: 0, LevelInfo: 1, LevelWarn: 2, LevelError: 3}
+
+// Fields attributes of a log record such as request_id, client or meeting_id. Error values are
+// written as their message, w/ secret query parameters redacted.
+type Fields map[string]interface{}
+
+// Logger write every record that is not less severe than its level as one line of json, which
+// has time, level and msg followed by the fields in alphabetical order. Safe for concurrent use.
+type Logger struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	level  int
+	fields Fields
+}
+
+// L logger of this app, write to stderr until InitLogger is called.
+var L = New(os.Stderr, LevelInfo)
+
+// New return logger that write records of the given level or more severe to out. Unknown level
+// is info.
+func New(out io.Writer, level string) *Logger {
+	lv, ok := severity[level]
+	if !ok {
+		lv = severity[LevelInfo]
+	}
+
+	return &Logger{mu: &sync.Mutex{}, out: out, level: lv}
+}
+
+// With return logger that add the given fields to every record, along with the fields of this
+// logger. Both share the same output.
+func (l *Logger) With(f Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(f))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range f {
+		merged[k] = v
+	}
+
+	return &Logger{mu: l.mu, out: l.out, level: l.level, fields: merged}
+}
+
+// Debug write record of debug level.
+func (l *Logger) Debug(msg string, f Fields) { l.log(LevelDebug, msg, f) }
+
+// Info write record of info level.
+func (l *Logger) Info(msg string, f Fields) { l.log(LevelInfo, msg, f) }
+
+// Warn write record of warn level.
+func (l *Logger) Warn(msg string, f Fields) { l.log(LevelWarn, msg, f) }
+
+// Error write record of error level.
+func (l *Logger) Error(msg string, f Fields) { l.log(LevelError, msg, f) }
+
+// Log write record of the given level.
+func (l *Logger) Log(level, msg string, f Fields) { l.log(level, msg, f) }
+
+// Debug write record of debug level using L.
+func Debug(msg string, f Fields) { L.log(LevelDebug, msg, f) }
+
+// Info write record of info level using L.
+func Info(msg string, f Fields) { L.log(LevelInfo, msg, f) }
+
+// Warn write record of warn level using L.
+func Warn(msg string, f Fields) { L.log(LevelWarn, msg, f) }
+
+// Error write record of error level using L.
+func Error(msg string, f Fields) { L.log(LevelError, msg, f) }
+
+func (l *Logger) log(level, msg string, f Fields) {
+	if severity[level] < l.level {
+		return
+	}
+
+	fields := l.fields
+	if len(f) > 0 {
+		fields = l.With(f).fields
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// fields are written after time, level and msg, so records are easy to read as well.
+	buf := []byte(`{"time":`)
+	buf = appendValue(buf, time.Now().Format(time.RFC3339Nano))
+	buf = append(buf, `,"level":`...)
+	buf = appendValue(buf, level)
+	buf = append(buf, `,"msg":`...)
+	buf = appendValue(buf, msg)
+	for _, k := range keys {
+		switch k {
+		case "time", "level", "msg":
+			continue
+		}
+		buf = append(buf, ',')
+		buf = appendValue(buf, k)
+		buf = append(buf, ':')
+		buf = appendValue(buf, fields[k])
+	}
+	buf = append(buf, "}\n"...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.out.Write(buf)
+}
+
+// appendValue append the given value as json, errors as their message and values that could
+// not be marshaled as they're printed. Secrets in the url that errors of http.Client have are
+// redacted.
+func appendValue(buf []byte, v interface{}) []byte {
+	if err, ok := v.(error); ok {
+		v = RedactText(err.Error())
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+
+	return append(buf, b...)
+}
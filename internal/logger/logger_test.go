@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	testCases := []struct {
+		name   string
+		level  string
+		log    func(l *Logger)
+		expect []map[string]interface{}
+	}{
+		{
+			name:  "Record should be written as json w its fields",
+			level: LevelInfo,
+			log: func(l *Logger) {
+				l.Info("meeting created", Fields{"meeting_id": "math101", "attendees": 3})
+			},
+			expect: []map[string]interface{}{
+				{"level": "info", "msg": "meeting created", "meeting_id": "math101", "attendees": float64(3)},
+			},
+		},
+		{
+			name:  "Error should be written as its message",
+			level: LevelInfo,
+			log: func(l *Logger) {
+				l.Error("failed to poll", Fields{"error": errors.New("connection refused")})
+			},
+			expect: []map[string]interface{}{
+				{"level": "error", "msg": "failed to poll", "error": "connection refused"},
+			},
+		},
+		{
+			name:  "Secrets in url of error should be redacted",
+			level: LevelInfo,
+			log: func(l *Logger) {
+				l.Error("failed to end meeting", Fields{"error": errors.New(`Get "https://bbb.example.com/api/end?meetingID=math101&password=mod&checksum=abc": EOF`)})
+			},
+			expect: []map[string]interface{}{
+				{"error": `Get "https://bbb.example.com/api/end?meetingID=math101&password=REDACTED&checksum=REDACTED": EOF`},
+			},
+		},
+		{
+			name:  "Keys and signatures in url of error should be redacted",
+			level: LevelInfo,
+			log: func(l *Logger) {
+				l.Error("failed to send callback", Fields{"error": errors.New(`Get "https://meet.example/calendar/lms.ics?key=k3y&signature=s1g": EOF`)})
+			},
+			expect: []map[string]interface{}{
+				{"error": `Get "https://meet.example/calendar/lms.ics?key=REDACTED&signature=REDACTED": EOF`},
+			},
+		},
+		{
+			name:  "Record less severe than the level should not be written",
+			level: LevelWarn,
+			log: func(l *Logger) {
+				l.Info("skipped", nil)
+				l.Debug("skipped", nil)
+				l.Warn("written", nil)
+			},
+			expect: []map[string]interface{}{
+				{"level": "warn", "msg": "written"},
+			},
+		},
+		{
+			name:  "Fields of With should be added to every record",
+			level: LevelDebug,
+			log: func(l *Logger) {
+				rl := l.With(Fields{"request_id": "req-1"})
+				rl.Debug("first", nil)
+				rl.Info("second", Fields{"request_id": "req-2"})
+				l.Info("third", nil)
+			},
+			expect: []map[string]interface{}{
+				{"level": "debug", "msg": "first", "request_id": "req-1"},
+				{"level": "info", "msg": "second", "request_id": "req-2"},
+				{"level": "info", "msg": "third", "request_id": nil},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tt.log(New(buf, tt.level))
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			require.Len(t, lines, len(tt.expect))
+			for i, line := range lines {
+				var rec map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(line), &rec))
+				assert.NotEmpty(t, rec["time"])
+				for k, v := range tt.expect[i] {
+					assert.Equal(t, v, rec[k], k)
+				}
+			}
+		})
+	}
+}
+
+func TestLogger_FieldOrder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	New(buf, LevelInfo).Info("request", Fields{"status": 200, "client": "lms", "msg": "ignored"})
+
+	line := buf.String()
+	assert.Less(t, strings.Index(line, `"time"`), strings.Index(line, `"level"`))
+	assert.Less(t, strings.Index(line, `"msg":"request"`), strings.Index(line, `"client"`))
+	assert.Less(t, strings.Index(line, `"client"`), strings.Index(line, `"status"`))
+	assert.NotContains(t, line, "ignored")
+}
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+const namespace = "bbb_interface"
+
+// Results of BBB API calls and callback deliveries.
+const (
+	ResultSuccess = "success" // BBB API returned SUCCESS, or the callback endpoint accepted it.
+	ResultFailed  = "failed"  // BBB API returned FAILED, or the callback endpoint responded 4xx/5xx.
+	ResultError   = "error"   // The request could not be sent or its response could not be read.
+)
+
+// Kinds of callback that are delivered to client apps.
+const (
+	CallbackEvent   = "event"   // Meeting event to `callback_on_event` of a client.
+	CallbackDestroy = "destroy" // Ended meeting to `callback_on_destroy`.
+)
+
+// Registry every metric of this app is registered to, along with the metrics of Go runtime
+// and the process.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Requests received by this app, by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to respond requests, by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	bbbRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bbb_api_requests_total",
+		Help:      "Calls to BBB API, by host, call and result.",
+	}, []string{"host", "call", "result"})
+
+	bbbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "bbb_api_request_duration_seconds",
+		Help:      "Time taken by BBB API to respond, by host and call.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "call"})
+
+	joinUrls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bbb_join_urls_total",
+		Help:      "Join urls that were given to users, who call BBB API join with their browser, by host.",
+	}, []string{"host"})
+
+	callbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "callback_deliveries_total",
+		Help:      "Callbacks that were sent to client apps, by kind and result.",
+	}, []string{"kind", "result"})
+
+	meetingsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "meetings_created_total",
+		Help:      "Meetings created in BBB server, by client. Empty client is the main token.",
+	}, []string{"client"})
+
+	bbbUp               = capacityGauge("bbb_up", "Whether the last getMeetings call to BBB server succeeded, by host.")
+	bbbMeetings         = capacityGauge("bbb_meetings", "Running meetings in BBB server, by host.")
+	bbbParticipants     = capacityGauge("bbb_participants", "Participants of running meetings, by host.")
+	bbbVoice            = capacityGauge("bbb_voice_participants", "Participants who joined the voice conference, by host.")
+	bbbVideos           = capacityGauge("bbb_video_streams", "Webcams that are shared, by host.")
+	bbbListeners        = capacityGauge("bbb_listeners", "Participants who are listen only, by host.")
+	bbbRecordingEnabled = capacityGauge("bbb_recording_meetings", "Running meetings that were created with recording enabled, by host.")
+)
+
+// Capacity usage of a BBB server, summed over its running meetings.
+type Capacity struct {
+	Meetings          int
+	Participants      int
+	VoiceParticipants int
+	Videos            int
+	Listeners         int
+	RecordingMeetings int
+}
+
+func capacityGauge(name, help string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, []string{"host"})
+}
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		bbbRequests, bbbDuration, joinUrls,
+		callbacks,
+		meetingsCreated,
+		bbbUp, bbbMeetings, bbbParticipants, bbbVoice, bbbVideos, bbbListeners, bbbRecordingEnabled,
+	)
+}
+
+// ObserveRequest record a request that was responded with the given status code. Route is the
+// registered path such as /meetings/:id/guests, so every meeting ID is counted as one.
+func ObserveRequest(route, method string, status int, d time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(route, method, code).Inc()
+	httpDuration.WithLabelValues(route, method, code).Observe(d.Seconds())
+}
+
+// ObserveBBBCall record a call to BBB API such as create, end or isMeetingRunning, along with
+// one of the results.
+func ObserveBBBCall(host, call, result string, d time.Duration) {
+	bbbRequests.WithLabelValues(host, call, result).Inc()
+	bbbDuration.WithLabelValues(host, call).Observe(d.Seconds())
+}
+
+// JoinUrlIssued record a join url of the given BBB host that was given to a user.
+func JoinUrlIssued(host string) {
+	joinUrls.WithLabelValues(host).Inc()
+}
+
+// ObserveCallback record a delivery of one of the callback kinds along with one of the results.
+func ObserveCallback(kind, result string) {
+	callbacks.WithLabelValues(kind, result).Inc()
+}
+
+// MeetingCreated record a meeting created by the given client.
+func MeetingCreated(client string) {
+	meetingsCreated.WithLabelValues(client).Inc()
+}
+
+// SetCapacity record the capacity usage of the given BBB host, which is up.
+func SetCapacity(host string, c Capacity) {
+	bbbUp.WithLabelValues(host).Set(1)
+	bbbMeetings.WithLabelValues(host).Set(float64(c.Meetings))
+	bbbParticipants.WithLabelValues(host).Set(float64(c.Participants))
+	bbbVoice.WithLabelValues(host).Set(float64(c.VoiceParticipants))
+	bbbVideos.WithLabelValues(host).Set(float64(c.Videos))
+	bbbListeners.WithLabelValues(host).Set(float64(c.Listeners))
+	bbbRecordingEnabled.WithLabelValues(host).Set(float64(c.RecordingMeetings))
+}
+
+// CapacityDown record that capacity usage of the given BBB host could not be known. The last
+// known usage is kept.
+func CapacityDown(host string) {
+	bbbUp.WithLabelValues(host).Set(0)
+}
+
+// Host return the host of the given url, to label metrics of BBB server by it.
+func Host(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// Handler return fasthttp handler that serve every metric in Prometheus text format.
+func Handler() fasthttp.RequestHandler {
+	return fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserve(t *testing.T) {
+	ObserveRequest("/meetings/:id/guests", "GET", 200, 10*time.Millisecond)
+	ObserveRequest("/meetings/:id/guests", "GET", 200, 20*time.Millisecond)
+	ObserveBBBCall("bbb.example", "create", ResultFailed, time.Second)
+	JoinUrlIssued("bbb.example")
+	ObserveCallback(CallbackEvent, ResultError)
+	MeetingCreated("lms")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues("/meetings/:id/guests", "GET", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(bbbRequests.WithLabelValues("bbb.example", "create", ResultFailed)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(joinUrls.WithLabelValues("bbb.example")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(callbacks.WithLabelValues(CallbackEvent, ResultError)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(meetingsCreated.WithLabelValues("lms")))
+
+	problems, err := testutil.GatherAndLint(Registry)
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+
+	err = testutil.GatherAndCompare(Registry, strings.NewReader(`
+# HELP bbb_interface_meetings_created_total Meetings created in BBB server, by client. Empty client is the main token.
+# TYPE bbb_interface_meetings_created_total counter
+bbb_interface_meetings_created_total{client="lms"} 1
+`), "bbb_interface_meetings_created_total")
+	assert.NoError(t, err)
+}
+
+func TestHost(t *testing.T) {
+	testCases := []struct {
+		name   string
+		url    string
+		expect string
+	}{
+		{name: "Host of url should be returned", url: "https://bbb.example:8443/bigbluebutton/", expect: "bbb.example:8443"},
+		{name: "Invalid url should be empty", url: "://bbb", expect: ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, Host(tt.url))
+		})
+	}
+}
@@ -5,11 +5,34 @@ import (
 	"github.com/kurvaid/bbb-interface/internal/config"
 )
 
+// ClientKey key of the locals that hold the name of the authenticated client. Would
+// be empty if the request was authenticated using the main token.
+const ClientKey = "client"
+
 // Auth super simple middleware to check whether wanted Authorization token exist
-// and match with token in config.
+// and match with token in config or token of one of the clients.
 func Auth(conf *config.Model) func(ctx *fiber.Ctx) error {
+	return auth(conf, false)
+}
+
+// AuthQuery the same as Auth, but token could also be sent in `token` query for requesters
+// that can't set header such as browser's websocket. Should only be used for such routes,
+// since urls end up in logs and browser history.
+func AuthQuery(conf *config.Model) func(ctx *fiber.Ctx) error {
+	return auth(conf, true)
+}
+
+func auth(conf *config.Model, fromQuery bool) func(ctx *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		token := c.GetReqHeaders()["Authorization"]
+		if token == "" && fromQuery {
+			token = c.Query("token")
+		}
+
+		if cl, ok := conf.ClientByToken(token); ok {
+			c.Locals(ClientKey, cl.Name)
+			return c.Next()
+		}
 
 		if conf.Token != token {
 			c.Status(fiber.StatusBadRequest)
@@ -21,3 +44,24 @@ func Auth(conf *config.Model) func(ctx *fiber.Ctx) error {
 		return c.Next()
 	}
 }
+
+// Client return the name of the client that was authenticated by Auth.
+func Client(c *fiber.Ctx) string {
+	name, _ := c.Locals(ClientKey).(string)
+	return name
+}
+
+// Admin middleware that only let requests that were authenticated by Auth using the main
+// token through. Should be placed after Auth.
+func Admin() func(ctx *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if Client(c) != "" {
+			c.Status(fiber.StatusForbidden)
+			return c.JSON(fiber.Map{
+				"message": "only the main token is allowed",
+			})
+		}
+
+		return c.Next()
+	}
+}
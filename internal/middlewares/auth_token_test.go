@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"bytes"
+	"io"
 	"net/http/httptest"
 	"testing"
 
@@ -81,3 +82,145 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, fiber.StatusOK, res.StatusCode)
 	})
 }
+
+func TestAuthMiddleware_Clients(t *testing.T) {
+	conf := &config.Model{
+		Token:   "superSecret",
+		Clients: []config.Client{{Name: "lms", Token: "lmsSecret"}},
+	}
+	require.NoError(t, conf.Sanitization())
+
+	app := fiber.New()
+	app.Post("/auth",
+		Auth(conf),
+		func(c *fiber.Ctx) error {
+			return c.SendString(Client(c))
+		},
+	)
+
+	testCases := []struct {
+		name   string
+		token  string
+		query  string
+		status int
+		client string
+	}{
+		{
+			name:   "Main token should pass without client",
+			token:  "superSecret",
+			status: fiber.StatusOK,
+		},
+		{
+			name:   "Client's token should pass as that client",
+			token:  "lmsSecret",
+			status: fiber.StatusOK,
+			client: "lms",
+		},
+		{
+			name:   "Client's token in query should fail",
+			query:  "?token=lmsSecret",
+			status: fiber.StatusBadRequest,
+		},
+		{
+			name:   "Unknown token should fail",
+			token:  "secret",
+			status: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/auth"+tc.query, nil)
+			req.Header.Set("Authorization", tc.token)
+			res, err := app.Test(req)
+			require.NoError(t, err, "failed to initiate app test: ", err)
+			assert.Equal(t, tc.status, res.StatusCode)
+
+			if tc.status == fiber.StatusOK {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.client, string(body))
+			}
+		})
+	}
+}
+
+func TestAuthQueryMiddleware(t *testing.T) {
+	conf := &config.Model{
+		Token:   "superSecret",
+		Clients: []config.Client{{Name: "lms", Token: "lmsSecret"}},
+	}
+	require.NoError(t, conf.Sanitization())
+
+	app := fiber.New()
+	app.Get("/socket",
+		AuthQuery(conf),
+		func(c *fiber.Ctx) error {
+			return c.SendString(Client(c))
+		},
+	)
+
+	testCases := []struct {
+		name   string
+		token  string
+		query  string
+		status int
+		client string
+	}{
+		{name: "Client's token in query should pass as that client", query: "?token=lmsSecret", status: fiber.StatusOK, client: "lms"},
+		{name: "Client's token in header should pass as that client", token: "lmsSecret", status: fiber.StatusOK, client: "lms"},
+		{name: "Unknown token in query should fail", query: "?token=secret", status: fiber.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/socket"+tc.query, nil)
+			req.Header.Set("Authorization", tc.token)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, res.StatusCode)
+
+			if tc.status == fiber.StatusOK {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.client, string(body))
+			}
+		})
+	}
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	conf := &config.Model{
+		Token:   "superSecret",
+		Clients: []config.Client{{Name: "lms", Token: "lmsSecret"}},
+	}
+	require.NoError(t, conf.Sanitization())
+
+	app := fiber.New()
+	app.Post("/admin",
+		Auth(conf),
+		Admin(),
+		func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		},
+	)
+
+	testCases := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "Main token should pass", token: "superSecret", status: fiber.StatusOK},
+		{name: "Client's token should be forbidden", token: "lmsSecret", status: fiber.StatusForbidden},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodPost, "/admin", nil)
+			req.Header.Set("Authorization", tt.token)
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/service"
+	"github.com/kurvaid/bbb-interface/internal/store"
+)
+
+const (
+	// IdempotencyKeyHeader header that hold the key which identify retries of the same request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader header that is set on responses that were sent before.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+)
+
+// Idempotency middleware that send the saved response to requests that have the same
+// Idempotency-Key header as a previous successful one, instead of doing it again. Responses are
+// kept for `idempotency_ttl` config. Requests without the header are passed through. Should be
+// placed after Auth, so keys of different clients don't collide. Errors of saving the response
+// are given to onErr, the response is still sent.
+func Idempotency(conf *config.Model, st *store.Store, onErr func(error)) func(ctx *fiber.Ctx) error {
+	// retries that arrive while the first request is in progress wait for it.
+	var locks service.KeyedMutex
+
+	return func(c *fiber.Ctx) error {
+		idemKey := c.Get(IdempotencyKeyHeader)
+		if idemKey == "" {
+			return c.Next()
+		}
+		if len(idemKey) > maxIdempotencyKeyLen {
+			c.Status(fiber.StatusBadRequest)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("%s must not be longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLen),
+			})
+		}
+
+		key := fmt.Sprintf("%s/%s %s/%s", Client(c), c.Method(), c.Path(), idemKey)
+		sum := sha256.Sum256(c.Body())
+		reqHash := hex.EncodeToString(sum[:])
+
+		unlock := locks.Lock(key)
+		defer unlock()
+
+		r, err := st.IdempotentResponse(key)
+		switch {
+		case err == store.ErrNotFound:
+		case err != nil:
+			c.Status(fiber.StatusInternalServerError)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("failed to get idempotent response: %s", err),
+			})
+		case time.Now().Before(r.ExpiresAt) && r.RequestHash != reqHash:
+			c.Status(fiber.StatusUnprocessableEntity)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("%s has been used for a different request", IdempotencyKeyHeader),
+			})
+		case time.Now().Before(r.ExpiresAt):
+			c.Set(IdempotentReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, r.ContentType)
+			return c.Status(r.Status).Send(r.Body)
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		// failed requests could be retried after they are fixed.
+		status := c.Response().StatusCode()
+		if status < 200 || status >= 300 {
+			return nil
+		}
+
+		now := time.Now()
+		err = st.SaveIdempotentResponse(store.IdempotentResponse{
+			Key:         key,
+			RequestHash: reqHash,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+			ExpiresAt:   now.Add(time.Duration(conf.IdempotencyTTL) * time.Second),
+			CreatedAt:   now,
+		})
+		if err != nil && onErr != nil {
+			onErr(err)
+		}
+
+		return nil
+	}
+}
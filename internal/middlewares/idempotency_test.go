@@ -0,0 +1,98 @@
+package middlewares
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	conf, err := config.NewConfig(bytes.NewBufferString(sampleConfigFile[0]))
+	require.NoError(t, err)
+	require.NoError(t, conf.Sanitization())
+
+	var created int32
+	app := fiber.New()
+	app.Post("/create",
+		Auth(conf),
+		Idempotency(conf, store.New(store.NewMemory()), func(err error) { t.Error(err) }),
+		func(c *fiber.Ctx) error {
+			// creating takes a while so retries would overlap.
+			time.Sleep(10 * time.Millisecond)
+			if string(c.Body()) == "fail" {
+				return c.SendStatus(fiber.StatusBadGateway)
+			}
+			n := atomic.AddInt32(&created, 1)
+			return c.JSON(fiber.Map{"meeting_id": fmt.Sprintf("meet%02d", n)})
+		},
+	)
+
+	create := func(t *testing.T, key, body string) (*http.Response, string) {
+		req := httptest.NewRequest(fiber.MethodPost, "/create", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "superSecret")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, string(b)
+	}
+
+	t.Run("Retries should get the original response", func(t *testing.T) {
+		var wg sync.WaitGroup
+		bodies := make([]string, 3)
+		for i := range bodies {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				res, body := create(t, "key01", `{"name":"Math"}`)
+				assert.Equal(t, fiber.StatusOK, res.StatusCode)
+				bodies[i] = body
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+		for _, body := range bodies {
+			assert.JSONEq(t, `{"meeting_id":"meet01"}`, body)
+		}
+
+		res, _ := create(t, "key01", `{"name":"Math"}`)
+		assert.Equal(t, "true", res.Header.Get(IdempotentReplayedHeader))
+		assert.Equal(t, fiber.MIMEApplicationJSON, res.Header.Get(fiber.HeaderContentType))
+	})
+
+	t.Run("Key that was used for a different request should be rejected", func(t *testing.T) {
+		res, _ := create(t, "key01", `{"name":"Physics"}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, res.StatusCode)
+	})
+
+	t.Run("Requests w/o key or w different keys should not be deduplicated", func(t *testing.T) {
+		before := atomic.LoadInt32(&created)
+		create(t, "", `{"name":"Math"}`)
+		create(t, "", `{"name":"Math"}`)
+		create(t, "key02", `{"name":"Math"}`)
+		assert.Equal(t, before+3, atomic.LoadInt32(&created))
+	})
+
+	t.Run("Failed request should not be kept", func(t *testing.T) {
+		res, _ := create(t, "key03", "fail")
+		assert.Equal(t, fiber.StatusBadGateway, res.StatusCode)
+		res, _ = create(t, "key03", "fail")
+		assert.Empty(t, res.Header.Get(IdempotentReplayedHeader))
+	})
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+)
+
+// Metrics middleware that record every request, by the route it matched, to the metrics that
+// are served on /metrics. Should be placed before every other middleware, so their responses
+// are recorded too.
+func Metrics() func(ctx *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// the error handler hasn't sent the error yet, so its status is taken from the error.
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		// labels are kept by the metrics after the request, so the method must be copied.
+		metrics.ObserveRequest(c.Route().Path, utils.CopyString(c.Method()), status, time.Since(start))
+
+		return err
+	}
+}
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	app := fiber.New()
+	app.Use(Metrics())
+	app.Get("/metrics-test/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "missing" {
+			return fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/metrics", func(c *fiber.Ctx) error {
+		metrics.Handler()(c.Context())
+		return nil
+	})
+
+	for _, target := range []string{"/metrics-test/meet01", "/metrics-test/meet02", "/metrics-test/missing"} {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil))
+		require.NoError(t, err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	// every meeting ID is counted as the route.
+	assert.Contains(t, string(body), `bbb_interface_http_requests_total{method="GET",route="/metrics-test/:id",status="204"} 2`)
+	assert.Contains(t, string(body), `bbb_interface_http_requests_total{method="GET",route="/metrics-test/:id",status="404"} 1`)
+	assert.Contains(t, string(body), `bbb_interface_http_request_duration_seconds_count{method="GET",route="/metrics-test/:id",status="204"} 2`)
+}
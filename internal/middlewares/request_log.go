@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/kurvaid/bbb-interface/internal/logger"
+)
+
+// RequestIdKey key of the locals that hold the ID of the request, which is set by requestid
+// middleware of fiber.
+const RequestIdKey = "requestid"
+
+// MeetingKey key of the locals that hold the ID of the meeting the request is about, to be
+// logged along the request.
+const MeetingKey = "meeting"
+
+// RequestId return the ID of the request, which is sent in logger.RequestIdHeader by the
+// requester or generated. The ID is copied so it could be kept after the request.
+func RequestId(c *fiber.Ctx) string {
+	id, _ := c.Locals(RequestIdKey).(string)
+	return utils.CopyString(id)
+}
+
+// SetMeeting set the ID of the meeting the request is about, to be logged along the request.
+func SetMeeting(c *fiber.Ctx, meetingId string) {
+	c.Locals(MeetingKey, utils.CopyString(meetingId))
+}
+
+// secretRoutes public routes that have the secret in the path, whose path is not logged.
+var secretRoutes = map[string]bool{
+	"/j/:token":     true,
+	"/lobby/:token": true,
+	"/l/:id":        true,
+	"/g/:id":        true,
+}
+
+// RequestLog middleware that log every request as json to the given logger, along with its
+// ID, client and meeting. Secrets in the query are redacted, and so is the path of secretRoutes. Should be placed after requestid
+// middleware of fiber.
+func RequestLog(l *logger.Logger) func(ctx *fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// the error handler hasn't sent the error yet, so its status is taken from the error.
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		meetingId, _ := c.Locals(MeetingKey).(string)
+		if meetingId == "" && strings.HasPrefix(c.Route().Path, "/meetings/:id") {
+			meetingId = c.Params("id")
+		}
+
+		f := logger.Fields{
+			"request_id": RequestId(c),
+			"method":     c.Method(),
+			"route":      c.Route().Path,
+			"status":     status,
+			"latency_ms": time.Since(start).Milliseconds(),
+			"ip":         c.IP(),
+		}
+		if !secretRoutes[c.Route().Path] {
+			f["path"] = c.Path()
+		}
+		if q := string(c.Request().URI().QueryString()); q != "" {
+			f["query"] = logger.RedactQuery(q)
+		}
+		if cl := Client(c); cl != "" {
+			f["client"] = cl
+		}
+		if meetingId != "" {
+			f["meeting_id"] = meetingId
+		}
+		if err != nil {
+			f["error"] = err
+		}
+
+		level := logger.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = logger.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = logger.LevelWarn
+		}
+		l.Log(level, "request", f)
+
+		return err
+	}
+}
@@ -0,0 +1,102 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/kurvaid/bbb-interface/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Use(RequestLog(logger.New(buf, logger.LevelDebug)))
+	app.Get("/meetings/:id/roster", func(c *fiber.Ctx) error {
+		if c.Params("id") == "missing" {
+			return fiber.NewError(fiber.StatusNotFound, "meeting is not found")
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/l/:id", func(c *fiber.Ctx) error {
+		return c.Redirect("https://bbb.example/join", fiber.StatusFound)
+	})
+	app.Post("/create", func(c *fiber.Ctx) error {
+		c.Locals(ClientKey, "lms")
+		SetMeeting(c, "lms-math101")
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	testCases := []struct {
+		name      string
+		method    string
+		target    string
+		requestId string
+		expect    map[string]interface{}
+	}{
+		{
+			name:      "Request w request ID should be logged w the ID and the meeting of the route",
+			method:    fiber.MethodGet,
+			target:    "/meetings/meet01/roster",
+			requestId: "req-1",
+			expect: map[string]interface{}{
+				"level": "info", "msg": "request", "request_id": "req-1", "method": "GET",
+				"route": "/meetings/:id/roster", "path": "/meetings/meet01/roster", "status": float64(200),
+				"meeting_id": "meet01",
+			},
+		},
+		{
+			name:      "Token in query should be redacted",
+			method:    fiber.MethodGet,
+			target:    "/meetings/meet01/roster?token=s3cret&full=true",
+			requestId: "req-2",
+			expect:    map[string]interface{}{"query": "full=true&token=REDACTED"},
+		},
+		{
+			name:      "Failed request should be logged as warn w the error",
+			method:    fiber.MethodGet,
+			target:    "/meetings/missing/roster",
+			requestId: "req-3",
+			expect:    map[string]interface{}{"level": "warn", "status": float64(404), "error": "meeting is not found"},
+		},
+		{
+			name:      "Client and meeting that were set by the handler should be logged",
+			method:    fiber.MethodPost,
+			target:    "/create",
+			requestId: "req-4",
+			expect:    map[string]interface{}{"client": "lms", "meeting_id": "lms-math101", "status": float64(201)},
+		},
+		{
+			name:      "Path of public link should not be logged",
+			method:    fiber.MethodGet,
+			target:    "/l/s3cret",
+			requestId: "req-5",
+			expect:    map[string]interface{}{"route": "/l/:id", "path": nil, "status": float64(302)},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header.Set(logger.RequestIdHeader, tt.requestId)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.requestId, res.Header.Get(logger.RequestIdHeader))
+
+			var rec map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+			for k, v := range tt.expect {
+				assert.Equal(t, v, rec[k], k)
+			}
+			assert.NotContains(t, buf.String(), "s3cret")
+		})
+	}
+}
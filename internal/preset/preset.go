@@ -0,0 +1,111 @@
+// Package preset hold named settings of create meeting request, so clients could create
+// meetings from them instead of sending every setting.
+package preset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+)
+
+// Settings fields of create meeting request in the same format as json request, such as
+// `{"is_recording": true, "lock_settings": {"disable_cam": true}}`. Keeping them as map
+// tells apart the fields that were given from the ones that were not.
+type Settings map[string]interface{}
+
+// Validate check whether every field of the settings is a known field of create meeting
+// request with valid value. Fields that identify a meeting could not be in a template.
+func (s Settings) Validate() error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %s", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var cm api.CreateMeeting
+	if err := dec.Decode(&cm); err != nil {
+		return fmt.Errorf("invalid settings: %s", err)
+	}
+
+	switch {
+	case cm.MeetingId != "":
+		return fmt.Errorf("meeting ID could not be in a template")
+	case cm.AttendeePass != "" || cm.ModeratorPass != "":
+		return fmt.Errorf("passwords could not be in a template")
+	case cm.Template != "":
+		return fmt.Errorf("template could not be in another template")
+	case cm.IsBreakout || cm.ParentMeetingId != "" || cm.Sequence != 0:
+		return fmt.Errorf("breakout room could not be in a template")
+	}
+
+	return nil
+}
+
+// Apply return create meeting request of the given json body, with the settings as the
+// default of every field that is not in the body. Nested object such as lock settings is
+// merged field by field. Fields are matched case-insensitively, as they're decoded.
+func (s Settings) Apply(body []byte) (api.CreateMeeting, error) {
+	var cm api.CreateMeeting
+
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return cm, fmt.Errorf("failed to decode request as json: %s", err)
+	}
+
+	b, err := json.Marshal(merge(normalize(s), normalize(req)))
+	if err != nil {
+		return cm, fmt.Errorf("failed to encode merged request: %s", err)
+	}
+	if err := json.Unmarshal(b, &cm); err != nil {
+		return cm, fmt.Errorf("failed to decode merged request: %s", err)
+	}
+
+	return cm, nil
+}
+
+// merge return copy of base with every field of over, merging the objects that are in both.
+func merge(base, over map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+
+	for k, v := range over {
+		baseObj, ok := out[k].(map[string]interface{})
+		overObj, ok2 := v.(map[string]interface{})
+		if ok && ok2 {
+			out[k] = merge(baseObj, overObj)
+			continue
+		}
+		out[k] = v
+	}
+
+	return out
+}
+
+// normalize return copy of the given object w every field name, including the ones of nested
+// objects, in lower case. Of the fields whose names differ only in case, the one that sorts last
+// is kept, so the result doesn't depend on the order of the map.
+func normalize(obj map[string]interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]interface{}, len(obj))
+	for _, k := range keys {
+		v := obj[k]
+		if nested, ok := v.(map[string]interface{}); ok {
+			v = normalize(nested)
+		}
+		out[strings.ToLower(k)] = v
+	}
+
+	return out
+}
@@ -0,0 +1,99 @@
+package preset
+
+import (
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		sample Settings
+		isErr  bool
+	}{
+		{name: "Known fields should be valid", sample: Settings{"is_recording": true, "lock_settings": map[string]interface{}{"disable_cam": true}}},
+		{name: "Unknown field should be invalid", sample: Settings{"recording": true}, isErr: true},
+		{name: "Unknown nested field should be invalid", sample: Settings{"lock_settings": map[string]interface{}{"disable_webcam": true}}, isErr: true},
+		{name: "Field w wrong type should be invalid", sample: Settings{"mute_on_start": "yes"}, isErr: true},
+		{name: "Meeting ID should be invalid", sample: Settings{"MeetingId": "meet01"}, isErr: true},
+		{name: "Nested template should be invalid", sample: Settings{"template": "exam"}, isErr: true},
+		{name: "Breakout room should be invalid", sample: Settings{"is_breakout": true, "parent_meeting_id": "abc", "sequence": float64(1)}, isErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sample.Validate()
+
+			switch tt.isErr {
+			case true:
+				require.Error(t, err)
+			case false:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSettings_Apply(t *testing.T) {
+	exam := Settings{
+		"is_recording":    true,
+		"mute_on_start":   true,
+		"max_participant": float64(40),
+		"lock_settings":   map[string]interface{}{"disable_cam": true, "disable_private_chat": true},
+	}
+
+	testCases := []struct {
+		name   string
+		body   string
+		expect api.CreateMeeting
+		isErr  bool
+	}{
+		{
+			name: "Request w/o override should get every setting of the template",
+			body: `{"name": "Final Exam", "template": "exam"}`,
+			expect: api.CreateMeeting{
+				Name: "Final Exam", Template: "exam", IsRecording: true, MuteOnStart: true, MaxParticipants: 40,
+				LockSettings: api.LockSettings{DisableCam: true, DisablePrivateChat: true},
+			},
+		},
+		{
+			name: "Given fields should override the template, including false",
+			body: `{"name": "Final Exam", "template": "exam", "is_recording": false, "max_participant": 10, "lock_settings": {"disable_cam": false, "disable_mic": true}}`,
+			expect: api.CreateMeeting{
+				Name: "Final Exam", Template: "exam", IsRecording: false, MuteOnStart: true, MaxParticipants: 10,
+				LockSettings: api.LockSettings{DisableCam: false, DisableMic: true, DisablePrivateChat: true},
+			},
+		},
+		{
+			name: "Fields should override the template regardless of their case",
+			body: `{"Name": "Final Exam", "template": "exam", "Is_Recording": false, "LOCK_SETTINGS": {"Disable_Cam": false}}`,
+			expect: api.CreateMeeting{
+				Name: "Final Exam", Template: "exam", IsRecording: false, MuteOnStart: true, MaxParticipants: 40,
+				LockSettings: api.LockSettings{DisableCam: false, DisablePrivateChat: true},
+			},
+		},
+		{
+			name:  "Request that is not json should be error",
+			body:  `name=Final+Exam`,
+			isErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// the result must not depend on the order of the maps.
+			for i := 0; i < 20; i++ {
+				cm, err := exam.Apply([]byte(tt.body))
+				if tt.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.expect, cm)
+			}
+		})
+	}
+}
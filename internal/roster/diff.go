@@ -0,0 +1,78 @@
+package roster
+
+import (
+	"sort"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/event"
+)
+
+// Diff compare two snapshots of attendees of the given meeting that keyed by their
+// user id, then return the events that describe the changes in between. BBB API doesn't
+// tell whether the microphone is muted, so attendees w/o open microphone are muted.
+func Diff(meetingId string, previous, current map[string]api.Attendee) []event.Event {
+	var events []event.Event
+	add := func(typ string, a api.Attendee) {
+		events = append(events, event.Event{Type: typ, MeetingId: meetingId, User: toUser(a)})
+	}
+
+	for _, id := range sortedKeys(current) {
+		a := current[id]
+		prev, ok := previous[id]
+		if !ok {
+			add(event.UserJoined, a)
+			continue
+		}
+
+		switch {
+		case !prev.IsPresenter && a.IsPresenter:
+			add(event.PresenterAssigned, a)
+		case prev.IsPresenter && !a.IsPresenter:
+			add(event.PresenterRemoved, a)
+		}
+
+		switch {
+		case !muted(prev) && muted(a):
+			add(event.UserMuted, a)
+		case muted(prev) && !muted(a):
+			add(event.UserUnmuted, a)
+		}
+	}
+
+	for _, id := range sortedKeys(previous) {
+		if _, ok := current[id]; !ok {
+			add(event.UserLeft, previous[id])
+		}
+	}
+
+	return events
+}
+
+// muted whether the attendee has no open microphone, either hasn't joined the voice conference
+// or is listen only.
+func muted(a api.Attendee) bool {
+	return !a.HasJoinedVoice || a.IsListeningOnly
+}
+
+// toUser convert attendee from BBB API to user of an event.
+func toUser(a api.Attendee) *event.User {
+	return &event.User{
+		Id:        a.UserId,
+		Name:      a.FullName,
+		Role:      a.Role,
+		Presenter: a.IsPresenter,
+		Muted:     muted(a),
+	}
+}
+
+// sortedKeys return keys of the given attendees in order, so the produced
+// events would be deterministic.
+func sortedKeys(m map[string]api.Attendee) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
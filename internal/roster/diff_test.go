@@ -0,0 +1,88 @@
+package roster
+
+import (
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	usr01 := api.Attendee{UserId: "usr01", FullName: "NzK", Role: "MODERATOR"}
+	usr01Presenting := api.Attendee{UserId: "usr01", FullName: "NzK", Role: "MODERATOR", IsPresenter: true}
+	usr02 := api.Attendee{UserId: "usr02", FullName: "Mhs", Role: "VIEWER"}
+	usr02Talking := api.Attendee{UserId: "usr02", FullName: "Mhs", Role: "VIEWER", HasJoinedVoice: true}
+	usr02Listening := api.Attendee{UserId: "usr02", FullName: "Mhs", Role: "VIEWER", HasJoinedVoice: true, IsListeningOnly: true}
+	usr01TalkingPresenting := api.Attendee{UserId: "usr01", FullName: "NzK", Role: "MODERATOR", IsPresenter: true, HasJoinedVoice: true}
+
+	testCases := []struct {
+		name     string
+		previous map[string]api.Attendee
+		current  map[string]api.Attendee
+		expect   []string
+	}{
+		{
+			name:    "Every attendee should be joined if there is no previous snapshot",
+			current: map[string]api.Attendee{"usr01": usr01, "usr02": usr02},
+			expect:  []string{event.UserJoined, event.UserJoined},
+		},
+		{
+			name:     "No event if nothing has changed",
+			previous: map[string]api.Attendee{"usr01": usr01},
+			current:  map[string]api.Attendee{"usr01": usr01},
+		},
+		{
+			name:     "Presenter changes should be detected",
+			previous: map[string]api.Attendee{"usr01": usr01},
+			current:  map[string]api.Attendee{"usr01": usr01Presenting},
+			expect:   []string{event.PresenterAssigned},
+		},
+		{
+			name:     "Attendee who joined voice w microphone should be unmuted",
+			previous: map[string]api.Attendee{"usr02": usr02},
+			current:  map[string]api.Attendee{"usr02": usr02Talking},
+			expect:   []string{event.UserUnmuted},
+		},
+		{
+			name:     "Attendee who switched to listen only should be muted",
+			previous: map[string]api.Attendee{"usr02": usr02Talking},
+			current:  map[string]api.Attendee{"usr02": usr02Listening},
+			expect:   []string{event.UserMuted},
+		},
+		{
+			name:     "Attendee who left voice should be muted",
+			previous: map[string]api.Attendee{"usr02": usr02Talking},
+			current:  map[string]api.Attendee{"usr02": usr02},
+			expect:   []string{event.UserMuted},
+		},
+		{
+			name:     "Listen only attendee who joined voice w/o microphone should stay muted",
+			previous: map[string]api.Attendee{"usr02": usr02},
+			current:  map[string]api.Attendee{"usr02": usr02Listening},
+		},
+		{
+			name:     "Presenter and microphone changes of the same attendee should both be detected",
+			previous: map[string]api.Attendee{"usr01": usr01},
+			current:  map[string]api.Attendee{"usr01": usr01TalkingPresenting},
+			expect:   []string{event.PresenterAssigned, event.UserUnmuted},
+		},
+		{
+			name:     "Missing attendee should be left",
+			previous: map[string]api.Attendee{"usr01": usr01Presenting, "usr02": usr02},
+			current:  map[string]api.Attendee{"usr01": usr01},
+			expect:   []string{event.PresenterRemoved, event.UserLeft},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var types []string
+			for _, e := range Diff("meet01", tc.previous, tc.current) {
+				assert.Equal(t, "meet01", e.MeetingId)
+				types = append(types, e.Type)
+			}
+			assert.Equal(t, tc.expect, types)
+		})
+	}
+}
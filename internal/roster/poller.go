@@ -0,0 +1,189 @@
+package roster
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/client"
+	"github.com/kurvaid/bbb-interface/internal/event"
+)
+
+// Poller periodically get the details of watched meetings from BBB API, then
+// publish the difference between successive snapshots of their attendees to
+// the bus as roster events.
+type Poller struct {
+	hCl      *http.Client
+	bbb      api.Config
+	bus      *event.Bus
+	interval time.Duration
+
+	mu       sync.Mutex
+	watched  map[string]int                     // Number of watchers of every meeting.
+	followed map[string]bool                    // Meetings that are watched until they ended.
+	last     map[string]map[string]api.Attendee // Last known attendees of every meeting.
+}
+
+// NewPoller return new Poller that poll BBB API every interval.
+func NewPoller(hCl *http.Client, bbb api.Config, bus *event.Bus, interval time.Duration) *Poller {
+	return &Poller{
+		hCl:      hCl,
+		bbb:      bbb,
+		bus:      bus,
+		interval: interval,
+		watched:  make(map[string]int),
+		followed: make(map[string]bool),
+		last:     make(map[string]map[string]api.Attendee),
+	}
+}
+
+// Watch start polling the given meeting until the returned function is called.
+// A meeting would be polled as long as it has at least one watcher.
+func (p *Poller) Watch(meetingId string) func() {
+	p.mu.Lock()
+	p.watched[meetingId]++
+	p.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			p.unwatch(meetingId)
+		})
+	}
+}
+
+// Follow watch every meeting from the time it's created or started until it's ended, as
+// heard from the bus, until the returned function is called or the bus is closed.
+func (p *Poller) Follow() func() {
+	ch, unsubscribe := p.bus.Subscribe(64, event.ByTypes(event.MeetingCreated, event.MeetingStarted, event.MeetingEnded))
+
+	go func() {
+		for e := range ch {
+			if e.Type == event.MeetingEnded {
+				p.Unfollow(e.MeetingId)
+				continue
+			}
+			p.FollowMeeting(e.MeetingId)
+		}
+	}()
+
+	return unsubscribe
+}
+
+// FollowMeeting watch the given meeting until it's ended. Following the same meeting
+// more than once has no effect.
+func (p *Poller) FollowMeeting(meetingId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.followed[meetingId] {
+		return
+	}
+	p.followed[meetingId] = true
+	p.watched[meetingId]++
+}
+
+// Unfollow stop watching the given meeting that was followed.
+func (p *Poller) Unfollow(meetingId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.followed[meetingId] {
+		return
+	}
+	delete(p.followed, meetingId)
+	p.unwatch(meetingId)
+}
+
+// unwatch remove a watcher of the given meeting. Must be called while holding the lock.
+func (p *Poller) unwatch(meetingId string) {
+	p.watched[meetingId]--
+	if p.watched[meetingId] <= 0 {
+		delete(p.watched, meetingId)
+		delete(p.last, meetingId)
+	}
+}
+
+// Run poll every watched meeting each interval until the given channel is closed.
+// Failed polls would be reported to onErr if not nil.
+func (p *Poller) Run(stop <-chan struct{}, onErr func(error)) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, id := range p.meetings() {
+				if err := p.Poll(id); err != nil && onErr != nil {
+					onErr(err)
+				}
+			}
+		}
+	}
+}
+
+// Poll get the details of the given meeting then publish the difference with the
+// previous snapshot to the bus.
+func (p *Poller) Poll(meetingId string) error {
+	mi := api.MeetingInfo{MeetingId: meetingId}
+	uri, err := mi.ParseMeetingInfo()
+	if err != nil {
+		return err
+	}
+
+	var res api.MeetingInfoResponse
+	var notFound bool
+	if err := client.Call(p.hCl, p.bbb, uri, &res); err != nil {
+		// BBB API respond with notFound if the meeting doesn't exist, e.g. it has ended.
+		if !client.IsNotFound(err) {
+			return err
+		}
+		notFound = true
+	}
+
+	current := make(map[string]api.Attendee)
+	for _, a := range res.Attendees {
+		current[a.UserId] = a
+	}
+
+	p.mu.Lock()
+	previous := p.last[meetingId]
+	if _, ok := p.watched[meetingId]; ok {
+		p.last[meetingId] = current
+	}
+	followed := p.followed[meetingId]
+	p.mu.Unlock()
+
+	p.bus.Observe(meetingId, res.Running)
+	for _, e := range Diff(meetingId, previous, current) {
+		e.InternalMeetingId = res.InternalMeetingId
+		p.bus.Publish(e)
+	}
+
+	// followed meeting that doesn't exist anymore has ended even if its end was never
+	// heard of, e.g. it ended while this app was not running.
+	if notFound && followed {
+		p.bus.Publish(event.Event{Type: event.MeetingEnded, MeetingId: meetingId})
+	}
+
+	return nil
+}
+
+// meetings return ids of every watched meeting.
+func (p *Poller) meetings() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make([]string, 0, len(p.watched))
+	for id := range p.watched {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
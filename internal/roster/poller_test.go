@@ -0,0 +1,121 @@
+package roster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prepare fake server to mimic BBB Server that respond with the given getMeetingInfo
+// responses in order, the last one would be repeated.
+var fakeMeetingInfoServer = func(responses ...string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		rw.Write([]byte(responses[0]))
+		if len(responses) > 1 {
+			responses = responses[1:]
+		}
+	}))
+}
+
+func TestPoller(t *testing.T) {
+	server := fakeMeetingInfoServer(
+		`<response><returncode>SUCCESS</returncode><running>true</running><attendees>
+<attendee><userID>usr01</userID><fullName>NzK</fullName><role>MODERATOR</role></attendee>
+</attendees></response>`,
+		`<response><returncode>SUCCESS</returncode><running>true</running><attendees>
+<attendee><userID>usr01</userID><fullName>NzK</fullName><role>MODERATOR</role><isPresenter>true</isPresenter></attendee>
+<attendee><userID>usr02</userID><fullName>Mhs</fullName><role>VIEWER</role></attendee>
+</attendees></response>`,
+		`<response><returncode>FAILED</returncode><messageKey>notFound</messageKey></response>`,
+	)
+	defer server.Close()
+
+	bbb := api.Config{Host: server.URL, Secret: "secret"}
+	require.NoError(t, bbb.Sanitization())
+
+	bus := event.NewBus()
+	ch, unsub := bus.Subscribe(16, nil)
+	defer unsub()
+
+	p := NewPoller(server.Client(), bbb, bus, time.Hour)
+	stop := p.Watch("meet01")
+
+	// collect every delivered event types so far.
+	types := func() (out []string) {
+		for len(ch) > 0 {
+			out = append(out, (<-ch).Type)
+		}
+		return
+	}
+
+	t.Run("First poll should publish every attendee as joined", func(t *testing.T) {
+		require.NoError(t, p.Poll("meet01"))
+		assert.Equal(t, []string{event.MeetingStarted, event.UserJoined}, types())
+	})
+
+	t.Run("Next poll should only publish the changes", func(t *testing.T) {
+		require.NoError(t, p.Poll("meet01"))
+		assert.Equal(t, []string{event.PresenterAssigned, event.UserJoined}, types())
+	})
+
+	t.Run("Meeting not found should be observed as ended", func(t *testing.T) {
+		require.NoError(t, p.Poll("meet01"))
+		assert.Equal(t, []string{event.MeetingEnded}, types())
+	})
+
+	t.Run("Unwatched meeting should not be polled anymore", func(t *testing.T) {
+		assert.Equal(t, []string{"meet01"}, p.meetings())
+		stop()
+		stop()
+		assert.Empty(t, p.meetings())
+	})
+}
+
+func TestPoller_Follow(t *testing.T) {
+	server := fakeMeetingInfoServer(`<response><returncode>FAILED</returncode><messageKey>notFound</messageKey></response>`)
+	defer server.Close()
+
+	bbb := api.Config{Host: server.URL, Secret: "secret"}
+	require.NoError(t, bbb.Sanitization())
+
+	bus := event.NewBus()
+	defer bus.Close()
+	p := NewPoller(server.Client(), bbb, bus, time.Hour)
+	p.Follow()
+
+	t.Run("Created meeting should be followed", func(t *testing.T) {
+		bus.Publish(event.Event{Type: event.MeetingCreated, MeetingId: "meet01"})
+		assert.Eventually(t, func() bool {
+			return len(p.meetings()) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Following the same meeting again should have no effect", func(t *testing.T) {
+		p.FollowMeeting("meet01")
+		p.Unfollow("meet01")
+		assert.Empty(t, p.meetings())
+		p.FollowMeeting("meet01")
+	})
+
+	t.Run("Followed meeting that is not found should end and be unfollowed", func(t *testing.T) {
+		ch, unsub := bus.Subscribe(16, event.ByTypes(event.MeetingEnded))
+		defer unsub()
+
+		require.NoError(t, p.Poll("meet01"))
+		assert.Equal(t, "meet01", (<-ch).MeetingId)
+		assert.Eventually(t, func() bool {
+			return len(p.meetings()) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -1,50 +1,198 @@
 package routes
 
 import (
+	"io"
 	"net/http"
+	"os"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
 	"github.com/kurvaid/bbb-interface/internal/handlers"
+	"github.com/kurvaid/bbb-interface/internal/logger"
 	"github.com/kurvaid/bbb-interface/internal/middlewares"
+	"github.com/kurvaid/bbb-interface/internal/roster"
+	"github.com/kurvaid/bbb-interface/internal/store"
 )
 
-func SetupRoutes(app *fiber.App, conf *config.Model, hCl *http.Client) {
+func SetupRoutes(app *fiber.App, conf *config.Model, hCl *http.Client, bus *event.Bus, poller *roster.Poller, st *store.Store) {
 	// Built-in fiber middlewares
 	app.Use(recover.New())
+	app.Use(requestid.New(requestid.Config{Header: logger.RequestIdHeader, ContextKey: middlewares.RequestIdKey}))
+	app.Use(middlewares.Metrics())
 	// Use log file only in production
-	switch conf.EnvIsProd {
-	case true:
-		fConf := logger.Config{
-			Format:     "[${time}] ${status} | ${method} - ${latency} - ${ip} | ${path}\n",
-			TimeFormat: "02-Jan-2006 15:04:05",
-			Output:     conf.LogFile,
-		}
-		app.Use(logger.New(fConf))
-	case false:
-		app.Use(logger.New())
+	var out io.Writer = os.Stdout
+	if conf.EnvIsProd && conf.LogFile != nil {
+		out = conf.LogFile
 	}
+	app.Use(middlewares.RequestLog(logger.New(out, conf.LogLevel)))
 
 	// This app's endpoints
+	app.Get("/metrics",
+		handlers.Metrics(conf),
+	)
 	app.Post("/create",
 		middlewares.Auth(conf),
-		handlers.CreateMeeting(conf, hCl),
+		middlewares.Idempotency(conf, st, func(err error) {
+			logger.Error("failed to save idempotent response", logger.Fields{"error": err})
+		}),
+		handlers.CreateMeeting(conf, hCl, bus, st),
 	)
 	app.Post("/join",
 		middlewares.Auth(conf),
-		handlers.JoinMeeting(conf),
+		handlers.JoinMeeting(conf, st),
+	)
+	app.Post("/join-or-create",
+		middlewares.Auth(conf),
+		handlers.JoinOrCreate(conf, hCl, bus, st),
+	)
+	app.Post("/join/link",
+		middlewares.Auth(conf),
+		handlers.JoinLink(conf, st),
+	)
+	app.Post("/join-links",
+		middlewares.Auth(conf),
+		handlers.CreateJoinLink(conf, st),
+	)
+	app.Get("/join-links/:id",
+		middlewares.Auth(conf),
+		handlers.GetJoinLink(conf, st),
+	)
+	app.Delete("/join-links/:id",
+		middlewares.Auth(conf),
+		handlers.DeleteJoinLink(st),
+	)
+	app.Post("/guests",
+		middlewares.Auth(conf),
+		handlers.CreateGuest(conf, bus, st),
+	)
+	app.Post("/guests/:id/approve",
+		middlewares.Auth(conf),
+		handlers.DecideGuest(conf, bus, st, store.GuestApproved),
+	)
+	app.Post("/guests/:id/deny",
+		middlewares.Auth(conf),
+		handlers.DecideGuest(conf, bus, st, store.GuestDenied),
+	)
+	app.Post("/meetings/:id/breakouts",
+		middlewares.Auth(conf),
+		handlers.CreateBreakouts(conf, hCl, bus, st),
+	)
+	app.Get("/meetings/:id/breakouts",
+		middlewares.Auth(conf),
+		handlers.ListBreakouts(st),
+	)
+	app.Get("/meetings/:id/guests",
+		middlewares.Auth(conf),
+		handlers.ListGuests(conf, st),
 	)
 	app.Post("/end",
 		middlewares.Auth(conf),
-		handlers.EndMeeting(conf, hCl),
+		handlers.EndMeeting(conf, hCl, st),
 	)
 	app.Post("/is_run",
 		middlewares.Auth(conf),
-		handlers.IsRunning(conf, hCl),
+		handlers.IsRunning(conf, hCl, bus, st),
+	)
+	app.Get("/events",
+		middlewares.Auth(conf),
+		handlers.EventStream(bus),
+	)
+	app.Get("/meetings/:id/roster",
+		middlewares.AuthQuery(conf),
+		handlers.RosterSocket(bus, poller, st),
+	)
+	app.Get("/meetings/:id/attendance",
+		middlewares.Auth(conf),
+		handlers.Attendance(st),
+	)
+	app.Get("/meetings/:id/analytics",
+		middlewares.Auth(conf),
+		handlers.Analytics(st),
+	)
+	app.Get("/history",
+		middlewares.Auth(conf),
+		handlers.History(st),
+	)
+	app.Post("/schedules",
+		middlewares.Auth(conf),
+		handlers.CreateSchedule(conf, st),
+	)
+	app.Get("/schedules",
+		middlewares.Auth(conf),
+		handlers.ListSchedules(conf, st),
+	)
+	app.Get("/schedules/:id.ics",
+		middlewares.Auth(conf),
+		handlers.ScheduleCalendar(conf, st),
+	)
+	app.Get("/schedules/:id",
+		middlewares.Auth(conf),
+		handlers.GetSchedule(conf, st),
+	)
+	app.Delete("/schedules/:id",
+		middlewares.Auth(conf),
+		handlers.DeleteSchedule(st),
+	)
+	app.Post("/series",
+		middlewares.Auth(conf),
+		handlers.CreateSeries(conf, st),
+	)
+	app.Get("/series",
+		middlewares.Auth(conf),
+		handlers.ListSeries(conf, st),
+	)
+	app.Get("/series/:id.ics",
+		middlewares.Auth(conf),
+		handlers.SeriesCalendar(conf, st),
+	)
+	app.Get("/series/:id",
+		middlewares.Auth(conf),
+		handlers.GetSeries(conf, st),
+	)
+	app.Delete("/series/:id",
+		middlewares.Auth(conf),
+		handlers.DeleteSeries(st),
+	)
+	app.Get("/series/:id/attendance",
+		middlewares.Auth(conf),
+		handlers.SeriesAttendance(st),
+	)
+	app.Get("/templates",
+		middlewares.Auth(conf),
+		handlers.ListTemplates(conf, st),
+	)
+	app.Get("/templates/:name",
+		middlewares.Auth(conf),
+		handlers.GetTemplate(conf, st),
+	)
+	app.Put("/templates/:name",
+		middlewares.Auth(conf),
+		middlewares.Admin(),
+		handlers.PutTemplate(conf, st),
+	)
+	app.Delete("/templates/:name",
+		middlewares.Auth(conf),
+		middlewares.Admin(),
+		handlers.DeleteTemplate(conf, st),
+	)
+	app.Get("/calendar",
+		middlewares.Auth(conf),
+		handlers.Calendar(conf),
 	)
-	app.Get("/callback/destroy", handlers.CallbackOnDestroy(conf, hCl))
+	// authenticated by the key in join or calendar link instead of token.
+	app.Get("/calendar/:client.ics", handlers.ClientCalendar(conf, st))
+	app.Get("/j/:token", handlers.RedirectJoin(conf, st))
+	app.Get("/lobby/:token", handlers.Lobby(conf, hCl, bus, st))
+	app.Get("/l/:id", handlers.RedeemJoinLink(conf, st))
+	app.Get("/g/:id", handlers.WaitGuest(conf, st))
+	app.Get("/schedules/:id/join", handlers.JoinSchedule(conf, hCl, bus, st))
+	app.Get("/series/:id/join", handlers.JoinSeries(conf, hCl, bus, st))
+	app.Get("/callback/destroy", handlers.CallbackOnDestroy(conf, hCl, bus))
+	app.Post("/callback/analytics", handlers.CallbackOnAnalytics(conf, bus, st))
+	app.Post("/webhooks/bbb", handlers.BBBWebhook(conf, bus))
 
 	// Custom middlewares AFTER endpoints
 	app.Use(handlers.DefaultRouteNotFound)
@@ -7,6 +7,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/kurvaid/bbb-interface/internal/store"
 )
 
 var fakeServer = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
@@ -16,13 +18,13 @@ func TestSetupRoutes(t *testing.T) {
 		conf := config.Model{}
 		app := fiber.New()
 
-		SetupRoutes(app, &conf, fakeServer.Client())
+		SetupRoutes(app, &conf, fakeServer.Client(), event.NewBus(), nil, store.New(store.NewMemory()))
 	})
 
 	t.Run("2# Success test with only one or more supplied value", func(t *testing.T) {
 		conf := config.Model{EnvIsProd: true}
 		app := fiber.New()
 
-		SetupRoutes(app, &conf, fakeServer.Client())
+		SetupRoutes(app, &conf, fakeServer.Client(), event.NewBus(), nil, store.New(store.NewMemory()))
 	})
 }
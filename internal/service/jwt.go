@@ -0,0 +1,97 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+// jwtHashes hash functions of the supported JWT signing algorithms.
+var jwtHashes = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// VerifyJWT verify the signature of the given HMAC signed JWT using the secret and bind
+// its claims to v. Token that has expired according to its `exp` claim is rejected.
+func VerifyJWT(token, secret string, v interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return fmt.Errorf("failed to decode token header: %s", err)
+	}
+
+	newHash, ok := jwtHashes[header.Alg]
+	if !ok {
+		return fmt.Errorf("unsupported signing algorithm `%s`", header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("failed to decode token signature: %s", err)
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return fmt.Errorf("invalid token signature")
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return fmt.Errorf("failed to decode token claims: %s", err)
+	}
+	if claims.Exp != 0 && time.Now().Unix() >= claims.Exp {
+		return fmt.Errorf("token has expired")
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return decodeJWTPart(parts[1], v)
+}
+
+// SignJWT return HS512 signed JWT of the given claims using the secret.
+func SignJWT(claims interface{}, secret string) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "HS512", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token claims: %s", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// decodeJWTPart decode base64 encoded json part of JWT to v.
+func decodeJWTPart(part string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,46 @@
+package service
+
+import "sync"
+
+// KeyedMutex mutual exclusion lock for each key, so things with different keys don't wait for
+// each other. The zero value is an unlocked mutex.
+type KeyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock lock of a key along with how many are holding or waiting for it.
+type keyedLock struct {
+	mu      sync.Mutex
+	holders int
+}
+
+// Lock lock the given key, wait until it's available if it's already locked. The returned
+// function unlock the key.
+func (k *KeyedMutex) Lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.holders++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		// forget the lock once nobody needs it so the map doesn't keep growing.
+		k.mu.Lock()
+		l.holders--
+		if l.holders == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	t.Run("Same key should be held by one at a time", func(t *testing.T) {
+		var k KeyedMutex
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		holding, most := 0, 0
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock := k.Lock("meet01")
+				defer unlock()
+
+				mu.Lock()
+				holding++
+				if holding > most {
+					most = holding
+				}
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				holding--
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, most)
+		assert.Empty(t, k.locks, "unused lock should be forgotten")
+	})
+
+	t.Run("Different keys should not wait for each other", func(t *testing.T) {
+		var k KeyedMutex
+		unlock := k.Lock("meet01")
+		defer unlock()
+
+		done := make(chan struct{})
+		go func() {
+			k.Lock("meet02")()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("lock of other key should not wait")
+		}
+	})
+}
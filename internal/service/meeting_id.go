@@ -0,0 +1,81 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Formats of generated meeting ID.
+const (
+	IdLetters = "letters" // Random characters, see SecureString.
+	IdUUID    = "uuid"    // Random (version 4) UUID.
+	IdULID    = "ulid"    // ULID, which is sorted by the time it was generated.
+)
+
+// crockford alphabet of ULID, which leaves out I, L, O and U.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewIdGenerator return generator of meeting ID in the given format. Letters are used for unknown
+// format, length and alphabet are only used by letters.
+func NewIdGenerator(format string, length int, alphabet string) RandStringInterface {
+	switch format {
+	case IdUUID:
+		return UUID{}
+	case IdULID:
+		return ULID{}
+	}
+
+	return &SecureString{Length: length, Alphabet: alphabet}
+}
+
+// UUID generator of random (version 4) UUID.
+type UUID struct{}
+
+// RandString generate UUID such as 0b9e3c54-8f6a-4d2e-9a71-3c5f2d8e1b07.
+func (UUID) RandString() string {
+	b := randomBytes(16)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+
+	h := hex.EncodeToString(b)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:])
+}
+
+// ULID generator of ULID, 48 bits of milliseconds since epoch followed by 80 random bits.
+type ULID struct{}
+
+// RandString generate ULID such as 01G0EZ7K3V9Q4T8XWJ2M5N6R1A.
+func (ULID) RandString() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*uint(i)))
+	}
+	copy(b[6:], randomBytes(10))
+
+	// encode 128 bits as 26 characters of 5 bits, the first one only has 3 bits.
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		bit := 128 - 5*(26-i)
+		var v byte
+		for j := 0; j < 5; j++ {
+			if pos := bit + j; pos >= 0 && b[pos/8]&(0x80>>uint(pos%8)) != 0 {
+				v |= 0x10 >> uint(j)
+			}
+		}
+		out[i] = crockford[v]
+	}
+
+	return string(out)
+}
+
+// randomBytes return n bytes from the secure random source of the OS.
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %s", err))
+	}
+	return b
+}
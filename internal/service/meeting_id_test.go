@@ -0,0 +1,58 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIdGenerator(t *testing.T) {
+	testCases := []struct {
+		name    string
+		format  string
+		pattern *regexp.Regexp
+	}{
+		{
+			name:    "Letters should have the given length",
+			format:  IdLetters,
+			pattern: regexp.MustCompile(`^[A-Za-z]{12}$`),
+		},
+		{
+			name:    "Unknown format should be letters",
+			format:  "",
+			pattern: regexp.MustCompile(`^[A-Za-z]{12}$`),
+		},
+		{
+			name:    "UUID should be version 4",
+			format:  IdUUID,
+			pattern: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		},
+		{
+			name:    "ULID should be 26 characters of crockford base32",
+			format:  IdULID,
+			pattern: regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewIdGenerator(tt.format, 12, "")
+			one, two := gen.RandString(), gen.RandString()
+			assert.Regexp(t, tt.pattern, one)
+			assert.Regexp(t, tt.pattern, two)
+			assert.NotEqual(t, one, two)
+		})
+	}
+}
+
+func TestULID_Sorted(t *testing.T) {
+	first := ULID{}.RandString()
+	time.Sleep(2 * time.Millisecond)
+	second := ULID{}.RandString()
+
+	assert.Less(t, first, second)
+	// the first 10 characters are the time, 0 ms since epoch would be all zeros.
+	assert.NotEqual(t, "0000000000", first[:10])
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -18,15 +19,23 @@ const (
 	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
 )
 
-var src = rand.NewSource(time.Now().UnixNano())
+var (
+	src   = rand.NewSource(time.Now().UnixNano())
+	srcMu sync.Mutex // rand.Source is not safe for concurrent use.
+)
 
 // RandomString service that generate random string based on given length.
+//
+// Deprecated: it's predictable, use SecureString for passwords and IDs.
 type RandomString struct {
 	Length int
 }
 
 // RandString generate random string.
 func (r *RandomString) RandString() string {
+	srcMu.Lock()
+	defer srcMu.Unlock()
+
 	b := make([]byte, r.Length)
 	// A src.Int63() generates 63 random bits, enough for letterIdxMax characters!
 	for i, cache, remain := r.Length-1, src.Int63(), letterIdxMax; i >= 0; {
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultAlphabet characters of SecureString that has no alphabet, the same as RandomString.
+const DefaultAlphabet = letterBytes
+
+// SecureString service that generate random string from the secure random source of the OS, so
+// it's unpredictable and safe for passwords, secret links and concurrent use.
+type SecureString struct {
+	Length   int
+	Alphabet string // Characters the string is made of, up to 256 of them. Default to DefaultAlphabet.
+}
+
+// RandString generate random string. Panics if the random source of the OS fails, there is no
+// safe fallback for secrets.
+func (s *SecureString) RandString() string {
+	alphabet := s.alphabet()
+	// bytes that are not below the largest multiple of the alphabet size are thrown away, so
+	// every character is equally likely.
+	limit := 256 - 256%len(alphabet)
+
+	b := make([]byte, s.Length)
+	for i := 0; i < s.Length; {
+		for _, r := range randomBytes(s.Length - i) {
+			if int(r) >= limit {
+				continue
+			}
+			b[i] = alphabet[int(r)%len(alphabet)]
+			i++
+		}
+	}
+
+	return string(b)
+}
+
+// Entropy return how many bits of entropy the generated string has.
+func (s *SecureString) Entropy() float64 {
+	return float64(s.Length) * math.Log2(float64(len(s.alphabet())))
+}
+
+func (s *SecureString) alphabet() string {
+	if s.Alphabet == "" {
+		return DefaultAlphabet
+	}
+	return s.Alphabet
+}
+
+// LengthForEntropy return the shortest length of string made of the given alphabet that has at
+// least the given bits of entropy.
+func LengthForEntropy(bits int, alphabet string) int {
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	return int(math.Ceil(float64(bits) / math.Log2(float64(len(alphabet)))))
+}
+
+// ValidateAlphabet check the given alphabet could be used by SecureString. Characters must be
+// unique and safe in URL without escaping, because passwords and IDs end up in join urls.
+func ValidateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return fmt.Errorf("alphabet must have at least 2 characters")
+	}
+
+	seen := make(map[rune]bool)
+	for _, r := range alphabet {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == '~':
+		default:
+			return fmt.Errorf("alphabet must only have letters, digits, `-`, `_`, `.` or `~`, found `%c`", r)
+		}
+		if seen[r] {
+			return fmt.Errorf("alphabet has `%c` more than once", r)
+		}
+		seen[r] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sample   SecureString
+		expected string
+	}{
+		{
+			name:     "String w/o alphabet should be made of letters",
+			sample:   SecureString{Length: 32},
+			expected: DefaultAlphabet,
+		},
+		{
+			name:     "String should be made of the given alphabet",
+			sample:   SecureString{Length: 32, Alphabet: "0123456789abcdef"},
+			expected: "0123456789abcdef",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			one, two := tt.sample.RandString(), tt.sample.RandString()
+			require.Len(t, one, tt.sample.Length)
+			assert.NotEqual(t, one, two)
+			for _, r := range one {
+				assert.True(t, strings.ContainsRune(tt.expected, r), "unexpected %c", r)
+			}
+		})
+	}
+
+	t.Run("Every character should be used", func(t *testing.T) {
+		s := SecureString{Length: 2000, Alphabet: "abc"}
+		out := s.RandString()
+		for _, r := range "abc" {
+			assert.InDelta(t, 667, strings.Count(out, string(r)), 150)
+		}
+	})
+
+	t.Run("Concurrent use should not race", func(t *testing.T) {
+		s := SecureString{Length: 16}
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Len(t, s.RandString(), 16)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestLengthForEntropy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bits     int
+		alphabet string
+		expected int
+	}{
+		{
+			name:     "128 bits of letters should be 23 characters",
+			bits:     128,
+			expected: 23,
+		},
+		{
+			name:     "128 bits of hex should be 32 characters",
+			bits:     128,
+			alphabet: "0123456789abcdef",
+			expected: 32,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			n := LengthForEntropy(tt.bits, tt.alphabet)
+			assert.Equal(t, tt.expected, n)
+			s := SecureString{Length: n, Alphabet: tt.alphabet}
+			assert.GreaterOrEqual(t, s.Entropy(), float64(tt.bits))
+		})
+	}
+}
+
+func TestValidateAlphabet(t *testing.T) {
+	testCases := []struct {
+		name      string
+		alphabet  string
+		expectErr bool
+	}{
+		{name: "Letters and digits should be valid", alphabet: "abcXYZ0129-_.~"},
+		{name: "One character should be invalid", alphabet: "a", expectErr: true},
+		{name: "Repeated character should be invalid", alphabet: "abca", expectErr: true},
+		{name: "Character that must be escaped in url should be invalid", alphabet: "ab&", expectErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAlphabet(tt.alphabet)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
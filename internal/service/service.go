@@ -1,7 +1,9 @@
 package service
 
 import (
+	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"strings"
 )
@@ -18,3 +20,22 @@ func SHA1HashUrl(sc, in string) string {
 	out := sha1.Sum([]byte(in))
 	return hex.EncodeToString(out[:])
 }
+
+// SHA1Hash hash the concatenation of given strings and return the result.
+func SHA1Hash(in ...string) string {
+	out := sha1.Sum([]byte(strings.Join(in, "")))
+	return hex.EncodeToString(out[:])
+}
+
+// HMACSHA256 sign the given string with the secret and return the signature as hex.
+func HMACSHA256(secret, in string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(in))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHMACSHA256 check whether the given signature is of the given string signed with the
+// secret, in constant time.
+func VerifyHMACSHA256(secret, in, signature string) bool {
+	return hmac.Equal([]byte(HMACSHA256(secret, in)), []byte(signature))
+}
@@ -39,3 +39,37 @@ func TestSHA1HashUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestSHA1Hash(t *testing.T) {
+	t.Run("Should hash the concatenation of every given string", func(t *testing.T) {
+		assert.Equal(t, SHA1Hash("thisshouldbehashed"), SHA1Hash("thisshould", "be", "hashed"))
+		assert.Equal(t, "ceb03b75323a3ae65a351130210396558ade157d", SHA1Hash("thisshouldbehashed", "secret"))
+	})
+}
+
+func TestHMACSHA256(t *testing.T) {
+	assert.Equal(t, "861146136349c86a95aaac7278a42c7e96ce0cd64af07a7b1c37ddea1d998b49", HMACSHA256("secret", "meet01"))
+}
+
+func TestVerifyHMACSHA256(t *testing.T) {
+	sig := HMACSHA256("secret", "meet01")
+
+	testCases := []struct {
+		name      string
+		secret    string
+		in        string
+		signature string
+		expect    bool
+	}{
+		{name: "Signature of the same string and secret should be valid", secret: "secret", in: "meet01", signature: sig, expect: true},
+		{name: "Signature of other string should be invalid", secret: "secret", in: "meet02", signature: sig},
+		{name: "Signature w other secret should be invalid", secret: "other", in: "meet01", signature: sig},
+		{name: "Empty signature should be invalid", secret: "secret", in: "meet01"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, VerifyHMACSHA256(tt.secret, tt.in, tt.signature))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+)
+
+const analyticsBucket = "analytics" // Learning dashboard data keyed by Meeting.Key.
+
+// Analytics learning dashboard data of a meeting along with when it was received.
+type Analytics struct {
+	ReceivedAt time.Time             `json:"received_at"`
+	Data       api.AnalyticsCallback `json:"data"`
+}
+
+// SaveAnalytics save the learning dashboard data of the latest meeting with the given ID.
+// Return ErrNotFound if the meeting is not recorded.
+func (s *Store) SaveAnalytics(meetingId string, data api.AnalyticsCallback) error {
+	m, err := s.Meeting(meetingId)
+	if err != nil {
+		return err
+	}
+
+	return s.put(analyticsBucket, m.Key, &Analytics{ReceivedAt: time.Now(), Data: data})
+}
+
+// Analytics return the learning dashboard data of the meeting record with the given key.
+func (s *Store) Analytics(meetingKey string) (a Analytics, err error) {
+	err = s.get(analyticsBucket, meetingKey, &a)
+	return
+}
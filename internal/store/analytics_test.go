@@ -0,0 +1,51 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Analytics(t *testing.T) {
+	st := New(NewMemory())
+	require.NoError(t, st.SaveMeeting(Meeting{MeetingId: "meet01", CreateTime: 1000}))
+
+	testCases := []struct {
+		name      string
+		meetingId string
+		expectErr error
+	}{
+		{
+			name:      "Analytics of recorded meeting should be saved",
+			meetingId: "meet01",
+		},
+		{
+			name:      "Analytics of unknown meeting should return ErrNotFound",
+			meetingId: "unknown",
+			expectErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			data := api.AnalyticsCallback{MeetingId: tt.meetingId, Data: api.AnalyticsData{Duration: 60}}
+			err := st.SaveAnalytics(tt.meetingId, data)
+			assert.Equal(t, tt.expectErr, err)
+			if err != nil {
+				return
+			}
+
+			a, err := st.Analytics(MeetingKey(tt.meetingId, 1000))
+			require.NoError(t, err)
+			assert.Equal(t, int64(60), a.Data.Data.Duration)
+			assert.False(t, a.ReceivedAt.IsZero())
+		})
+	}
+
+	t.Run("Meeting w/o analytics should return ErrNotFound", func(t *testing.T) {
+		_, err := st.Analytics("unknown")
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// attendanceBucket return bucket of attendances of the meeting record with the given key.
+func attendanceBucket(meetingKey string) string {
+	return "attendance:" + meetingKey
+}
+
+// Session a period of time a user was in a meeting.
+type Session struct {
+	JoinedAt time.Time  `json:"joined_at"`
+	LeftAt   *time.Time `json:"left_at,omitempty"` // Nil if the user is still in the meeting.
+}
+
+// Attendance record of a user in a meeting, keyed by the user ID given when joining.
+type Attendance struct {
+	UserId   string    `json:"user_id"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+	Sessions []Session `json:"sessions"` // Every time the user joined, in order.
+}
+
+// Present return whether the user is still in the meeting.
+func (a *Attendance) Present() bool {
+	return len(a.Sessions) > 0 && a.Sessions[len(a.Sessions)-1].LeftAt == nil
+}
+
+// FirstJoin return when the user joined for the first time.
+func (a *Attendance) FirstJoin() time.Time {
+	if len(a.Sessions) == 0 {
+		return time.Time{}
+	}
+
+	return a.Sessions[0].JoinedAt
+}
+
+// LastLeave return when the user left for the last time, or nil if the user is still
+// in the meeting.
+func (a *Attendance) LastLeave() *time.Time {
+	if len(a.Sessions) == 0 {
+		return nil
+	}
+
+	return a.Sessions[len(a.Sessions)-1].LeftAt
+}
+
+// TotalTime return how long the user was in the meeting over every session. Sessions
+// that haven't ended are counted until now.
+func (a *Attendance) TotalTime(now time.Time) (total time.Duration) {
+	for _, s := range a.Sessions {
+		end := now
+		if s.LeftAt != nil {
+			end = *s.LeftAt
+		}
+		total += end.Sub(s.JoinedAt)
+	}
+
+	return
+}
+
+// Reconnects return how many times the user joined again after leaving.
+func (a *Attendance) Reconnects() int {
+	if len(a.Sessions) == 0 {
+		return 0
+	}
+
+	return len(a.Sessions) - 1
+}
+
+// Attendances return attendance of every user in the meeting record with the given key,
+// ordered by the time they joined first.
+func (s *Store) Attendances(meetingKey string) ([]Attendance, error) {
+	atts := make([]Attendance, 0)
+
+	var a Attendance
+	err := s.each(attendanceBucket(meetingKey), &a, func() error {
+		atts = append(atts, a)
+		a = Attendance{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(atts, func(i, j int) bool {
+		return atts[i].FirstJoin().Before(atts[j].FirstJoin())
+	})
+
+	return atts, nil
+}
+
+// joinAttendance start new session of the user in the meeting record with the given key.
+func (s *Store) joinAttendance(meetingKey, userId, name, role string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var a Attendance
+	if err := s.get(attendanceBucket(meetingKey), userId, &a); err != nil && err != ErrNotFound {
+		return err
+	}
+
+	a.UserId = userId
+	if name != "" {
+		a.Name = name
+	}
+	if role != "" {
+		a.Role = role
+	}
+	// the user could already be in the meeting, e.g. the join was heard from both webhook
+	// and polling before the bus knew about it.
+	if !a.Present() {
+		a.Sessions = append(a.Sessions, Session{JoinedAt: at})
+	}
+
+	return s.put(attendanceBucket(meetingKey), userId, &a)
+}
+
+// leaveAttendance end the current session of the user in the meeting record with the given key.
+func (s *Store) leaveAttendance(meetingKey, userId string, at time.Time) error {
+	var a Attendance
+	err := s.update(attendanceBucket(meetingKey), userId, &a, func() error {
+		if a.Present() {
+			a.Sessions[len(a.Sessions)-1].LeftAt = &at
+		}
+		return nil
+	})
+	if err == ErrNotFound {
+		return nil
+	}
+
+	return err
+}
+
+// closeAttendances end the current session of every user in the meeting record with the
+// given key, because the meeting has ended.
+func (s *Store) closeAttendances(meetingKey string, at time.Time) error {
+	atts, err := s.Attendances(meetingKey)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range atts {
+		if !a.Present() {
+			continue
+		}
+		if err := s.leaveAttendance(meetingKey, a.UserId, at); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
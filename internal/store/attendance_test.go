@@ -0,0 +1,83 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Attendances(t *testing.T) {
+	st := New(NewMemory())
+	require.NoError(t, st.SaveMeeting(Meeting{MeetingId: "meet01", CreateTime: 1000}))
+	at := func(min int) time.Time { return time.Unix(int64(min*60), 0) }
+	usr := func(id, name string) *event.User { return &event.User{Id: id, Name: name, Role: "VIEWER"} }
+
+	for _, e := range []event.Event{
+		{Type: event.UserJoined, MeetingId: "meet01", User: usr("usr02", "Mhs"), Timestamp: at(5)},
+		{Type: event.UserJoined, MeetingId: "meet01", User: usr("usr01", "NzK"), Timestamp: at(1)},
+		// the same join heard from another source should not start new session.
+		{Type: event.UserJoined, MeetingId: "meet01", User: usr("usr01", "NzK"), Timestamp: at(2)},
+		{Type: event.UserLeft, MeetingId: "meet01", User: usr("usr01", ""), Timestamp: at(10)},
+		{Type: event.UserJoined, MeetingId: "meet01", User: usr("usr01", "NzK"), Timestamp: at(20)},
+		{Type: event.UserLeft, MeetingId: "meet01", User: usr("unknown", ""), Timestamp: at(25)},
+		{Type: event.MeetingEnded, MeetingId: "meet01", Timestamp: at(30)},
+	} {
+		require.NoError(t, st.Record(e))
+	}
+
+	m, err := st.Meeting("meet01")
+	require.NoError(t, err)
+	atts, err := st.Attendances(m.Key)
+	require.NoError(t, err)
+	require.Len(t, atts, 2)
+
+	testCases := []struct {
+		name       string
+		sample     Attendance
+		userId     string
+		firstJoin  time.Time
+		lastLeave  time.Time
+		totalTime  time.Duration
+		reconnects int
+	}{
+		{
+			name:       "User that reconnected should have every session counted",
+			sample:     atts[0],
+			userId:     "usr01",
+			firstJoin:  at(1),
+			lastLeave:  at(30),
+			totalTime:  19 * time.Minute,
+			reconnects: 1,
+		},
+		{
+			name:       "User that was still in the meeting should leave when it ended",
+			sample:     atts[1],
+			userId:     "usr02",
+			firstJoin:  at(5),
+			lastLeave:  at(30),
+			totalTime:  25 * time.Minute,
+			reconnects: 0,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.userId, tt.sample.UserId)
+			assert.Equal(t, tt.firstJoin.Unix(), tt.sample.FirstJoin().Unix())
+			require.NotNil(t, tt.sample.LastLeave())
+			assert.Equal(t, tt.lastLeave.Unix(), tt.sample.LastLeave().Unix())
+			assert.Equal(t, tt.totalTime, tt.sample.TotalTime(time.Now()))
+			assert.Equal(t, tt.reconnects, tt.sample.Reconnects())
+			assert.False(t, tt.sample.Present())
+		})
+	}
+
+	t.Run("Meeting w/o attendance should return empty list", func(t *testing.T) {
+		atts, err := st.Attendances("unknown")
+		require.NoError(t, err)
+		assert.Empty(t, atts)
+	})
+}
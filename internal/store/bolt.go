@@ -0,0 +1,87 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bolt Backend that persist every value in embedded bbolt database file.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// NewBolt open or create bbolt database file in the given path.
+func NewBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database file: %s", err)
+	}
+
+	return &Bolt{db: db}, nil
+}
+
+// Get return value of the given key in the bucket.
+func (b *Bolt) Get(bucket, key string) (val []byte, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucket))
+		if bk == nil {
+			return ErrNotFound
+		}
+
+		v := bk.Get([]byte(key))
+		if v == nil {
+			return ErrNotFound
+		}
+		// value is only valid while the transaction is open.
+		val = append([]byte(nil), v...)
+
+		return nil
+	})
+
+	return
+}
+
+// Put save the value of the given key in the bucket.
+func (b *Bolt) Put(bucket, key string, value []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bk, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %s", bucket, err)
+		}
+
+		return bk.Put([]byte(key), value)
+	})
+}
+
+// Delete remove the given key from the bucket.
+func (b *Bolt) Delete(bucket, key string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucket))
+		if bk == nil {
+			return nil
+		}
+
+		return bk.Delete([]byte(key))
+	})
+}
+
+// ForEach call fn with every key and value in the bucket in order of the keys.
+func (b *Bolt) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		bk := tx.Bucket([]byte(bucket))
+		if bk == nil {
+			return nil
+		}
+
+		return bk.ForEach(func(k, v []byte) error {
+			return fn(string(k), v)
+		})
+	})
+}
+
+// Close close the database file.
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const guestBucket = "guests" // Every guest that asked to join a meeting keyed by Guest.Id.
+
+// Status of a guest that asked to join a meeting.
+const (
+	GuestWaiting  = "waiting"  // The guest is waiting for a decision.
+	GuestApproved = "approved" // The guest could join the meeting.
+	GuestDenied   = "denied"   // The guest could not join the meeting.
+)
+
+// ErrGuestDecided returned when deciding on a guest that has been approved or denied.
+var ErrGuestDecided = errors.New("guest has been decided")
+
+// ErrGuestClaimed returned when claiming a guest that has been claimed by another browser.
+var ErrGuestClaimed = errors.New("guest has been claimed by another browser")
+
+// Guest user that asked to join a meeting as guest and is screened by the staff. Its ID is the
+// secret in the link that the guest is waiting on.
+type Guest struct {
+	Id        string     `json:"id"`
+	MeetingId string     `json:"meeting_id"`
+	Client    string     `json:"client,omitempty"`
+	Name      string     `json:"name"`             // Full name of the guest in the meeting.
+	UserId    string     `json:"user_id"`          // Identifier of the guest in the client app.
+	Avatar    string     `json:"avatar,omitempty"` // Link of the guest's avatar.
+	Status    string     `json:"status"`           // One of the guest statuses.
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"` // When the guest was approved or denied.
+	Browser   string     `json:"browser,omitempty"`    // Hash of the key of the browser that opened the link first.
+}
+
+// SaveGuest save the given guest, replacing the one with the same ID if any.
+func (s *Store) SaveGuest(g Guest) error {
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now()
+	}
+
+	if err := s.put(guestBucket, g.Id, &g); err != nil {
+		return fmt.Errorf("failed to save guest: %s", err)
+	}
+
+	return nil
+}
+
+// Guest return the guest with the given ID.
+func (s *Store) Guest(id string) (g Guest, err error) {
+	err = s.get(guestBucket, id, &g)
+	return
+}
+
+// Guests return the guests of the given meeting ID that have the given status, or every status
+// if it's empty, in the order they asked to join.
+func (s *Store) Guests(meetingId, status string) ([]Guest, error) {
+	guests := []Guest{}
+	var g Guest
+	err := s.each(guestBucket, &g, func() error {
+		if g.MeetingId == meetingId && (status == "" || g.Status == status) {
+			guests = append(guests, g)
+		}
+		// fields that are omitted in the next one must not be kept.
+		g = Guest{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read guests: %s", err)
+	}
+
+	sort.SliceStable(guests, func(i, j int) bool {
+		return guests[i].CreatedAt.Before(guests[j].CreatedAt)
+	})
+
+	return guests, nil
+}
+
+// DecideGuest set the status of the waiting guest with the given ID to either GuestApproved or
+// GuestDenied and return it. ErrGuestDecided is returned if it has been decided.
+func (s *Store) DecideGuest(id, status string, at time.Time) (Guest, error) {
+	var g Guest
+	err := s.update(guestBucket, id, &g, func() error {
+		if g.Status != GuestWaiting {
+			return ErrGuestDecided
+		}
+		g.Status = status
+		g.DecidedAt = &at
+		return nil
+	})
+
+	return g, err
+}
+
+// ClaimGuest bind the guest with the given ID to the browser of the given key hash and return it.
+// ErrGuestClaimed is returned if it has been bound to another browser.
+func (s *Store) ClaimGuest(id, browser string) (Guest, error) {
+	var g Guest
+	err := s.update(guestBucket, id, &g, func() error {
+		if g.Browser != "" && g.Browser != browser {
+			return ErrGuestClaimed
+		}
+		g.Browser = browser
+		return nil
+	})
+
+	return g, err
+}
+
+// DeleteGuest remove the guest with the given ID.
+func (s *Store) DeleteGuest(id string) error {
+	return s.db.Delete(guestBucket, id)
+}
+
+// PurgeGuests remove every guest that asked to join before the given time and return how many
+// were removed.
+func (s *Store) PurgeGuests(before time.Time) (int, error) {
+	// collect the IDs first, guests could not be removed while iterating.
+	var ids []string
+	var g Guest
+	err := s.each(guestBucket, &g, func() error {
+		if g.CreatedAt.Before(before) {
+			ids = append(ids, g.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read guests: %s", err)
+	}
+
+	for i, id := range ids {
+		if err := s.DeleteGuest(id); err != nil {
+			return i, fmt.Errorf("failed to delete guest %s: %s", id, err)
+		}
+	}
+
+	return len(ids), nil
+}
+
+// RunGuests remove the guests that asked to join longer than maxAge ago every interval until
+// stop is closed.
+func (s *Store) RunGuests(maxAge, interval time.Duration, stop <-chan struct{}, onErr func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeGuests(time.Now().Add(-maxAge)); err != nil {
+				onErr(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Guests(t *testing.T) {
+	st := New(NewMemory())
+	now := time.Now()
+	require.NoError(t, st.SaveGuest(Guest{Id: "g02", MeetingId: "meet01", Status: GuestWaiting, CreatedAt: now}))
+	require.NoError(t, st.SaveGuest(Guest{Id: "g01", MeetingId: "meet01", Status: GuestWaiting, CreatedAt: now.Add(-time.Minute)}))
+	require.NoError(t, st.SaveGuest(Guest{Id: "g03", MeetingId: "meet01", Status: GuestApproved, CreatedAt: now}))
+	require.NoError(t, st.SaveGuest(Guest{Id: "g04", MeetingId: "meet02", Status: GuestWaiting, CreatedAt: now}))
+
+	testCases := []struct {
+		name   string
+		status string
+		expect []string
+	}{
+		{name: "Waiting guests in the order they asked", status: GuestWaiting, expect: []string{"g01", "g02"}},
+		{name: "Every guest of the meeting", expect: []string{"g01", "g02", "g03"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			guests, err := st.Guests("meet01", tt.status)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, g := range guests {
+				ids = append(ids, g.Id)
+			}
+			assert.Equal(t, tt.expect, ids)
+		})
+	}
+}
+
+func TestStore_DecideGuest(t *testing.T) {
+	st := New(NewMemory())
+	now := time.Now()
+	require.NoError(t, st.SaveGuest(Guest{Id: "g01", MeetingId: "meet01", Status: GuestWaiting}))
+
+	g, err := st.DecideGuest("g01", GuestApproved, now)
+	require.NoError(t, err)
+	assert.Equal(t, GuestApproved, g.Status)
+	require.NotNil(t, g.DecidedAt)
+
+	_, err = st.DecideGuest("g01", GuestDenied, now)
+	assert.Equal(t, ErrGuestDecided, err, "decided guest should not be decided again")
+
+	_, err = st.DecideGuest("unknown", GuestApproved, now)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestStore_ClaimGuest(t *testing.T) {
+	st := New(NewMemory())
+	require.NoError(t, st.SaveGuest(Guest{Id: "g01", MeetingId: "meet01", Status: GuestWaiting}))
+
+	g, err := st.ClaimGuest("g01", "browser01")
+	require.NoError(t, err)
+	assert.Equal(t, "browser01", g.Browser)
+
+	_, err = st.ClaimGuest("g01", "browser01")
+	assert.NoError(t, err, "the same browser should claim again")
+
+	_, err = st.ClaimGuest("g01", "browser02")
+	assert.Equal(t, ErrGuestClaimed, err, "claimed guest should not be claimed by another browser")
+
+	_, err = st.ClaimGuest("unknown", "browser01")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestStore_PurgeGuests(t *testing.T) {
+	st := New(NewMemory())
+	now := time.Now()
+	require.NoError(t, st.SaveGuest(Guest{Id: "old", CreatedAt: now.Add(-time.Minute)}))
+	require.NoError(t, st.SaveGuest(Guest{Id: "new", CreatedAt: now.Add(time.Minute)}))
+
+	n, err := st.PurgeGuests(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = st.Guest("old")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = st.Guest("new")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor returned when the cursor of history query was not returned by History.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Status of meeting in history query.
+const (
+	StatusRunning = "running"
+	StatusEnded   = "ended"
+)
+
+// HistoryQuery filters and page of meeting history. Zero value of every field means
+// the records are not filtered by it.
+type HistoryQuery struct {
+	Client   string    // Only meetings of this client.
+	SeriesId string    // Only occurrences of this series.
+	ParentId string    // Only breakout rooms of this meeting ID.
+	Name     string    // Only meetings that have this substring in their name, case-insensitive.
+	From     time.Time // Only meetings that were created at or after this time.
+	To       time.Time // Only meetings that were created before this time.
+	Status   string    // Only meetings that are in this status, either StatusRunning or StatusEnded.
+	Recorded *bool     // Only meetings that have or don't have any recording.
+	Cursor   string    // Continue from the page that returned this cursor.
+	Limit    int       // Maximum number of meetings in a page.
+}
+
+// match return whether the meeting pass every filter of the query.
+func (q *HistoryQuery) match(m *Meeting) bool {
+	switch {
+	case q.Client != "" && m.Client != q.Client:
+		return false
+	case q.SeriesId != "" && m.SeriesId != q.SeriesId:
+		return false
+	case q.ParentId != "" && m.ParentId != q.ParentId:
+		return false
+	case q.Name != "" && !strings.Contains(strings.ToLower(m.Name), strings.ToLower(q.Name)):
+		return false
+	case !q.From.IsZero() && m.CreatedAt.Before(q.From):
+		return false
+	case !q.To.IsZero() && !m.CreatedAt.Before(q.To):
+		return false
+	case q.Status == StatusRunning && !m.Running():
+		return false
+	case q.Status == StatusEnded && !m.Ended():
+		return false
+	case q.Recorded != nil && (len(m.RecordIds) > 0) != *q.Recorded:
+		return false
+	}
+
+	return true
+}
+
+// History return meetings that match the query, the most recently created first,
+// along with cursor of the next page. The cursor is empty if there is no next page.
+func (s *Store) History(q HistoryQuery) ([]Meeting, string, error) {
+	var after *historyCursor
+	if q.Cursor != "" {
+		cur, err := parseHistoryCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &cur
+	}
+
+	var meets []Meeting
+	err := s.EachMeeting(func(m Meeting) error {
+		if !q.match(&m) {
+			return nil
+		}
+		if after != nil && !after.before(&m) {
+			return nil
+		}
+		meets = append(meets, m)
+
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read meetings: %s", err)
+	}
+
+	sort.Slice(meets, func(i, j int) bool {
+		return newHistoryCursor(&meets[i]).before(&meets[j])
+	})
+
+	var next string
+	if q.Limit > 0 && len(meets) > q.Limit {
+		meets = meets[:q.Limit]
+		next = newHistoryCursor(&meets[q.Limit-1]).String()
+	}
+
+	return meets, next, nil
+}
+
+// historyCursor position of a meeting in the history.
+type historyCursor struct {
+	createdAt int64 // Creation time in nanoseconds.
+	key       string
+}
+
+func newHistoryCursor(m *Meeting) historyCursor {
+	return historyCursor{createdAt: m.CreatedAt.UnixNano(), key: m.Key}
+}
+
+// parseHistoryCursor parse cursor that was returned from String.
+func parseHistoryCursor(s string) (historyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return historyCursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "/", 2)
+	if len(parts) != 2 {
+		return historyCursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return historyCursor{}, ErrInvalidCursor
+	}
+
+	return historyCursor{createdAt: createdAt, key: parts[1]}, nil
+}
+
+// String return the cursor as opaque url-safe string.
+func (c historyCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d/%s", c.createdAt, c.key)))
+}
+
+// before return whether the cursor come before the given meeting in the history.
+func (c historyCursor) before(m *Meeting) bool {
+	createdAt := m.CreatedAt.UnixNano()
+	if c.createdAt != createdAt {
+		return c.createdAt > createdAt
+	}
+
+	return c.key > m.Key
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_History(t *testing.T) {
+	st := New(NewMemory())
+	created := time.Unix(1000, 0)
+	// meetings created at the same time should still be paged in stable order.
+	for _, id := range []string{"meet01", "meet02", "meet03"} {
+		require.NoError(t, st.SaveMeeting(Meeting{MeetingId: id, CreatedAt: created, CreateTime: 1000}))
+	}
+
+	t.Run("Should page through every meeting w/o duplicate", func(t *testing.T) {
+		var ids []string
+		q := HistoryQuery{Limit: 2}
+		for {
+			meets, next, err := st.History(q)
+			require.NoError(t, err)
+			for _, m := range meets {
+				ids = append(ids, m.MeetingId)
+			}
+			if next == "" {
+				break
+			}
+			q.Cursor = next
+		}
+		assert.Equal(t, []string{"meet03", "meet02", "meet01"}, ids)
+	})
+
+	t.Run("Should only return breakout rooms of the parent", func(t *testing.T) {
+		require.NoError(t, st.SaveMeeting(Meeting{MeetingId: "meet01-breakout-1", ParentId: "meet01", CreatedAt: created, CreateTime: 1000}))
+		meets, _, err := st.History(HistoryQuery{ParentId: "meet01"})
+		require.NoError(t, err)
+		require.Len(t, meets, 1)
+		assert.Equal(t, "meet01-breakout-1", meets[0].MeetingId)
+	})
+
+	t.Run("Invalid cursor should return ErrInvalidCursor", func(t *testing.T) {
+		_, _, err := st.History(HistoryQuery{Cursor: "bm9wZQ"})
+		assert.Equal(t, ErrInvalidCursor, err)
+	})
+}
+
+func TestMeeting_Duration(t *testing.T) {
+	start, end := time.Unix(1000, 0), time.Unix(1600, 0)
+
+	testCases := []struct {
+		name   string
+		sample Meeting
+		expect time.Duration
+	}{
+		{name: "Meeting nobody joined should have no duration", sample: Meeting{}, expect: 0},
+		{name: "Ended meeting should last until it ended", sample: Meeting{StartedAt: &start, EndedAt: &end}, expect: 10 * time.Minute},
+		{name: "Running meeting should last until now", sample: Meeting{StartedAt: &start}, expect: 20 * time.Minute},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.sample.Duration(time.Unix(2200, 0)))
+		})
+	}
+}
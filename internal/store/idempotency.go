@@ -0,0 +1,81 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+const idempotencyBucket = "idempotency_keys" // Response of every idempotent request keyed by IdempotentResponse.Key.
+
+// IdempotentResponse response that was sent to a request with an idempotency key, so retries of
+// the request get the same response instead of doing it again.
+type IdempotentResponse struct {
+	Key         string    `json:"key"`          // Idempotency key along with who sent it to which endpoint.
+	RequestHash string    `json:"request_hash"` // Hash of the request body, to detect the key is reused for another request.
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SaveIdempotentResponse save the given response, replacing the one with the same key if any.
+func (s *Store) SaveIdempotentResponse(r IdempotentResponse) error {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+
+	if err := s.put(idempotencyBucket, r.Key, &r); err != nil {
+		return fmt.Errorf("failed to save idempotent response: %s", err)
+	}
+
+	return nil
+}
+
+// IdempotentResponse return the response that was saved with the given key.
+func (s *Store) IdempotentResponse(key string) (r IdempotentResponse, err error) {
+	err = s.get(idempotencyBucket, key, &r)
+	return
+}
+
+// PurgeIdempotentResponses remove every response that has expired before the given time and
+// return how many were removed.
+func (s *Store) PurgeIdempotentResponses(before time.Time) (int, error) {
+	// collect the keys first, responses could not be removed while iterating.
+	var keys []string
+	var r IdempotentResponse
+	err := s.each(idempotencyBucket, &r, func() error {
+		if r.ExpiresAt.Before(before) {
+			keys = append(keys, r.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read idempotent responses: %s", err)
+	}
+
+	for i, key := range keys {
+		if err := s.db.Delete(idempotencyBucket, key); err != nil {
+			return i, fmt.Errorf("failed to delete idempotent response %s: %s", key, err)
+		}
+	}
+
+	return len(keys), nil
+}
+
+// RunIdempotentResponses remove the expired responses every interval until stop is closed.
+func (s *Store) RunIdempotentResponses(interval time.Duration, stop <-chan struct{}, onErr func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeIdempotentResponses(time.Now()); err != nil {
+				onErr(err)
+			}
+		}
+	}
+}
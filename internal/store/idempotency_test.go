@@ -0,0 +1,29 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_IdempotentResponse(t *testing.T) {
+	st := New(NewMemory())
+	now := time.Now()
+	require.NoError(t, st.SaveIdempotentResponse(IdempotentResponse{Key: "lms/create/key01", Status: 200, Body: []byte(`{"meeting_id":"meet01"}`), ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, st.SaveIdempotentResponse(IdempotentResponse{Key: "lms/create/key02", Status: 200, ExpiresAt: now.Add(-time.Minute)}))
+
+	r, err := st.IdempotentResponse("lms/create/key01")
+	require.NoError(t, err)
+	assert.Equal(t, `{"meeting_id":"meet01"}`, string(r.Body))
+
+	n, err := st.PurgeIdempotentResponses(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = st.IdempotentResponse("lms/create/key02")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = st.IdempotentResponse("lms/create/key01")
+	assert.NoError(t, err)
+}
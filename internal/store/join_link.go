@@ -0,0 +1,116 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const joinLinkBucket = "join_links" // Every join link keyed by JoinLink.Id.
+
+var (
+	// ErrJoinLinkExpired returned when using a join link after it has expired.
+	ErrJoinLinkExpired = errors.New("join link has expired")
+	// ErrJoinLinkUsedUp returned when using a join link that has been used as many times as allowed.
+	ErrJoinLinkUsedUp = errors.New("join link has been used up")
+)
+
+// JoinLink link that join a particular user to a meeting a limited number of times before
+// it expires. Its ID is the secret in the link.
+type JoinLink struct {
+	Id        string    `json:"id"`
+	MeetingId string    `json:"meeting_id"`
+	Client    string    `json:"client,omitempty"`
+	Name      string    `json:"name"`             // Full name of the user in the meeting.
+	UserId    string    `json:"user_id"`          // Identifier of the user in the client app.
+	Avatar    string    `json:"avatar,omitempty"` // Link of the user's avatar.
+	Moderator bool      `json:"moderator"`        // Whether the user join as moderator.
+	MaxUses   int       `json:"max_uses"`         // How many times the link could be used.
+	Uses      int       `json:"uses"`             // How many times the link has been used.
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SaveJoinLink save the given join link, replacing the one with the same ID if any.
+func (s *Store) SaveJoinLink(l JoinLink) error {
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+
+	if err := s.put(joinLinkBucket, l.Id, &l); err != nil {
+		return fmt.Errorf("failed to save join link: %s", err)
+	}
+
+	return nil
+}
+
+// JoinLink return the join link with the given ID.
+func (s *Store) JoinLink(id string) (l JoinLink, err error) {
+	err = s.get(joinLinkBucket, id, &l)
+	return
+}
+
+// UseJoinLink count a use of the join link with the given ID at the given time and return it.
+// ErrJoinLinkExpired or ErrJoinLinkUsedUp is returned if it could not be used anymore.
+func (s *Store) UseJoinLink(id string, at time.Time) (JoinLink, error) {
+	var l JoinLink
+	err := s.update(joinLinkBucket, id, &l, func() error {
+		switch {
+		case !at.Before(l.ExpiresAt):
+			return ErrJoinLinkExpired
+		case l.Uses >= l.MaxUses:
+			return ErrJoinLinkUsedUp
+		}
+		l.Uses++
+		return nil
+	})
+
+	return l, err
+}
+
+// DeleteJoinLink remove the join link with the given ID.
+func (s *Store) DeleteJoinLink(id string) error {
+	return s.db.Delete(joinLinkBucket, id)
+}
+
+// PurgeJoinLinks remove every join link that has expired before the given time and return how
+// many were removed.
+func (s *Store) PurgeJoinLinks(before time.Time) (int, error) {
+	// collect the IDs first, links could not be removed while iterating.
+	var ids []string
+	var l JoinLink
+	err := s.each(joinLinkBucket, &l, func() error {
+		if l.ExpiresAt.Before(before) {
+			ids = append(ids, l.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read join links: %s", err)
+	}
+
+	for i, id := range ids {
+		if err := s.DeleteJoinLink(id); err != nil {
+			return i, fmt.Errorf("failed to delete join link %s: %s", id, err)
+		}
+	}
+
+	return len(ids), nil
+}
+
+// RunJoinLinks remove the expired join links every interval until stop is closed.
+func (s *Store) RunJoinLinks(interval time.Duration, stop <-chan struct{}, onErr func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeJoinLinks(time.Now()); err != nil {
+				onErr(err)
+			}
+		}
+	}
+}
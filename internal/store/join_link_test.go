@@ -0,0 +1,78 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_UseJoinLink(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name    string
+		link    JoinLink
+		uses    int
+		expects []error
+	}{
+		{
+			name:    "Single-use link should only be used once",
+			link:    JoinLink{Id: "link01", MaxUses: 1, ExpiresAt: now.Add(time.Hour)},
+			uses:    2,
+			expects: []error{nil, ErrJoinLinkUsedUp},
+		},
+		{
+			name:    "N-use link should be used N times",
+			link:    JoinLink{Id: "link02", MaxUses: 3, ExpiresAt: now.Add(time.Hour)},
+			uses:    4,
+			expects: []error{nil, nil, nil, ErrJoinLinkUsedUp},
+		},
+		{
+			name:    "Expired link should not be used",
+			link:    JoinLink{Id: "link03", MaxUses: 1, ExpiresAt: now},
+			uses:    1,
+			expects: []error{ErrJoinLinkExpired},
+		},
+		{
+			name:    "Unknown link should not be found",
+			link:    JoinLink{Id: "unknown"},
+			uses:    1,
+			expects: []error{ErrNotFound},
+		},
+	}
+
+	st := New(NewMemory())
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.link.MaxUses > 0 {
+				require.NoError(t, st.SaveJoinLink(tt.link))
+			}
+
+			for i := 0; i < tt.uses; i++ {
+				l, err := st.UseJoinLink(tt.link.Id, now)
+				assert.Equal(t, tt.expects[i], err, "use #%d", i+1)
+				if err == nil {
+					assert.Equal(t, i+1, l.Uses)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_PurgeJoinLinks(t *testing.T) {
+	st := New(NewMemory())
+	now := time.Now()
+	require.NoError(t, st.SaveJoinLink(JoinLink{Id: "expired", MaxUses: 1, ExpiresAt: now.Add(-time.Minute)}))
+	require.NoError(t, st.SaveJoinLink(JoinLink{Id: "valid", MaxUses: 1, ExpiresAt: now.Add(time.Minute)}))
+
+	n, err := st.PurgeJoinLinks(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = st.JoinLink("expired")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = st.JoinLink("valid")
+	assert.NoError(t, err)
+}
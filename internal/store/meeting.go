@@ -0,0 +1,193 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+)
+
+const (
+	meetingBucket   = "meetings"    // Every meeting record keyed by Meeting.Key.
+	meetingIdBucket = "meeting_ids" // Meeting ID mapped to key of its latest record.
+)
+
+// Meeting record of a meeting lifecycle from the time it's created until it's ended. A
+// meeting ID could be reused after the meeting ended, every reuse has its own record.
+type Meeting struct {
+	Key               string            `json:"key"`                           // Identify this record, see MeetingKey.
+	MeetingId         string            `json:"meeting_id"`                    // Meeting ID that was given when creating the meeting.
+	InternalMeetingId string            `json:"internal_meeting_id,omitempty"` // Meeting ID that was generated by BBB server.
+	Name              string            `json:"name"`                          // Name of the meeting.
+	Client            string            `json:"client,omitempty"`              // Name of the client that created the meeting.
+	SeriesId          string            `json:"series_id,omitempty"`           // Series that the meeting is an occurrence of.
+	ParentId          string            `json:"parent_id,omitempty"`           // Meeting ID of the meeting that this is a breakout room of.
+	AssignedUsers     []string          `json:"assigned_users,omitempty"`      // User IDs that are assigned to this breakout room.
+	AttendeePass      string            `json:"attendee_pass,omitempty"`       // Password to join as attendee.
+	ModeratorPass     string            `json:"moderator_pass,omitempty"`      // Password to join as moderator.
+	CreateTime        int64             `json:"create_time"`                   // Creation time returned by BBB server in milliseconds.
+	Settings          api.CreateMeeting `json:"settings"`                      // Request that was used to create the meeting.
+	CreatedAt         time.Time         `json:"created_at"`
+	StartedAt         *time.Time        `json:"started_at,omitempty"` // When the first user joined.
+	EndedAt           *time.Time        `json:"ended_at,omitempty"`
+	Participants      int               `json:"participants"`         // Number of users that are currently in the meeting.
+	ParticipantPeak   int               `json:"participant_peak"`     // Highest number of users at the same time.
+	Recording         bool              `json:"recording"`            // Whether the meeting is being recorded right now.
+	RecordIds         []string          `json:"record_ids,omitempty"` // Recordings that are ready to be played.
+}
+
+// Ended return whether the meeting has ended.
+func (m *Meeting) Ended() bool {
+	return m.EndedAt != nil
+}
+
+// Assigned return whether the user with the given ID could join as attendee. Every user could
+// join a meeting that is not a breakout room, a free join breakout room, or a breakout room that
+// has no assigned users.
+func (m *Meeting) Assigned(userId string) bool {
+	if m.ParentId == "" || m.Settings.FreeJoin || len(m.AssignedUsers) == 0 {
+		return true
+	}
+
+	for _, id := range m.AssignedUsers {
+		if id == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// Running return whether somebody has joined the meeting and it hasn't ended.
+func (m *Meeting) Running() bool {
+	return m.StartedAt != nil && m.EndedAt == nil
+}
+
+// Duration return how long the meeting took place, from the first user joined until
+// it ended, or until now if it's still running. Zero if nobody has joined.
+func (m *Meeting) Duration(now time.Time) time.Duration {
+	if m.StartedAt == nil {
+		return 0
+	}
+	if m.EndedAt != nil {
+		now = *m.EndedAt
+	}
+
+	return now.Sub(*m.StartedAt)
+}
+
+// MeetingKey return key of the meeting record with the given meeting ID and create time. The
+// create time is zero padded so records of the same meeting ID are ordered by their time.
+func MeetingKey(meetingId string, createTime int64) string {
+	return fmt.Sprintf("%s/%013d", meetingId, createTime)
+}
+
+// SaveMeeting save a newly created meeting. If the latest record of the meeting ID
+// hasn't ended, this is the same meeting, so what has been recorded about it (e.g.
+// from webhook events that arrived earlier) is kept and filled with the given one.
+func (s *Store) SaveMeeting(m Meeting) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, err := s.meeting(m.MeetingId)
+	switch {
+	case err == ErrNotFound:
+	case err != nil:
+		return err
+	case !prev.Ended():
+		m.Key = prev.Key
+		m.StartedAt = prev.StartedAt
+		m.Participants, m.ParticipantPeak = prev.Participants, prev.ParticipantPeak
+		m.Recording, m.RecordIds = prev.Recording, prev.RecordIds
+		if m.InternalMeetingId == "" {
+			m.InternalMeetingId = prev.InternalMeetingId
+		}
+		if m.Client == "" {
+			m.Client = prev.Client
+		}
+		if m.SeriesId == "" {
+			m.SeriesId = prev.SeriesId
+		}
+		if m.ParentId == "" {
+			m.ParentId, m.AssignedUsers = prev.ParentId, prev.AssignedUsers
+		}
+	}
+
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	if m.Key == "" {
+		createTime := m.CreateTime
+		if createTime == 0 {
+			createTime = m.CreatedAt.UnixNano() / int64(time.Millisecond)
+		}
+		m.Key = MeetingKey(m.MeetingId, createTime)
+	}
+
+	if err := s.put(meetingBucket, m.Key, &m); err != nil {
+		return fmt.Errorf("failed to save meeting: %s", err)
+	}
+
+	if err := s.db.Put(meetingIdBucket, m.MeetingId, []byte(m.Key)); err != nil {
+		return fmt.Errorf("failed to save meeting ID: %s", err)
+	}
+
+	return nil
+}
+
+// Meeting return the latest record of the given meeting ID.
+func (s *Store) Meeting(meetingId string) (Meeting, error) {
+	return s.meeting(meetingId)
+}
+
+// MeetingByKey return the meeting record of the given key.
+func (s *Store) MeetingByKey(key string) (m Meeting, err error) {
+	err = s.get(meetingBucket, key, &m)
+	return
+}
+
+// UpdateMeeting call fn to modify the latest record of the given meeting ID then save
+// it. Nothing would be saved if fn return error.
+func (s *Store) UpdateMeeting(meetingId string, fn func(m *Meeting) error) error {
+	key, err := s.db.Get(meetingIdBucket, meetingId)
+	if err != nil {
+		return err
+	}
+
+	var m Meeting
+	return s.update(meetingBucket, string(key), &m, func() error {
+		return fn(&m)
+	})
+}
+
+// EachMeeting call fn with every meeting record in order of their key. Stop
+// iterating when fn return error.
+func (s *Store) EachMeeting(fn func(m Meeting) error) error {
+	var m Meeting
+	return s.each(meetingBucket, &m, func() error {
+		cur := m
+		m = Meeting{}
+		return fn(cur)
+	})
+}
+
+// MeetingOwner return name of the client that created the latest meeting with the
+// given meeting ID, or empty if it's unknown.
+func (s *Store) MeetingOwner(meetingId string) string {
+	m, err := s.meeting(meetingId)
+	if err != nil {
+		return ""
+	}
+
+	return m.Client
+}
+
+// meeting return the latest record of the given meeting ID without locking.
+func (s *Store) meeting(meetingId string) (m Meeting, err error) {
+	key, err := s.db.Get(meetingIdBucket, meetingId)
+	if err != nil {
+		return
+	}
+
+	err = s.get(meetingBucket, string(key), &m)
+	return
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SaveMeeting(t *testing.T) {
+	st := New(NewMemory())
+
+	t.Run("New meeting should be saved with its key", func(t *testing.T) {
+		require.NoError(t, st.SaveMeeting(Meeting{MeetingId: "meet01", Name: "Class", Client: "lms", CreateTime: 1000}))
+
+		m, err := st.Meeting("meet01")
+		require.NoError(t, err)
+		assert.Equal(t, MeetingKey("meet01", 1000), m.Key)
+		assert.Equal(t, "Class", m.Name)
+		assert.Equal(t, "lms", st.MeetingOwner("meet01"))
+		assert.False(t, m.CreatedAt.IsZero())
+	})
+
+	t.Run("Saving meeting that hasn't ended should keep what has been recorded", func(t *testing.T) {
+		require.NoError(t, st.UpdateMeeting("meet01", func(m *Meeting) error {
+			m.Participants, m.ParticipantPeak = 2, 3
+			return nil
+		}))
+		require.NoError(t, st.SaveMeeting(Meeting{MeetingId: "meet01", Name: "Class", CreateTime: 1000}))
+
+		m, err := st.Meeting("meet01")
+		require.NoError(t, err)
+		assert.Equal(t, 3, m.ParticipantPeak)
+		assert.Equal(t, "lms", m.Client)
+	})
+
+	t.Run("Reusing meeting ID after it ended should be saved as new record", func(t *testing.T) {
+		require.NoError(t, st.UpdateMeeting("meet01", func(m *Meeting) error {
+			now := time.Now()
+			m.EndedAt = &now
+			return nil
+		}))
+		require.NoError(t, st.SaveMeeting(Meeting{MeetingId: "meet01", Name: "Class 2", CreateTime: 2000}))
+
+		m, err := st.Meeting("meet01")
+		require.NoError(t, err)
+		assert.Equal(t, "Class 2", m.Name)
+		assert.Zero(t, m.ParticipantPeak)
+
+		var names []string
+		require.NoError(t, st.EachMeeting(func(m Meeting) error {
+			names = append(names, m.Name)
+			return nil
+		}))
+		assert.Equal(t, []string{"Class", "Class 2"}, names)
+	})
+}
+
+func TestStore_Meeting(t *testing.T) {
+	st := New(NewMemory())
+
+	testCases := []struct {
+		name      string
+		meetingId string
+		expectErr error
+	}{
+		{
+			name:      "Unknown meeting should return ErrNotFound",
+			meetingId: "unknown",
+			expectErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := st.Meeting(tt.meetingId)
+			assert.Equal(t, tt.expectErr, err)
+			assert.Equal(t, tt.expectErr, st.UpdateMeeting(tt.meetingId, func(*Meeting) error { return nil }))
+			assert.Empty(t, st.MeetingOwner(tt.meetingId))
+		})
+	}
+}
+
+func TestMeeting_Assigned(t *testing.T) {
+	testCases := []struct {
+		name    string
+		meeting Meeting
+		userId  string
+		expect  bool
+	}{
+		{name: "Meeting that is not breakout room", meeting: Meeting{}, userId: "mhs 01", expect: true},
+		{name: "Assigned user", meeting: Meeting{ParentId: "meet01", AssignedUsers: []string{"mhs 01"}}, userId: "mhs 01", expect: true},
+		{name: "User of other room", meeting: Meeting{ParentId: "meet01", AssignedUsers: []string{"mhs 01"}}, userId: "mhs 02", expect: false},
+		{name: "Free join room", meeting: Meeting{ParentId: "meet01", AssignedUsers: []string{"mhs 01"}, Settings: api.CreateMeeting{FreeJoin: true}}, userId: "mhs 02", expect: true},
+		{name: "Room w/o assigned users", meeting: Meeting{ParentId: "meet01"}, userId: "mhs 02", expect: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.meeting.Assigned(tt.userId))
+		})
+	}
+}
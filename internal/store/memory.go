@@ -0,0 +1,81 @@
+package store
+
+import (
+	"sort"
+	"sync"
+)
+
+// Memory Backend that keep every value in memory. Mostly used in tests.
+type Memory struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+}
+
+// NewMemory return new empty in-memory backend.
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]map[string][]byte)}
+}
+
+// Get return value of the given key in the bucket.
+func (m *Memory) Get(bucket, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	val, ok := m.buckets[bucket][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return append([]byte(nil), val...), nil
+}
+
+// Put save the value of the given key in the bucket.
+func (m *Memory) Put(bucket, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.buckets[bucket] == nil {
+		m.buckets[bucket] = make(map[string][]byte)
+	}
+	m.buckets[bucket][key] = append([]byte(nil), value...)
+
+	return nil
+}
+
+// Delete remove the given key from the bucket.
+func (m *Memory) Delete(bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.buckets[bucket], key)
+
+	return nil
+}
+
+// ForEach call fn with every key and value in the bucket in order of the keys.
+func (m *Memory) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	m.mu.RLock()
+	keys := make([]string, 0, len(m.buckets[bucket]))
+	for k := range m.buckets[bucket] {
+		keys = append(keys, k)
+	}
+	m.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		val, err := m.Get(bucket, k)
+		if err == ErrNotFound {
+			continue
+		}
+		if err := fn(k, val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close do nothing.
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+)
+
+const scheduleBucket = "schedules" // Every schedule keyed by Schedule.Id.
+
+// Schedule a meeting that is planned ahead of time. The meeting is only created in BBB
+// server when the first moderator joins within its window.
+type Schedule struct {
+	Id           string            `json:"id"`
+	MeetingId    string            `json:"meeting_id"`          // Meeting ID that would be used when creating the meeting.
+	SeriesId     string            `json:"series_id,omitempty"` // Series that the schedule is an occurrence of.
+	Date         string            `json:"date,omitempty"`      // Date of the occurrence of the series as YYYYMMDD.
+	Client       string            `json:"client,omitempty"`
+	StartAt      time.Time         `json:"start_at"`      // Users could join since this time.
+	EndAt        time.Time         `json:"end_at"`        // Users could join until this time.
+	Settings     api.CreateMeeting `json:"settings"`      // Request that would be used to create the meeting.
+	ModeratorKey string            `json:"moderator_key"` // Secret in join link of moderators.
+	AttendeeKey  string            `json:"attendee_key"`  // Secret in join link of attendees.
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// Window return whether users could join the scheduled meeting at the given time, and the
+// reason if they couldn't.
+func (s *Schedule) Window(at time.Time) (open bool, reason string) {
+	switch {
+	case at.Before(s.StartAt):
+		return false, fmt.Sprintf("meeting is not open yet, it opens at %s", s.StartAt.Format(time.RFC3339))
+	case !at.Before(s.EndAt):
+		return false, fmt.Sprintf("meeting is already closed at %s", s.EndAt.Format(time.RFC3339))
+	}
+
+	return true, ""
+}
+
+// SaveSchedule save the given schedule, replacing the one with the same ID if any.
+func (s *Store) SaveSchedule(sc Schedule) error {
+	if sc.CreatedAt.IsZero() {
+		sc.CreatedAt = time.Now()
+	}
+
+	if err := s.put(scheduleBucket, sc.Id, &sc); err != nil {
+		return fmt.Errorf("failed to save schedule: %s", err)
+	}
+
+	return nil
+}
+
+// Schedule return the schedule with the given ID.
+func (s *Store) Schedule(id string) (sc Schedule, err error) {
+	err = s.get(scheduleBucket, id, &sc)
+	return
+}
+
+// DeleteSchedule remove the schedule with the given ID.
+func (s *Store) DeleteSchedule(id string) error {
+	return s.db.Delete(scheduleBucket, id)
+}
+
+// EachSchedule call fn with every schedule in order of their ID. Stop iterating when fn
+// return error.
+func (s *Store) EachSchedule(fn func(sc Schedule) error) error {
+	var sc Schedule
+	return s.each(scheduleBucket, &sc, func() error {
+		cur := sc
+		sc = Schedule{}
+		return fn(cur)
+	})
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Schedule(t *testing.T) {
+	st := New(NewMemory())
+
+	t.Run("Saved schedule should be found", func(t *testing.T) {
+		require.NoError(t, st.SaveSchedule(Schedule{Id: "sch02", MeetingId: "meet02"}))
+		require.NoError(t, st.SaveSchedule(Schedule{Id: "sch01", MeetingId: "meet01"}))
+
+		sc, err := st.Schedule("sch01")
+		require.NoError(t, err)
+		assert.Equal(t, "meet01", sc.MeetingId)
+		assert.False(t, sc.CreatedAt.IsZero())
+
+		var ids []string
+		require.NoError(t, st.EachSchedule(func(sc Schedule) error {
+			ids = append(ids, sc.Id)
+			return nil
+		}))
+		assert.Equal(t, []string{"sch01", "sch02"}, ids)
+	})
+
+	t.Run("Deleted schedule should not be found", func(t *testing.T) {
+		require.NoError(t, st.DeleteSchedule("sch01"))
+		_, err := st.Schedule("sch01")
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
+
+func TestSchedule_Window(t *testing.T) {
+	sc := Schedule{StartAt: time.Unix(1000, 0), EndAt: time.Unix(2000, 0)}
+
+	testCases := []struct {
+		name   string
+		at     time.Time
+		expect bool
+	}{
+		{name: "Before start should be closed", at: time.Unix(999, 0), expect: false},
+		{name: "At start should be open", at: time.Unix(1000, 0), expect: true},
+		{name: "At end should be closed", at: time.Unix(2000, 0), expect: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			open, reason := sc.Window(tt.at)
+			assert.Equal(t, tt.expect, open)
+			assert.Equal(t, tt.expect, reason == "")
+		})
+	}
+}
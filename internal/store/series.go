@@ -0,0 +1,394 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+)
+
+const (
+	seriesBucket = "series" // Every series keyed by Series.Id.
+
+	// seriesDateFormat format of occurrence date in meeting ID and exceptions.
+	seriesDateFormat = "20060102"
+	// maxOccurrences maximum number of occurrences of a series.
+	maxOccurrences = 1000
+)
+
+// Frequencies of a series.
+const (
+	Daily  = "daily"
+	Weekly = "weekly"
+)
+
+// weekdays weekday of every two-letter day in recurrence rule.
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Recurrence rule of a series, similar to RRULE of iCalendar.
+type Recurrence struct {
+	Freq     string    `json:"freq"`               // Either daily or weekly.
+	Interval int       `json:"interval,omitempty"` // Every how many days or weeks. Default to 1.
+	ByDay    []string  `json:"by_day,omitempty"`   // Days of weekly series such as MO, WE. Default to the day of the first occurrence.
+	Until    time.Time `json:"until,omitempty"`    // No occurrence starts after this time. Optional.
+	Count    int       `json:"count,omitempty"`    // Number of occurrences, including the exceptions. Optional.
+}
+
+// Series meeting that is held repeatedly by recurrence rule. Every occurrence is scheduled
+// as its own meeting with ID derived from the series ID and the occurrence date.
+type Series struct {
+	Id           string            `json:"id"`
+	Client       string            `json:"client,omitempty"`
+	StartAt      time.Time         `json:"start_at"`           // Start of the first occurrence.
+	Timezone     string            `json:"timezone,omitempty"` // IANA time zone of the occurrences. Default to the offset of StartAt.
+	Duration     time.Duration     `json:"duration"`           // How long every occurrence could be joined.
+	Recurrence   Recurrence        `json:"recurrence"`
+	Exceptions   []string          `json:"exceptions,omitempty"` // Dates (YYYYMMDD) that are skipped.
+	Settings     api.CreateMeeting `json:"settings"`             // Request that would be used to create every occurrence.
+	ModeratorKey string            `json:"moderator_key"`        // Secret in join link of moderators.
+	AttendeeKey  string            `json:"attendee_key"`         // Secret in join link of attendees.
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// Occurrence a time the series is held.
+type Occurrence struct {
+	Date    string    `json:"date"` // Date of the occurrence as YYYYMMDD.
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+// MeetingId return meeting ID of the occurrence of the series.
+func (sr *Series) MeetingId(o Occurrence) string {
+	return fmt.Sprintf("%s-%s", sr.Id, o.Date)
+}
+
+// Schedule return the schedule of the occurrence of the series. Every occurrence shares the
+// settings and the join keys of the series.
+func (sr *Series) Schedule(o Occurrence) Schedule {
+	settings := sr.Settings
+	settings.MeetingId = sr.MeetingId(o)
+
+	return Schedule{
+		Id:           settings.MeetingId,
+		MeetingId:    settings.MeetingId,
+		SeriesId:     sr.Id,
+		Date:         o.Date,
+		Client:       sr.Client,
+		StartAt:      o.StartAt,
+		EndAt:        o.EndAt,
+		Settings:     settings,
+		ModeratorKey: sr.ModeratorKey,
+		AttendeeKey:  sr.AttendeeKey,
+	}
+}
+
+// Validate check whether the series and its recurrence rule are valid, then fill the
+// default values.
+func (sr *Series) Validate() error {
+	switch {
+	case sr.StartAt.IsZero():
+		return fmt.Errorf("`start_at` is required")
+	case sr.Duration <= 0:
+		return fmt.Errorf("`duration` must be greater than zero")
+	case sr.Recurrence.Freq != Daily && sr.Recurrence.Freq != Weekly:
+		return fmt.Errorf("`freq` must be either %s or %s", Daily, Weekly)
+	case sr.Recurrence.Interval < 0:
+		return fmt.Errorf("`interval` must not be negative")
+	case sr.Recurrence.Count < 0 || sr.Recurrence.Count > maxOccurrences:
+		return fmt.Errorf("`count` must be between 0 and %d", maxOccurrences)
+	case !sr.Recurrence.Until.IsZero() && sr.Recurrence.Until.Before(sr.StartAt):
+		return fmt.Errorf("`until` must not be before `start_at`")
+	}
+
+	loc, err := sr.Location()
+	if err != nil {
+		return err
+	}
+	sr.StartAt = sr.StartAt.In(loc)
+
+	if sr.Recurrence.Interval == 0 {
+		sr.Recurrence.Interval = 1
+	}
+
+	if sr.Recurrence.Freq == Weekly && len(sr.Recurrence.ByDay) == 0 {
+		for day, wd := range weekdays {
+			if wd == sr.StartAt.Weekday() {
+				sr.Recurrence.ByDay = []string{day}
+			}
+		}
+	}
+	for i, day := range sr.Recurrence.ByDay {
+		day = strings.ToUpper(day)
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("`by_day` must be two-letter days such as MO, TU, WE")
+		}
+		sr.Recurrence.ByDay[i] = day
+	}
+
+	for _, date := range sr.Exceptions {
+		if _, err := time.Parse(seriesDateFormat, date); err != nil {
+			return fmt.Errorf("`exceptions` must be dates formatted as YYYYMMDD")
+		}
+	}
+
+	return nil
+}
+
+// Excepted return whether the occurrence at the given date is skipped.
+func (sr *Series) Excepted(date string) bool {
+	for _, ex := range sr.Exceptions {
+		if ex == date {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Occurrences return every occurrence of the series that ends after the given time and
+// starts before the other, in order. Exceptions are not included.
+func (sr *Series) Occurrences(from, to time.Time) []Occurrence {
+	loc, err := sr.Location()
+	if err != nil {
+		return nil
+	}
+
+	start := sr.StartAt.In(loc)
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	// monday of the week of the first occurrence to count weeks of weekly series.
+	firstWeek := first.AddDate(0, 0, -(int(first.Weekday())+6)%7)
+
+	interval := sr.Recurrence.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	// occurrences that have ended long before `from` are counted w/o going through every day.
+	count, days := sr.skipTo(first, firstWeek, interval, from)
+
+	var out []Occurrence
+	for ; ; days++ {
+		days = sr.nextDay(days, first, firstWeek, interval)
+		day := first.AddDate(0, 0, days)
+		o := Occurrence{
+			Date:    day.Format(seriesDateFormat),
+			StartAt: time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc),
+		}
+		o.EndAt = o.StartAt.Add(sr.Duration)
+
+		if !o.StartAt.Before(to) || (!sr.Recurrence.Until.IsZero() && o.StartAt.After(sr.Recurrence.Until)) {
+			break
+		}
+		if !sr.matchDay(day, first, firstWeek, interval) {
+			continue
+		}
+
+		count++
+		if (sr.Recurrence.Count > 0 && count > sr.Recurrence.Count) || count > maxOccurrences {
+			break
+		}
+		if o.EndAt.After(from) && !sr.Excepted(o.Date) {
+			out = append(out, o)
+		}
+	}
+
+	return out
+}
+
+// skipTo return the number of occurrences that are held before the returned day, counted in
+// days from the first occurrence, which is a few days before the given time. Occurrences before
+// the returned day have ended before the given time.
+func (sr *Series) skipTo(first, firstWeek time.Time, interval int, from time.Time) (count, days int) {
+	// occurrence that start a few days before could still be running.
+	days = daysBetween(first, from) - int(sr.Duration/(24*time.Hour)) - 1
+	if days <= 0 {
+		return 0, 0
+	}
+
+	if sr.Recurrence.Freq == Daily {
+		return (days-1)/interval + 1, days
+	}
+
+	// skip whole weeks, the first week is partial as it starts at the first occurrence.
+	offset := daysBetween(firstWeek, first)
+	weeks := (days + offset) / 7
+	if weeks <= 0 {
+		return 0, 0
+	}
+
+	held := make(map[time.Weekday]bool)
+	for _, d := range sr.Recurrence.ByDay {
+		held[weekdays[d]] = true
+	}
+	for wd := range held {
+		if (int(wd)+6)%7 >= offset {
+			count++
+		}
+	}
+	count += (weeks - 1) / interval * len(held)
+
+	return count, weeks*7 - offset
+}
+
+// nextDay return the given day, counted in days from the first occurrence, or the first day of
+// the next week the series is held at if the series isn't held at the week of the given day.
+// Daily series return the next day it's held at.
+func (sr *Series) nextDay(days int, first, firstWeek time.Time, interval int) int {
+	if sr.Recurrence.Freq == Daily {
+		return (days + interval - 1) / interval * interval
+	}
+
+	offset := daysBetween(firstWeek, first)
+	week := (days + offset) / 7
+	if week%interval == 0 {
+		return days
+	}
+
+	return (week/interval+1)*interval*7 - offset
+}
+
+// matchDay return whether the series is held at the given day.
+func (sr *Series) matchDay(day, first, firstWeek time.Time, interval int) bool {
+	if sr.Recurrence.Freq == Daily {
+		return daysBetween(first, day)%interval == 0
+	}
+
+	if (daysBetween(firstWeek, day)/7)%interval != 0 {
+		return false
+	}
+	for _, d := range sr.Recurrence.ByDay {
+		if weekdays[d] == day.Weekday() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Location return time zone of the occurrences. Without Timezone, it's the fixed offset of
+// StartAt so the occurrences don't depend on the system's local time.
+func (sr *Series) Location() (*time.Location, error) {
+	if sr.Timezone == "" {
+		_, offset := sr.StartAt.Zone()
+		return time.FixedZone("", offset), nil
+	}
+
+	loc, err := time.LoadLocation(sr.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("`timezone` is unknown: %s", err)
+	}
+
+	return loc, nil
+}
+
+// daysBetween return number of calendar days from a to b.
+func daysBetween(a, b time.Time) int {
+	ad := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	bd := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(bd.Sub(ad).Hours() / 24)
+}
+
+// SaveSeries save the given series, replacing the one with the same ID if any.
+func (s *Store) SaveSeries(sr Series) error {
+	if sr.CreatedAt.IsZero() {
+		sr.CreatedAt = time.Now()
+	}
+
+	if err := s.put(seriesBucket, sr.Id, &sr); err != nil {
+		return fmt.Errorf("failed to save series: %s", err)
+	}
+
+	return nil
+}
+
+// Series return the series with the given ID.
+func (s *Store) Series(id string) (sr Series, err error) {
+	err = s.get(seriesBucket, id, &sr)
+	return
+}
+
+// UpdateSeries call fn to modify the series with the given ID then save it. Nothing would be
+// saved if fn return error.
+func (s *Store) UpdateSeries(id string, fn func(sr *Series) error) error {
+	var sr Series
+	return s.update(seriesBucket, id, &sr, func() error {
+		return fn(&sr)
+	})
+}
+
+// DeleteSeries remove the series with the given ID.
+func (s *Store) DeleteSeries(id string) error {
+	return s.db.Delete(seriesBucket, id)
+}
+
+// EachSeries call fn with every series in order of their ID. Stop iterating when fn
+// return error.
+func (s *Store) EachSeries(fn func(sr Series) error) error {
+	var sr Series
+	return s.each(seriesBucket, &sr, func() error {
+		cur := sr
+		sr = Series{}
+		return fn(cur)
+	})
+}
+
+// ScheduleSeries save schedule of every occurrence of the series between the given times that
+// hasn't been scheduled. Occurrences that were already scheduled are left as they are.
+func (s *Store) ScheduleSeries(sr Series, from, to time.Time) error {
+	for _, o := range sr.Occurrences(from, to) {
+		_, err := s.Schedule(sr.MeetingId(o))
+		if err == nil {
+			continue
+		}
+		if err != ErrNotFound {
+			return fmt.Errorf("failed to get schedule: %s", err)
+		}
+
+		if err := s.SaveSchedule(sr.Schedule(o)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunSeries schedule the occurrences of every series that start within the horizon, then
+// repeat it every interval until stop is closed.
+func (s *Store) RunSeries(horizon, interval time.Duration, stop <-chan struct{}, onErr func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// collect every series first, schedules could not be saved while iterating.
+		var all []Series
+		if err := s.EachSeries(func(sr Series) error {
+			all = append(all, sr)
+			return nil
+		}); err != nil {
+			onErr(fmt.Errorf("failed to read series: %s", err))
+		}
+
+		now := time.Now()
+		for _, sr := range all {
+			if err := s.ScheduleSeries(sr, now, now.Add(horizon)); err != nil {
+				onErr(fmt.Errorf("failed to schedule series %s: %s", sr.Id, err))
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeries_Occurrences(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*60*60)
+	// monday 10:00.
+	start := time.Date(2022, 3, 7, 10, 0, 0, 0, loc)
+	from, to := start.AddDate(0, 0, -1), start.AddDate(0, 0, 21)
+
+	testCases := []struct {
+		name   string
+		sample Series
+		expect []string
+	}{
+		{
+			name:   "Daily series should be held every day",
+			sample: Series{Recurrence: Recurrence{Freq: Daily, Count: 3}},
+			expect: []string{"20220307", "20220308", "20220309"},
+		},
+		{
+			name:   "Daily series w interval should skip days",
+			sample: Series{Recurrence: Recurrence{Freq: Daily, Interval: 2, Until: start.AddDate(0, 0, 5)}},
+			expect: []string{"20220307", "20220309", "20220311"},
+		},
+		{
+			name:   "Weekly series w/o days should be held at the day of the first occurrence",
+			sample: Series{Recurrence: Recurrence{Freq: Weekly}},
+			expect: []string{"20220307", "20220314", "20220321"},
+		},
+		{
+			name:   "Weekly series should be held at the given days",
+			sample: Series{Recurrence: Recurrence{Freq: Weekly, ByDay: []string{"mo", "we"}, Count: 4}},
+			expect: []string{"20220307", "20220309", "20220314", "20220316"},
+		},
+		{
+			name:   "Biweekly series should skip weeks",
+			sample: Series{Recurrence: Recurrence{Freq: Weekly, Interval: 2, ByDay: []string{"MO", "FR"}}},
+			expect: []string{"20220307", "20220311", "20220321", "20220325"},
+		},
+		{
+			name:   "Exception should be skipped but counted",
+			sample: Series{Recurrence: Recurrence{Freq: Daily, Count: 3}, Exceptions: []string{"20220308"}},
+			expect: []string{"20220307", "20220309"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.sample.StartAt = start
+			tt.sample.Duration = time.Hour
+			require.NoError(t, tt.sample.Validate())
+
+			var dates []string
+			for _, o := range tt.sample.Occurrences(from, to) {
+				dates = append(dates, o.Date)
+				assert.Equal(t, 10, o.StartAt.Hour())
+				assert.Equal(t, time.Hour, o.EndAt.Sub(o.StartAt))
+			}
+			assert.Equal(t, tt.expect, dates)
+		})
+	}
+
+	t.Run("Occurrence that has ended should not be returned", func(t *testing.T) {
+		sr := Series{StartAt: start, Duration: time.Hour, Recurrence: Recurrence{Freq: Daily}}
+		require.NoError(t, sr.Validate())
+		occ := sr.Occurrences(start.Add(time.Hour), start.AddDate(0, 0, 2))
+		require.Len(t, occ, 1)
+		assert.Equal(t, "20220308", occ[0].Date)
+	})
+}
+
+func TestSeries_OccurrencesSkip(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*60*60)
+	// thursday 22:00, so occurrences of two hours end on the next day.
+	start := time.Date(2020, 1, 2, 22, 0, 0, 0, loc)
+	from, to := time.Date(2022, 3, 9, 23, 0, 0, 0, loc), time.Date(2022, 5, 1, 0, 0, 0, 0, loc)
+
+	testCases := []struct {
+		name       string
+		recurrence Recurrence
+		duration   time.Duration
+	}{
+		{name: "Daily series", recurrence: Recurrence{Freq: Daily}, duration: 2 * time.Hour},
+		{name: "Daily series w interval", recurrence: Recurrence{Freq: Daily, Interval: 3}, duration: time.Hour},
+		{name: "Daily series that last for days", recurrence: Recurrence{Freq: Daily, Interval: 5}, duration: 50 * time.Hour},
+		{name: "Weekly series", recurrence: Recurrence{Freq: Weekly, ByDay: []string{"MO", "WE", "TH", "FR"}}, duration: 2 * time.Hour},
+		{name: "Weekly series w interval", recurrence: Recurrence{Freq: Weekly, Interval: 3, ByDay: []string{"SU", "TU", "TH"}}, duration: time.Hour},
+		{name: "Weekly series w count that ends in the window", recurrence: Recurrence{Freq: Weekly, ByDay: []string{"TU", "TH", "TH"}, Count: 230}, duration: time.Hour},
+		{name: "Daily series w count that ended before the window", recurrence: Recurrence{Freq: Daily, Count: 100}, duration: time.Hour},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := Series{StartAt: start, Duration: tt.duration, Recurrence: tt.recurrence}
+			require.NoError(t, sr.Validate())
+
+			// occurrences from the start are found day by day, as there is nothing to skip.
+			var expect []Occurrence
+			for _, o := range sr.Occurrences(start, to) {
+				if o.EndAt.After(from) {
+					expect = append(expect, o)
+				}
+			}
+			assert.Equal(t, expect, sr.Occurrences(from, to))
+		})
+	}
+
+	t.Run("Series that started long ago should not be iterated day by day", func(t *testing.T) {
+		sr := Series{StartAt: time.Date(1, 1, 1, 10, 0, 0, 0, loc), Duration: time.Hour, Recurrence: Recurrence{Freq: Weekly, Interval: 1 << 20}}
+		require.NoError(t, sr.Validate())
+
+		begin := time.Now()
+		assert.Empty(t, sr.Occurrences(from, to))
+		assert.Less(t, time.Since(begin), time.Second)
+	})
+}
+
+func TestSeries_Validate(t *testing.T) {
+	start := time.Date(2022, 3, 7, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name   string
+		sample Series
+	}{
+		{name: "Series w/o start should be invalid", sample: Series{Duration: time.Hour, Recurrence: Recurrence{Freq: Daily}}},
+		{name: "Series w/o duration should be invalid", sample: Series{StartAt: start, Recurrence: Recurrence{Freq: Daily}}},
+		{name: "Series w unknown frequency should be invalid", sample: Series{StartAt: start, Duration: time.Hour, Recurrence: Recurrence{Freq: "monthly"}}},
+		{name: "Series w unknown day should be invalid", sample: Series{StartAt: start, Duration: time.Hour, Recurrence: Recurrence{Freq: Weekly, ByDay: []string{"XX"}}}},
+		{name: "Series that end before start should be invalid", sample: Series{StartAt: start, Duration: time.Hour, Recurrence: Recurrence{Freq: Daily, Until: start.Add(-time.Hour)}}},
+		{name: "Series w invalid exception should be invalid", sample: Series{StartAt: start, Duration: time.Hour, Recurrence: Recurrence{Freq: Daily}, Exceptions: []string{"2022-03-08"}}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.sample.Validate())
+		})
+	}
+}
+
+func TestStore_ScheduleSeries(t *testing.T) {
+	st := New(NewMemory())
+	start := time.Date(2022, 3, 7, 10, 0, 0, 0, time.UTC)
+	sr := Series{
+		Id:           "math",
+		Client:       "lms",
+		StartAt:      start,
+		Duration:     time.Hour,
+		Recurrence:   Recurrence{Freq: Weekly},
+		ModeratorKey: "mdr",
+		AttendeeKey:  "att",
+	}
+	sr.Settings.Name = "Math"
+	require.NoError(t, sr.Validate())
+	require.NoError(t, st.SaveSeries(sr))
+
+	t.Run("Upcoming occurrences should be scheduled w stable meeting ID", func(t *testing.T) {
+		require.NoError(t, st.ScheduleSeries(sr, start, start.AddDate(0, 0, 14)))
+
+		var ids []string
+		require.NoError(t, st.EachSchedule(func(sc Schedule) error {
+			ids = append(ids, sc.MeetingId)
+			assert.Equal(t, sc.Id, sc.MeetingId)
+			assert.Equal(t, sc.MeetingId, sc.Settings.MeetingId)
+			assert.Equal(t, "math", sc.SeriesId)
+			assert.Equal(t, "att", sc.AttendeeKey)
+			return nil
+		}))
+		assert.Equal(t, []string{"math-20220307", "math-20220314"}, ids)
+	})
+
+	t.Run("Scheduled occurrence should be left as it is", func(t *testing.T) {
+		sc, err := st.Schedule("math-20220314")
+		require.NoError(t, err)
+		sc.Settings.Name = "Math Exam"
+		require.NoError(t, st.SaveSchedule(sc))
+
+		require.NoError(t, st.ScheduleSeries(sr, start, start.AddDate(0, 0, 21)))
+		sc, err = st.Schedule("math-20220314")
+		require.NoError(t, err)
+		assert.Equal(t, "Math Exam", sc.Settings.Name)
+		_, err = st.Schedule("math-20220321")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Updated series should be saved", func(t *testing.T) {
+		require.NoError(t, st.UpdateSeries("math", func(sr *Series) error {
+			sr.Exceptions = append(sr.Exceptions, "20220328")
+			return nil
+		}))
+		got, err := st.Series("math")
+		require.NoError(t, err)
+		assert.True(t, got.Excepted("20220328"))
+	})
+
+	t.Run("Deleted series should not be found", func(t *testing.T) {
+		require.NoError(t, st.DeleteSeries("math"))
+		_, err := st.Series("math")
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotFound returned when the wanted key doesn't exist in the bucket.
+var ErrNotFound = errors.New("not found")
+
+// Backend signatures of key-value storage that persist data of this app. Values
+// are grouped into buckets.
+type Backend interface {
+	Get(bucket, key string) ([]byte, error)                               // Return ErrNotFound if the key doesn't exist.
+	Put(bucket, key string, value []byte) error                           // Create the bucket if it doesn't exist.
+	Delete(bucket, key string) error                                      // No error if the key doesn't exist.
+	ForEach(bucket string, fn func(key string, value []byte) error) error // Iterate in order of the keys.
+	Close() error
+}
+
+// Store persist data of this app as json into the backend.
+type Store struct {
+	mu sync.Mutex // Serialize read-modify-write of values.
+	db Backend
+}
+
+// New return new Store that use the given backend.
+func New(db Backend) *Store {
+	return &Store{db: db}
+}
+
+// Close close the backend.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// get bind value of the given key to v.
+func (s *Store) get(bucket, key string, v interface{}) error {
+	val, err := s.db.Get(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(val, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s %s: %s", bucket, key, err)
+	}
+
+	return nil
+}
+
+// put save v as the value of the given key.
+func (s *Store) put(bucket, key string, v interface{}) error {
+	val, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s %s: %s", bucket, key, err)
+	}
+
+	return s.db.Put(bucket, key, val)
+}
+
+// update bind value of the given key to v, call fn to modify v, then save v back.
+// Nothing would be saved if fn return error.
+func (s *Store) update(bucket, key string, v interface{}, fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.get(bucket, key, v); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	return s.put(bucket, key, v)
+}
+
+// each call fn with every value in the bucket after bound to v, in order of the keys.
+func (s *Store) each(bucket string, v interface{}, fn func() error) error {
+	return s.db.ForEach(bucket, func(key string, val []byte) error {
+		if err := json.Unmarshal(val, v); err != nil {
+			return fmt.Errorf("failed to unmarshal %s %s: %s", bucket, key, err)
+		}
+
+		return fn()
+	})
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backends return every backend implementation to be tested against the same cases.
+var backends = func(t *testing.T) map[string]Backend {
+	b, err := NewBolt(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	return map[string]Backend{
+		"memory": NewMemory(),
+		"bolt":   b,
+	}
+}
+
+func TestBackend(t *testing.T) {
+	for name, db := range backends(t) {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			defer db.Close()
+
+			t.Run("Get from unknown bucket or key should return ErrNotFound", func(t *testing.T) {
+				_, err := db.Get("unknown", "key")
+				assert.Equal(t, ErrNotFound, err)
+
+				require.NoError(t, db.Put("bucket", "key01", []byte("val01")))
+				_, err = db.Get("bucket", "unknown")
+				assert.Equal(t, ErrNotFound, err)
+			})
+
+			t.Run("Put should replace the value of existing key", func(t *testing.T) {
+				require.NoError(t, db.Put("bucket", "key01", []byte("val02")))
+				val, err := db.Get("bucket", "key01")
+				require.NoError(t, err)
+				assert.Equal(t, []byte("val02"), val)
+			})
+
+			t.Run("ForEach should iterate in order of the keys", func(t *testing.T) {
+				require.NoError(t, db.Put("bucket", "key03", []byte("val03")))
+				require.NoError(t, db.Put("bucket", "key02", []byte("val02")))
+
+				var keys []string
+				require.NoError(t, db.ForEach("bucket", func(key string, _ []byte) error {
+					keys = append(keys, key)
+					return nil
+				}))
+				assert.Equal(t, []string{"key01", "key02", "key03"}, keys)
+			})
+
+			t.Run("Delete should remove the key and ignore unknown one", func(t *testing.T) {
+				require.NoError(t, db.Delete("bucket", "key01"))
+				require.NoError(t, db.Delete("unknown", "key01"))
+				_, err := db.Get("bucket", "key01")
+				assert.Equal(t, ErrNotFound, err)
+			})
+		})
+	}
+}
+
+func TestNewBolt_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := NewBolt(path)
+	require.NoError(t, err)
+	require.NoError(t, db.Put("bucket", "key01", []byte("val01")))
+	require.NoError(t, db.Close())
+
+	db, err = NewBolt(path)
+	require.NoError(t, err)
+	defer db.Close()
+	val, err := db.Get("bucket", "key01")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("val01"), val)
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/preset"
+)
+
+const templateBucket = "templates" // Templates that were defined through the API keyed by their name.
+
+// Template named settings of create meeting request.
+type Template struct {
+	Name      string          `json:"name"`
+	Settings  preset.Settings `json:"settings"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// SaveTemplate save the given template, replacing the one with the same name if any.
+func (s *Store) SaveTemplate(t Template) error {
+	t.UpdatedAt = time.Now()
+
+	if err := s.put(templateBucket, t.Name, &t); err != nil {
+		return fmt.Errorf("failed to save template: %s", err)
+	}
+
+	return nil
+}
+
+// Template return the template with the given name.
+func (s *Store) Template(name string) (t Template, err error) {
+	err = s.get(templateBucket, name, &t)
+	return
+}
+
+// DeleteTemplate remove the template with the given name.
+func (s *Store) DeleteTemplate(name string) error {
+	return s.db.Delete(templateBucket, name)
+}
+
+// EachTemplate call fn with every template in order of their name. Stop iterating when fn
+// return error.
+func (s *Store) EachTemplate(fn func(t Template) error) error {
+	var t Template
+	return s.each(templateBucket, &t, func() error {
+		cur := t
+		t = Template{}
+		return fn(cur)
+	})
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/preset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Template(t *testing.T) {
+	st := New(NewMemory())
+
+	t.Run("Saved template should be found", func(t *testing.T) {
+		require.NoError(t, st.SaveTemplate(Template{Name: "lecture", Settings: preset.Settings{"is_recording": true}}))
+		require.NoError(t, st.SaveTemplate(Template{Name: "exam", Settings: preset.Settings{"mute_on_start": true}}))
+
+		tmpl, err := st.Template("exam")
+		require.NoError(t, err)
+		assert.Equal(t, true, tmpl.Settings["mute_on_start"])
+		assert.False(t, tmpl.UpdatedAt.IsZero())
+
+		var names []string
+		require.NoError(t, st.EachTemplate(func(t Template) error {
+			names = append(names, t.Name)
+			return nil
+		}))
+		assert.Equal(t, []string{"exam", "lecture"}, names)
+	})
+
+	t.Run("Deleted template should not be found", func(t *testing.T) {
+		require.NoError(t, st.DeleteTemplate("exam"))
+		_, err := st.Template("exam")
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
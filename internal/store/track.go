@@ -0,0 +1,134 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/event"
+)
+
+// trackedEventTypes event types that change the lifecycle of a meeting record.
+var trackedEventTypes = []string{
+	event.MeetingCreated,
+	event.MeetingStarted,
+	event.MeetingEnded,
+	event.UserJoined,
+	event.UserLeft,
+	event.RecordingStarted,
+	event.RecordingStopped,
+	event.RecordingReady,
+}
+
+// Track subscribe to the bus and record the lifecycle of every meeting from its
+// events until the returned function is called or the bus is closed. Errors are
+// passed to onErr.
+func (s *Store) Track(bus *event.Bus, onErr func(error)) func() {
+	ch, unsubscribe := bus.Subscribe(256, event.ByTypes(trackedEventTypes...))
+
+	go func() {
+		for e := range ch {
+			if err := s.Record(e); err != nil {
+				onErr(fmt.Errorf("failed to record %s event of meeting %s: %s", e.Type, e.MeetingId, err))
+			}
+		}
+	}()
+
+	return unsubscribe
+}
+
+// Record update the meeting record of the given event. Meetings that were not
+// created through this app would be recorded since their created event.
+func (s *Store) Record(e event.Event) error {
+	if e.MeetingId == "" {
+		return nil
+	}
+
+	at := e.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	if e.Type == event.MeetingCreated {
+		m, err := s.Meeting(e.MeetingId)
+		if err == nil && !m.Ended() {
+			return nil
+		}
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+
+		return s.SaveMeeting(Meeting{
+			MeetingId:         e.MeetingId,
+			InternalMeetingId: e.InternalMeetingId,
+			Client:            e.Client,
+			CreatedAt:         at,
+		})
+	}
+
+	var key string
+	err := s.UpdateMeeting(e.MeetingId, func(m *Meeting) error {
+		key = m.Key
+		if m.InternalMeetingId == "" {
+			m.InternalMeetingId = e.InternalMeetingId
+		}
+
+		switch e.Type {
+		case event.MeetingStarted:
+			if m.StartedAt == nil {
+				m.StartedAt = &at
+			}
+		case event.MeetingEnded:
+			if m.EndedAt == nil {
+				m.EndedAt = &at
+			}
+			m.Participants, m.Recording = 0, false
+		case event.UserJoined:
+			m.Participants++
+			if m.Participants > m.ParticipantPeak {
+				m.ParticipantPeak = m.Participants
+			}
+		case event.UserLeft:
+			if m.Participants > 0 {
+				m.Participants--
+			}
+		case event.RecordingStarted:
+			m.Recording = true
+		case event.RecordingStopped:
+			m.Recording = false
+		case event.RecordingReady:
+			if e.RecordId != "" {
+				m.RecordIds = append(m.RecordIds, e.RecordId)
+			}
+		}
+
+		return nil
+	})
+	// events of meetings that are unknown to this app are ignored.
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case e.Type == event.UserJoined && e.User != nil:
+		return s.joinAttendance(key, attendeeId(e.User), e.User.Name, e.User.Role, at)
+	case e.Type == event.UserLeft && e.User != nil:
+		return s.leaveAttendance(key, attendeeId(e.User), at)
+	case e.Type == event.MeetingEnded:
+		return s.closeAttendances(key, at)
+	}
+
+	return nil
+}
+
+// attendeeId return ID to identify the user in attendance, which is the user ID that was
+// given when joining or the one generated by BBB server if none.
+func attendeeId(u *event.User) string {
+	if u.Id != "" {
+		return u.Id
+	}
+
+	return u.InternalId
+}
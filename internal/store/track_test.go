@@ -0,0 +1,97 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kurvaid/bbb-interface/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Record(t *testing.T) {
+	st := New(NewMemory())
+	require.NoError(t, st.SaveMeeting(Meeting{MeetingId: "meet01", Client: "lms", CreateTime: 1000}))
+
+	usr := func(id string) *event.User { return &event.User{Id: id} }
+	testCases := []struct {
+		name   string
+		sample []event.Event
+		expect func(t *testing.T, m Meeting)
+	}{
+		{
+			name: "Joined users should be counted along with their peak",
+			sample: []event.Event{
+				{Type: event.MeetingStarted, MeetingId: "meet01", InternalMeetingId: "int01"},
+				{Type: event.UserJoined, MeetingId: "meet01", User: usr("usr01")},
+				{Type: event.UserJoined, MeetingId: "meet01", User: usr("usr02")},
+				{Type: event.UserLeft, MeetingId: "meet01", User: usr("usr01")},
+			},
+			expect: func(t *testing.T, m Meeting) {
+				assert.NotNil(t, m.StartedAt)
+				assert.Equal(t, "int01", m.InternalMeetingId)
+				assert.Equal(t, 1, m.Participants)
+				assert.Equal(t, 2, m.ParticipantPeak)
+			},
+		},
+		{
+			name: "Ended meeting should have no participant and keep recorded recordings",
+			sample: []event.Event{
+				{Type: event.RecordingStarted, MeetingId: "meet01"},
+				{Type: event.MeetingEnded, MeetingId: "meet01"},
+				{Type: event.RecordingReady, MeetingId: "meet01", RecordId: "rec01"},
+			},
+			expect: func(t *testing.T, m Meeting) {
+				assert.True(t, m.Ended())
+				assert.False(t, m.Recording)
+				assert.Zero(t, m.Participants)
+				assert.Equal(t, []string{"rec01"}, m.RecordIds)
+			},
+		},
+		{
+			name: "Meeting that was not created through this app should be recorded since created",
+			sample: []event.Event{
+				{Type: event.MeetingCreated, MeetingId: "meet02", Timestamp: time.Unix(10, 0)},
+				{Type: event.UserJoined, MeetingId: "meet02", User: usr("usr01")},
+			},
+			expect: func(t *testing.T, m Meeting) {
+				m, err := st.Meeting("meet02")
+				require.NoError(t, err)
+				assert.Equal(t, time.Unix(10, 0).Unix(), m.CreatedAt.Unix())
+				assert.Equal(t, 1, m.Participants)
+			},
+		},
+		{
+			name:   "Event of unknown meeting should be ignored",
+			sample: []event.Event{{Type: event.UserJoined, MeetingId: "unknown"}},
+			expect: func(t *testing.T, m Meeting) {
+				_, err := st.Meeting("unknown")
+				assert.Equal(t, ErrNotFound, err)
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, e := range tt.sample {
+				require.NoError(t, st.Record(e))
+			}
+			m, err := st.Meeting("meet01")
+			require.NoError(t, err)
+			tt.expect(t, m)
+		})
+	}
+}
+
+func TestStore_Track(t *testing.T) {
+	st := New(NewMemory())
+	bus := event.NewBus()
+	defer bus.Close()
+	st.Track(bus, func(err error) { t.Error(err) })
+
+	bus.Publish(event.Event{Type: event.MeetingCreated, MeetingId: "meet01"})
+	assert.Eventually(t, func() bool {
+		_, err := st.Meeting("meet01")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/client"
+)
+
+// Register remove hooks that were left by the previous run of this app and
+// point to the given callback url, then register a new one to receive events
+// of all meetings. Return the id of the newly registered hook.
+func Register(hCl *http.Client, bbb api.Config, callbackUrl string) (string, error) {
+	var list api.HookListResponse
+	if err := client.Call(hCl, bbb, api.ParseHookList(), &list); err != nil {
+		return "", fmt.Errorf("failed to list registered hooks: %s", err)
+	}
+
+	for _, h := range list.Hooks {
+		if h.CallbackUrl != callbackUrl || h.PermanentHook {
+			continue
+		}
+
+		if err := Unregister(hCl, bbb, h.HookId); err != nil {
+			return "", err
+		}
+	}
+
+	hc := api.HookCreate{CallbackUrl: callbackUrl}
+	uri, err := hc.ParseHookCreate()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse create hook url: %s", err)
+	}
+
+	var res api.HookCreateResponse
+	if err := client.Call(hCl, bbb, uri, &res); err != nil {
+		return "", fmt.Errorf("failed to register hook: %s", err)
+	}
+
+	return res.HookId, nil
+}
+
+// Unregister remove the registered hook with the given id.
+func Unregister(hCl *http.Client, bbb api.Config, hookId string) error {
+	hd := api.HookDestroy{HookId: hookId}
+	uri, err := hd.ParseHookDestroy()
+	if err != nil {
+		return fmt.Errorf("failed to parse destroy hook url: %s", err)
+	}
+
+	if err := client.Call(hCl, bbb, uri, nil); err != nil {
+		return fmt.Errorf("failed to remove hook %s: %s", hookId, err)
+	}
+
+	return nil
+}
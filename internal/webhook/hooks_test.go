@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const callbackUrl = "http://this.app/webhooks/bbb"
+
+// prepare fake server to mimic BBB Server with bbb-webhooks installed.
+var fakeHooksServer = func(t *testing.T, destroyed *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, api.HooksList):
+			rw.Write([]byte(`<response><returncode>SUCCESS</returncode><hooks>
+<hook><hookID>1</hookID><callbackURL><![CDATA[` + callbackUrl + `]]></callbackURL><permanentHook>false</permanentHook></hook>
+<hook><hookID>2</hookID><callbackURL><![CDATA[http://other.app]]></callbackURL><permanentHook>false</permanentHook></hook>
+</hooks></response>`))
+		case strings.HasSuffix(req.URL.Path, api.HooksDestroy):
+			*destroyed = append(*destroyed, req.URL.Query().Get("hookID"))
+			rw.Write([]byte(`<response><returncode>SUCCESS</returncode><removed>true</removed></response>`))
+		case strings.HasSuffix(req.URL.Path, api.HooksCreate):
+			assert.Equal(t, callbackUrl, req.URL.Query().Get("callbackURL"))
+			rw.Write([]byte(`<response><returncode>SUCCESS</returncode><hookID>3</hookID></response>`))
+		default:
+			rw.Write([]byte(`<response><returncode>FAILED</returncode></response>`))
+		}
+	}))
+}
+
+func TestRegister(t *testing.T) {
+	var destroyed []string
+	server := fakeHooksServer(t, &destroyed)
+	defer server.Close()
+
+	bbb := api.Config{Host: server.URL, Secret: "secret"}
+	require.NoError(t, bbb.Sanitization())
+
+	t.Run("Should remove stale hook of this app then register the new one", func(t *testing.T) {
+		id, err := Register(server.Client(), bbb, callbackUrl)
+		require.NoError(t, err)
+		assert.Equal(t, "3", id)
+		assert.Equal(t, []string{"1"}, destroyed)
+	})
+
+	t.Run("Should error if BBB server is unreachable", func(t *testing.T) {
+		_, err := Register(server.Client(), api.Config{Host: "http://localhost:1/", Secret: "secret"}, callbackUrl)
+		require.Error(t, err)
+	})
+}
+
+func TestUnregister(t *testing.T) {
+	var destroyed []string
+	server := fakeHooksServer(t, &destroyed)
+	defer server.Close()
+
+	bbb := api.Config{Host: server.URL, Secret: "secret"}
+	require.NoError(t, bbb.Sanitization())
+
+	t.Run("Should error if hook id is empty", func(t *testing.T) {
+		require.Error(t, Unregister(server.Client(), bbb, ""))
+	})
+
+	t.Run("Should remove the given hook", func(t *testing.T) {
+		require.NoError(t, Unregister(server.Client(), bbb, "3"))
+		assert.Equal(t, []string{"3"}, destroyed)
+	})
+}
@@ -7,12 +7,23 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// embed time zone database so time zone of meeting series and calendar works on hosts w/o it.
+	_ "time/tzdata"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kurvaid/bbb-interface/internal/api"
+	"github.com/kurvaid/bbb-interface/internal/capacity"
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
 	"github.com/kurvaid/bbb-interface/internal/handlers"
 	"github.com/kurvaid/bbb-interface/internal/logger"
+	"github.com/kurvaid/bbb-interface/internal/roster"
 	"github.com/kurvaid/bbb-interface/internal/routes"
+	"github.com/kurvaid/bbb-interface/internal/store"
+	"github.com/kurvaid/bbb-interface/internal/webhook"
 )
 
 func main() {
@@ -33,11 +44,130 @@ func main() {
 		log.Fatalln("failed to open|create log file:", err)
 	}
 
+	db, err := store.NewBolt(appConfig.DBPath)
+	if err != nil {
+		log.Fatalln("failed to open database:", err)
+	}
+	st := store.New(db)
+
 	cl := &http.Client{}
-	routes.SetupRoutes(app, &appConfig, cl)
+	bus := event.NewBus()
+	// record the lifecycle of every meeting, and attribute events of meetings that were
+	// created before this app restarted to their client.
+	bus.ResolveOwner(st.MeetingOwner)
+	st.Track(bus, func(err error) {
+		logger.Error("failed to record meeting", logger.Fields{"error": err})
+	})
+
+	// forward events to every client that subscribed to them
+	forwardEvents(bus, cl, appConfig.Clients, func(err error) {
+		logger.Error("failed to forward event", logger.Fields{"error": err})
+	})
+
+	// register this app to bbb-webhooks if enabled
+	var hookId string
+	if appConfig.CallbackOnWebhookThisApp != "" {
+		hookId, err = webhook.Register(cl, appConfig.BBB, appConfig.CallbackOnWebhookThisApp)
+		if err != nil {
+			logger.Error("failed to register hook to bbb-webhooks", logger.Fields{"error": err})
+		}
+	}
+
+	// poll roster of meetings that are being watched, and of every meeting until it's
+	// ended so their attendance would be recorded.
+	stop := make(chan struct{})
+	poller := roster.NewPoller(cl, appConfig.BBB, bus, time.Duration(appConfig.PollInterval)*time.Second)
+	poller.Follow()
+	err = st.EachMeeting(func(m store.Meeting) error {
+		if !m.Ended() {
+			poller.FollowMeeting(m.MeetingId)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("failed to follow meetings that haven't ended", logger.Fields{"error": err})
+	}
+	go poller.Run(stop, func(err error) {
+		logger.Error("failed to poll meeting roster", logger.Fields{"error": err})
+	})
 
-	logger.InfL.Printf("listening on %s:%v\n", appConfig.Host, appConfig.PortNum)
-	logger.ErrL.Fatalln(app.Listen(fmt.Sprintf("%s:%v", appConfig.Host, appConfig.PortNum)))
+	// count the meetings and participants of BBB server for the capacity metrics.
+	collector := capacity.NewCollector(cl, []api.Config{appConfig.BBB}, time.Duration(appConfig.CapacityInterval)*time.Second)
+	go collector.Run(stop, func(err error) {
+		logger.Error("failed to collect capacity of BBB server", logger.Fields{"error": err})
+	})
+
+	// schedule the upcoming occurrences of every meeting series.
+	go st.RunSeries(time.Duration(appConfig.SeriesHorizon)*24*time.Hour, time.Hour, stop, func(err error) {
+		logger.Error("failed to schedule meeting series", logger.Fields{"error": err})
+	})
+
+	// remove the join links that have expired.
+	go st.RunJoinLinks(time.Hour, stop, func(err error) {
+		logger.Error("failed to purge expired join links", logger.Fields{"error": err})
+	})
+
+	// remove the responses of idempotent requests that have expired.
+	go st.RunIdempotentResponses(time.Hour, stop, func(err error) {
+		logger.Error("failed to purge idempotent responses", logger.Fields{"error": err})
+	})
+
+	// remove the guests that asked to join more than a day ago.
+	go st.RunGuests(24*time.Hour, time.Hour, stop, func(err error) {
+		logger.Error("failed to purge guests", logger.Fields{"error": err})
+	})
+
+	routes.SetupRoutes(app, &appConfig, cl, bus, poller, st)
+
+	// gracefully shutdown the app on interrupt
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		// close the bus first so every open event stream would end.
+		close(stop)
+		bus.Close()
+		if err := app.Shutdown(); err != nil {
+			logger.Error("failed to shutdown the app", logger.Fields{"error": err})
+		}
+	}()
+
+	logger.Info("listening", logger.Fields{"host": appConfig.Host, "port": appConfig.PortNum})
+	if err := app.Listen(fmt.Sprintf("%s:%v", appConfig.Host, appConfig.PortNum)); err != nil {
+		logger.Error("failed to listen", logger.Fields{"error": err})
+		os.Exit(1)
+	}
+
+	// unregister this app from bbb-webhooks so BBB server would not keep sending events
+	if hookId != "" {
+		if err := webhook.Unregister(cl, appConfig.BBB, hookId); err != nil {
+			logger.Error("failed to unregister hook from bbb-webhooks", logger.Fields{"error": err})
+		}
+	}
+
+	if err := st.Close(); err != nil {
+		logger.Error("failed to close database", logger.Fields{"error": err})
+	}
+}
+
+// forwardEvents forward events of the meetings that belong to every client with `callback_on_event`
+// to it, so clients never receive events of the others' meetings. Return function that stop
+// forwarding.
+func forwardEvents(bus *event.Bus, hCl *http.Client, clients []config.Client, onErr func(error)) func() {
+	var stops []func()
+	for _, c := range clients {
+		if c.CallbackOnEvent == "" {
+			continue
+		}
+		f := event.All(event.ByClient(c.Name), event.ByTypes(c.Events...))
+		stops = append(stops, event.Forward(bus, hCl, c.CallbackOnEvent, f, onErr))
+	}
+
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
 }
 
 // setup prepare everything that necessary before starting this app.
@@ -2,13 +2,18 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/kurvaid/bbb-interface/internal/config"
+	"github.com/kurvaid/bbb-interface/internal/event"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -108,3 +113,51 @@ log: /fake/dir
 		require.Error(t, err)
 	})
 }
+
+func TestForwardEvents(t *testing.T) {
+	// callback server of a client, which collect every event it received.
+	callback := func() (*httptest.Server, chan event.Event) {
+		received := make(chan event.Event, 16)
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			var e event.Event
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&e))
+			received <- e
+		})), received
+	}
+	srvA, receivedA := callback()
+	defer srvA.Close()
+	srvB, receivedB := callback()
+	defer srvB.Close()
+
+	bus := event.NewBus()
+	stop := forwardEvents(bus, &http.Client{}, []config.Client{
+		{Name: "lms-a", CallbackOnEvent: srvA.URL},
+		{Name: "lms-b", CallbackOnEvent: srvB.URL, Events: []string{event.MeetingEnded}},
+		{Name: "lms-c"},
+	}, nil)
+	defer stop()
+
+	bus.Publish(event.Event{Type: event.MeetingCreated, MeetingId: "meet-b", Client: "lms-b"})
+	bus.Publish(event.Event{Type: event.UserJoined, MeetingId: "meet-b", Client: "lms-b"})
+	bus.Publish(event.Event{Type: event.MeetingEnded, MeetingId: "meet-b", Client: "lms-b"})
+	bus.Publish(event.Event{Type: event.MeetingEnded, MeetingId: "meet-a", Client: "lms-a"})
+
+	// events are forwarded in order, so once the last event of client A arrived, every event
+	// of client B that would be wrongly forwarded to A would have arrived too.
+	select {
+	case e := <-receivedA:
+		assert.Equal(t, "meet-a", e.MeetingId)
+	case <-time.After(time.Second):
+		t.Fatal("event of client A was not forwarded")
+	}
+	assert.Empty(t, receivedA)
+
+	select {
+	case e := <-receivedB:
+		assert.Equal(t, event.MeetingEnded, e.Type)
+		assert.Equal(t, "meet-b", e.MeetingId)
+	case <-time.After(time.Second):
+		t.Fatal("event of client B was not forwarded")
+	}
+	assert.Empty(t, receivedB)
+}